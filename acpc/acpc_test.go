@@ -0,0 +1,176 @@
+package acpc
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	poker "github.com/whomever000/poker-common"
+)
+
+// ParseMatchState() ///////////////////////////////////////////////////////////
+
+var testsMatchState = []string{
+	"MATCHSTATE:0:0::9s8h|",
+	"MATCHSTATE:1:30:cr300c/r600:|KdQh/8c8d5c",
+	"MATCHSTATE:0:31:cr20000c///:9s8h|KdQh/8c8d5c/2h/Jc",
+	"MATCHSTATE:2:5:r200ff:||AsKs",
+}
+
+func TestParseMatchState(t *testing.T) {
+
+	tests := testsMatchState
+
+	for i := 0; i < len(tests); i++ {
+		m, err := ParseMatchState(tests[i])
+		if err != nil {
+			t.Fatalf("For %v: %v", tests[i], err)
+		}
+		if m.String() != tests[i] {
+			t.Errorf("Expected %v, got %v", tests[i], m)
+		}
+	}
+
+	for _, invalid := range []string{"MATCHSTATE:0:0:x:", "MATCHSTATE:0:0::9s8", "0:0::"} {
+		if _, err := ParseMatchState(invalid); err == nil {
+			t.Errorf("Expected error for %v", invalid)
+		}
+	}
+}
+
+// Hand() and NewMatchState() //////////////////////////////////////////////////
+
+func TestMatchStateHand(t *testing.T) {
+
+	g := NewGame(2, 20000, 50, 100)
+	str := "MATCHSTATE:0:30:cr300c/r600c/cc/r1200f:9s8h|/8c8d5c/2h/Jc"
+	m, err := ParseMatchState(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := m.Hand(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Button != 2 || h.SmallBlind != 2 || h.BigBlind != 1 {
+		t.Errorf("Unexpected positions %v %v %v", h.Button, h.SmallBlind, h.BigBlind)
+	}
+	if v := h.Validate(); len(v) != 0 {
+		t.Errorf("Unexpected violations %v", v)
+	}
+	if h.Rounds[3].Pot != 1200 || h.Result.Winnings()[1] != 1200 {
+		t.Errorf("Unexpected result %+v", h.Result)
+	}
+
+	m2, err := NewMatchState(h, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m2.String() != str {
+		t.Errorf("Expected %v, got %v", str, m2)
+	}
+
+	// Players sitting out have no position.
+	h.Table.Size = 3
+	h.Sit(3, poker.Player{Name: "p3", Stack: 20000, Status: poker.SittingOut})
+	if m2, err := NewMatchState(h, 1); err != nil || m2.String() != str {
+		t.Errorf("Expected %v, got %v (%v)", str, m2, err)
+	}
+	if _, err := NewMatchState(h, 3); err == nil {
+		t.Errorf("Expected error for a player sitting out")
+	}
+}
+
+// Dealer //////////////////////////////////////////////////////////////////////
+
+// testBot connects to a dealer and plays a fixed action whenever it is its
+// turn.
+func testBot(g *Game, addr net.Addr, action string) error {
+
+	conn, err := net.Dial("tcp", addr.String())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "VERSION:2.0.0\r\n")
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		m, err := ParseMatchState(line)
+		if err != nil {
+			return err
+		}
+		h, s, err := m.replay(g)
+		if err != nil {
+			return err
+		}
+		if !over(h, s) && int(s.ToAct) == m.Position+1 {
+			fmt.Fprintf(conn, "%v:%v\r\n", line, action)
+		}
+	}
+	return nil
+}
+
+func testMatch(t *testing.T, seed int64) []*poker.Hand {
+
+	g := NewGame(3, 1000, 5, 10)
+	d := NewDealer(g, 20, seed)
+	d.Names = []string{"caller", "raiser", "folder"}
+	d.Start = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	d.HandTime = time.Minute
+	if err := d.Listen("127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 3)
+	for i, action := range []string{"c", "r1", "f"} {
+		go func(addr net.Addr, action string) {
+			errs <- testBot(g, addr, action)
+		}(d.Addrs()[i], action)
+	}
+
+	hands, err := d.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return hands
+}
+
+func TestDealer(t *testing.T) {
+
+	hands := testMatch(t, 7)
+	if len(hands) != 20 {
+		t.Fatalf("Expected 20 hands, got %v", len(hands))
+	}
+	for i, h := range hands {
+		if v := h.Validate(); len(v) != 0 {
+			t.Errorf("For hand %v unexpected violations %v\n%v", i, v, h)
+		}
+		if h.Result == nil || len(h.Result.Pots) == 0 {
+			t.Errorf("For hand %v expected a result\n%v", i, h)
+		}
+	}
+
+	// Players rotate through the positions.
+	if hands[0].Players[1].Name != "caller" || hands[1].Players[1].Name != "raiser" {
+		t.Errorf("Unexpected players %v %v", hands[0].Players, hands[1].Players)
+	}
+
+	// The same seed deals the same hands, dated alike.
+	again := testMatch(t, 7)
+	for i := 0; i < len(hands); i++ {
+		if hands[i].String() != again[i].String() {
+			t.Errorf("For hand %v expected\n%v\ngot\n%v", i, hands[i], again[i])
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package acpc
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	poker "github.com/whomever000/poker-common"
+	"github.com/whomever000/poker-common/card"
+)
+
+// Dealer deals a match between bots which connect over TCP, with one port for
+// each player. Players change positions after every hand, and stacks are reset
+// at the start of every hand.
+type Dealer struct {
+
+	// Game is the game played.
+	Game *Game
+
+	// Hands is the number of hands in the match.
+	Hands int
+
+	// Seed seeds the deck, so that a match can be repeated.
+	Seed int64
+
+	// Names are the names of the players, in the order of their ports.
+	Names []string
+
+	// Start is the date of the first hand. Later hands are dated HandTime
+	// apart, so that a repeated match records identical hands.
+	Start    time.Time
+	HandTime time.Duration
+
+	listeners []net.Listener
+}
+
+// player is a connected bot.
+type player struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewDealer creates a dealer for a match.
+func NewDealer(g *Game, hands int, seed int64) *Dealer {
+	return &Dealer{Game: g, Hands: hands, Seed: seed}
+}
+
+// Listen opens a port for each player on a host, e.g. 'localhost'. The ports
+// are chosen by the system, and returned by Addrs.
+func (d *Dealer) Listen(host string) error {
+	for i := 0; i < d.Game.Players(); i++ {
+		l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+		if err != nil {
+			d.Close()
+			return err
+		}
+		d.listeners = append(d.listeners, l)
+	}
+	return nil
+}
+
+// Addrs returns the addresses the players connect to.
+func (d *Dealer) Addrs() []net.Addr {
+	var addrs []net.Addr
+	for _, l := range d.listeners {
+		addrs = append(addrs, l.Addr())
+	}
+	return addrs
+}
+
+// Close closes the ports.
+func (d *Dealer) Close() {
+	for _, l := range d.listeners {
+		l.Close()
+	}
+	d.listeners = nil
+}
+
+// name returns the name of a player.
+func (d *Dealer) name(i int) string {
+	if i < len(d.Names) {
+		return d.Names[i]
+	}
+	return fmt.Sprintf("Player %v", i+1)
+}
+
+// Run waits for every player to connect, and plays the match. It returns the
+// hands played, as seen by an observer.
+func (d *Dealer) Run() ([]*poker.Hand, error) {
+
+	defer d.Close()
+	if len(d.listeners) != d.Game.Players() {
+		return nil, fmt.Errorf("acpc: dealer is not listening")
+	}
+
+	players := make([]*player, len(d.listeners))
+	defer func() {
+		for _, p := range players {
+			if p != nil {
+				p.conn.Close()
+			}
+		}
+	}()
+	for i, l := range d.listeners {
+		conn, err := l.Accept()
+		if err != nil {
+			return nil, err
+		}
+		p := &player{conn, bufio.NewReader(conn)}
+		players[i] = p
+
+		line, err := p.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "VERSION:") {
+			return nil, fmt.Errorf("acpc: player %v: expected version, got %q", i+1, line)
+		}
+	}
+
+	deck := card.NewDeck(d.Seed)
+	var hands []*poker.Hand
+	for hand := 0; hand < d.Hands; hand++ {
+		h, err := d.play(hand, deck, players)
+		if err != nil {
+			return hands, err
+		}
+		hands = append(hands, h)
+	}
+	return hands, nil
+}
+
+// play plays a single hand.
+func (d *Dealer) play(hand int, deck *card.Deck, players []*player) (*poker.Hand, error) {
+
+	g := d.Game
+	n := g.Players()
+
+	// Players rotate through the positions.
+	at := func(position int) *player {
+		return players[(position+hand)%n]
+	}
+
+	deck.Shuffle()
+	m := &MatchState{
+		Position:   -1,
+		HandNumber: hand,
+		Betting:    [][]Action{nil},
+		Board:      [][]card.Card{nil},
+	}
+	for i := 0; i < n; i++ {
+		m.HoleCards = append(m.HoleCards, deck.Deal(2))
+	}
+	boards := [][]card.Card{nil, deck.Deal(3), deck.Deal(1), deck.Deal(1)}
+
+	for {
+		h, s, err := m.replay(g)
+		if err != nil {
+			return nil, err
+		}
+
+		if over(h, s) {
+			// Hole cards are shown at showdown.
+			var shown []int
+			if len(s.InHand) > 1 {
+				for _, pos := range s.InHand {
+					shown = append(shown, int(pos)-1)
+				}
+			}
+			for position := 0; position < n; position++ {
+				if err := at(position).send(m.View(position, shown...)); err != nil {
+					return nil, err
+				}
+			}
+
+			for i := 0; i < n; i++ {
+				h.Players[poker.PlayerPosition(i+1)].Name = d.name((i + hand) % n)
+			}
+			h.Date = poker.Date(d.Start.Add(time.Duration(hand) * d.HandTime))
+			return h, nil
+		}
+
+		if s.ToAct == 0 {
+			r := len(m.Betting)
+			m.Betting = append(m.Betting, nil)
+			m.Board = append(m.Board, boards[r])
+			continue
+		}
+
+		for position := 0; position < n; position++ {
+			if err := at(position).send(m.View(position)); err != nil {
+				return nil, err
+			}
+		}
+
+		position := int(s.ToAct) - 1
+		a, err := at(position).receive(m.View(position))
+		if err != nil {
+			return nil, err
+		}
+		r := len(m.Betting) - 1
+		m.Betting[r] = append(m.Betting[r], legalize(s, s.ToAct, a))
+	}
+}
+
+// send sends a match state to a player.
+func (p *player) send(m *MatchState) error {
+	_, err := fmt.Fprintf(p.conn, "%v\r\n", m)
+	return err
+}
+
+// receive reads a player's response to a match state. Responses to other
+// states and comments are ignored, and invalid actions are read as calls.
+func (p *player) receive(m *MatchState) (Action, error) {
+	prefix := m.String() + ":"
+	for {
+		line, err := p.reader.ReadString('\n')
+		if err != nil {
+			return Action{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		a, err := ParseAction(line[len(prefix):])
+		if err != nil {
+			return Action{Type: Call}, nil
+		}
+		return a, nil
+	}
+}
+
+// legalize turns an action into a legal one. Folding without facing a bet is
+// a check, raising when nobody can call is a call, and raise sizes are limited
+// to the minimum raise and the player's stack.
+func legalize(s *poker.TableState, pos poker.PlayerPosition, a Action) Action {
+
+	if a.Type == Fold && s.ToCall(pos) > 0 {
+		return a
+	}
+	if a.Type != Raise {
+		return Action{Type: Call}
+	}
+
+	others := 0
+	for _, p := range s.InHand {
+		if p != pos && s.Stacks[p] > 0 {
+			others++
+		}
+	}
+	if others == 0 || s.Stacks[pos] <= s.ToCall(pos) {
+		return Action{Type: Call}
+	}
+
+	// Amounts are totals for the hand.
+	before := int(s.Invested[pos] - s.Contributions[pos])
+	min := before + int(s.CurrentBet+s.MinRaise)
+	max := int(s.Invested[pos] + s.Stacks[pos])
+	switch {
+	case a.Amount > max || min > max:
+		a.Amount = max
+	case a.Amount < min:
+		a.Amount = min
+	}
+	return a
+}
@@ -0,0 +1,191 @@
+// Package acpc implements the match-state protocol of the Annual Computer
+// Poker Competition (ACPC), and a dealer which speaks it over TCP.
+//
+// Only no limit Texas Hold'em is supported. ACPC positions are numbered from
+// 0, starting left of the button, so that the last position has the button.
+// Heads-up, position 0 is the big blind and position 1 the button, which posts
+// the small blind. One chip is one poker.Amount unit.
+package acpc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	poker "github.com/whomever000/poker-common"
+)
+
+// Game is an ACPC game definition.
+type Game struct {
+
+	// Stacks are the starting stacks of the positions.
+	Stacks []int
+
+	// Blinds are the blinds posted by the positions.
+	Blinds []int
+}
+
+// NewGame creates a no limit Texas Hold'em game.
+func NewGame(players, stack, smallBlind, bigBlind int) *Game {
+	g := &Game{
+		Stacks: make([]int, players),
+		Blinds: make([]int, players),
+	}
+	for i := 0; i < players; i++ {
+		g.Stacks[i] = stack
+	}
+	if players == 2 {
+		g.Blinds[0], g.Blinds[1] = bigBlind, smallBlind
+	} else if players > 2 {
+		g.Blinds[0], g.Blinds[1] = smallBlind, bigBlind
+	}
+	return g
+}
+
+// Players returns the number of players.
+func (g *Game) Players() int {
+	return len(g.Stacks)
+}
+
+// firstPlayer returns the position which acts first in a betting round.
+func (g *Game) firstPlayer(round int) int {
+	switch {
+	case round > 0:
+		return 0
+	case g.Players() == 2:
+		// Heads-up, the button acts first before the flop.
+		return 1
+	default:
+		return 2 % g.Players()
+	}
+}
+
+// ParseGame reads a game definition, i.e. a 'GAMEDEF' block.
+func ParseGame(r io.Reader) (*Game, error) {
+
+	g := &Game{}
+	players := 0
+	limit := ""
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		lower := strings.ToLower(line)
+		switch lower {
+		case "", "gamedef", "end gamedef":
+			continue
+		case "nolimit", "limit", "pot limit":
+			limit = lower
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("acpc: invalid game definition line %q", line)
+		}
+		key := strings.ToLower(strings.TrimSpace(parts[0]))
+		var values []int
+		for _, f := range strings.Fields(parts[1]) {
+			v, err := strconv.Atoi(f)
+			if err != nil {
+				return nil, fmt.Errorf("acpc: invalid value %v for %v", f, key)
+			}
+			values = append(values, v)
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("acpc: missing value for %v", key)
+		}
+
+		switch key {
+		case "numplayers":
+			players = values[0]
+		case "stack":
+			g.Stacks = values
+		case "blind":
+			g.Blinds = values
+		case "numrounds":
+			if values[0] != 4 {
+				return nil, fmt.Errorf("acpc: unsupported number of rounds %v", values[0])
+			}
+		case "numholecards":
+			if values[0] != 2 {
+				return nil, fmt.Errorf("acpc: unsupported number of hole cards %v", values[0])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if limit != "nolimit" {
+		return nil, fmt.Errorf("acpc: unsupported betting type %q", limit)
+	}
+	if players < 2 || len(g.Stacks) != players || len(g.Blinds) != players {
+		return nil, fmt.Errorf("acpc: expected stacks and blinds for %v players", players)
+	}
+	return g, nil
+}
+
+// String returns the game definition.
+func (g *Game) String() string {
+
+	var b strings.Builder
+	ints := func(values []int) string {
+		strs := make([]string, len(values))
+		for i := 0; i < len(values); i++ {
+			strs[i] = strconv.Itoa(values[i])
+		}
+		return strings.Join(strs, " ")
+	}
+
+	b.WriteString("GAMEDEF\nnolimit\n")
+	fmt.Fprintf(&b, "numPlayers = %v\n", g.Players())
+	b.WriteString("numRounds = 4\n")
+	fmt.Fprintf(&b, "stack = %v\n", ints(g.Stacks))
+	fmt.Fprintf(&b, "blind = %v\n", ints(g.Blinds))
+	first := make([]int, 4)
+	for r := 0; r < 4; r++ {
+		first[r] = g.firstPlayer(r) + 1
+	}
+	fmt.Fprintf(&b, "firstPlayer = %v\n", ints(first))
+	b.WriteString("numSuits = 4\nnumRanks = 13\nnumHoleCards = 2\n")
+	b.WriteString("numBoardCards = 0 3 1 1\nEND GAMEDEF\n")
+	return b.String()
+}
+
+// table returns the table of a hand played in the game. Position i sits in
+// seat i+1.
+func (g *Game) table() poker.Table {
+	t := poker.Table{
+		Size: g.Players(),
+		Game: poker.TexasHoldEmNoLimit,
+	}
+	sb, bb := g.blinds()
+	if sb >= 0 {
+		t.Stakes.SmallBlind = poker.Amount(g.Blinds[sb])
+	}
+	if bb >= 0 {
+		t.Stakes.BigBlind = poker.Amount(g.Blinds[bb])
+	}
+	return t
+}
+
+// blinds returns the small blind and big blind positions, or -1.
+func (g *Game) blinds() (int, int) {
+	sb, bb := -1, -1
+	for i := 0; i < len(g.Blinds); i++ {
+		switch {
+		case g.Blinds[i] == 0:
+		case bb < 0 || g.Blinds[i] > g.Blinds[bb]:
+			sb, bb = bb, i
+		case sb < 0:
+			sb = i
+		}
+	}
+	return sb, bb
+}
@@ -0,0 +1,381 @@
+package acpc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	poker "github.com/whomever000/poker-common"
+	"github.com/whomever000/poker-common/card"
+)
+
+// Action types
+const (
+	Fold  = 'f'
+	Call  = 'c'
+	Raise = 'r'
+)
+
+// Action is an ACPC action.
+type Action struct {
+
+	// Type is Fold, Call (which includes checking) or Raise.
+	Type byte
+
+	// Amount is the total a player has put in during the hand after a raise.
+	Amount int
+}
+
+// String returns the action as written in a match state, e.g. 'r300'.
+func (a Action) String() string {
+	if a.Type == Raise {
+		return fmt.Sprintf("%c%v", a.Type, a.Amount)
+	}
+	return string(a.Type)
+}
+
+// ParseAction parses an action, e.g. 'f', 'c' or 'r300'.
+func ParseAction(str string) (Action, error) {
+	if str == "" {
+		return Action{}, fmt.Errorf("acpc: empty action")
+	}
+	switch str[0] {
+	case Fold, Call:
+		if len(str) == 1 {
+			return Action{Type: str[0]}, nil
+		}
+	case Raise:
+		amount, err := strconv.Atoi(str[1:])
+		if err == nil && amount > 0 {
+			return Action{Raise, amount}, nil
+		}
+	}
+	return Action{}, fmt.Errorf("acpc: invalid action %q", str)
+}
+
+// MatchState is the state of a hand as seen by one position, e.g.
+// 'MATCHSTATE:0:30:cc/r250:9s8h|/8c8d5c'.
+type MatchState struct {
+
+	// Position is the position which sees the state.
+	Position int
+
+	// HandNumber is the number of the hand in the match.
+	HandNumber int
+
+	// Betting are the actions of each betting round so far.
+	Betting [][]Action
+
+	// HoleCards are the hole cards of each position, or nil if unknown.
+	HoleCards [][]card.Card
+
+	// Board are the board cards dealt at the start of each betting round.
+	Board [][]card.Card
+}
+
+// ParseMatchState parses a match state.
+func ParseMatchState(str string) (*MatchState, error) {
+
+	parts := strings.Split(strings.TrimSpace(str), ":")
+	if len(parts) != 5 || parts[0] != "MATCHSTATE" {
+		return nil, fmt.Errorf("acpc: invalid match state %q", str)
+	}
+
+	m := &MatchState{}
+	var err error
+	if m.Position, err = strconv.Atoi(parts[1]); err != nil {
+		return nil, fmt.Errorf("acpc: invalid position %v", parts[1])
+	}
+	if m.HandNumber, err = strconv.Atoi(parts[2]); err != nil {
+		return nil, fmt.Errorf("acpc: invalid hand number %v", parts[2])
+	}
+
+	for _, round := range strings.Split(parts[3], "/") {
+		var actions []Action
+		for i := 0; i < len(round); {
+			j := i + 1
+			for j < len(round) && round[j] >= '0' && round[j] <= '9' {
+				j++
+			}
+			a, err := ParseAction(round[i:j])
+			if err != nil {
+				return nil, err
+			}
+			actions = append(actions, a)
+			i = j
+		}
+		m.Betting = append(m.Betting, actions)
+	}
+
+	rounds := strings.Split(parts[4], "/")
+	for _, hole := range strings.Split(rounds[0], "|") {
+		cards, err := parseCards(hole)
+		if err != nil {
+			return nil, err
+		}
+		m.HoleCards = append(m.HoleCards, cards)
+	}
+	m.Board = append(m.Board, nil)
+	for _, board := range rounds[1:] {
+		cards, err := parseCards(board)
+		if err != nil {
+			return nil, err
+		}
+		m.Board = append(m.Board, cards)
+	}
+
+	return m, nil
+}
+
+// String returns the match state as sent by the dealer.
+func (m *MatchState) String() string {
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "MATCHSTATE:%v:%v:", m.Position, m.HandNumber)
+	for r := 0; r < len(m.Betting); r++ {
+		if r > 0 {
+			b.WriteString("/")
+		}
+		for _, a := range m.Betting[r] {
+			b.WriteString(a.String())
+		}
+	}
+	b.WriteString(":")
+	for i := 0; i < len(m.HoleCards); i++ {
+		if i > 0 {
+			b.WriteString("|")
+		}
+		b.WriteString(formatCards(m.HoleCards[i]))
+	}
+	for r := 1; r < len(m.Board); r++ {
+		b.WriteString("/")
+		b.WriteString(formatCards(m.Board[r]))
+	}
+	return b.String()
+}
+
+// View returns the state as seen by a position, i.e. without the hole cards
+// of the other positions unless they are shown.
+func (m *MatchState) View(position int, shown ...int) *MatchState {
+	v := *m
+	v.Position = position
+	v.HoleCards = make([][]card.Card, len(m.HoleCards))
+	if position >= 0 && position < len(m.HoleCards) {
+		v.HoleCards[position] = m.HoleCards[position]
+	}
+	for _, i := range shown {
+		if i >= 0 && i < len(m.HoleCards) {
+			v.HoleCards[i] = m.HoleCards[i]
+		}
+	}
+	return &v
+}
+
+// Hand converts the match state to a hand, seen by the match state's
+// position. A finished hand is settled if the hole cards of the players at
+// showdown are known.
+func (m *MatchState) Hand(g *Game) (*poker.Hand, error) {
+	h, _, err := m.replay(g)
+	return h, err
+}
+
+// replay converts the match state to a hand, and returns the table state
+// after the last action.
+func (m *MatchState) replay(g *Game) (*poker.Hand, *poker.TableState, error) {
+
+	n := g.Players()
+	h := &poker.Hand{
+		Client: "ACPC",
+		HandID: m.HandNumber,
+		Table:  g.table(),
+		Button: poker.PlayerPosition(n),
+	}
+	for i := 0; i < n; i++ {
+		h.Sit(poker.PlayerPosition(i+1), poker.Player{
+			Name:  fmt.Sprintf("p%v", i+1),
+			Stack: poker.Amount(g.Stacks[i]),
+		})
+	}
+	sb, bb := g.blinds()
+	if sb >= 0 {
+		h.SmallBlind = poker.PlayerPosition(sb + 1)
+	}
+	if bb >= 0 {
+		h.BigBlind = poker.PlayerPosition(bb + 1)
+	}
+
+	holeCards := make(map[poker.PlayerPosition][]card.Card)
+	for i := 0; i < len(m.HoleCards); i++ {
+		if len(m.HoleCards[i]) > 0 {
+			holeCards[poker.PlayerPosition(i+1)] = m.HoleCards[i]
+		}
+	}
+	if m.Position >= 0 && m.Position < n {
+		pos := poker.PlayerPosition(m.Position + 1)
+		h.ThisPlayer = &poker.PlayerCards{Position: pos, Cards: holeCards[pos]}
+	}
+
+	s := poker.NewTableState(h)
+	var board []card.Card
+	for r := 0; r < len(m.Betting); r++ {
+		if r > 0 {
+			s.NextRound()
+		}
+		if r < len(m.Board) {
+			board = append(append([]card.Card(nil), board...), m.Board[r]...)
+		}
+		round := poker.Round{}
+		if r > 0 {
+			round.Cards = board
+		}
+
+		for _, a := range m.Betting[r] {
+			pos := s.ToAct
+			if pos == 0 {
+				return nil, nil, fmt.Errorf("acpc: action %v after the betting round", a)
+			}
+			pa := poker.PlayerAction{Position: pos, Action: toAction(s, pos, a)}
+			if err := s.Apply(pa); err != nil {
+				return nil, nil, fmt.Errorf("acpc: %v", err)
+			}
+			round.Actions = append(round.Actions, pa)
+		}
+
+		_, uncalled := s.Uncalled()
+		round.Pot = s.Pot - uncalled
+		h.Rounds = append(h.Rounds, round)
+	}
+
+	// Without the hole cards of every player at showdown, the winners are
+	// unknown and the hand is left unsettled.
+	if over(h, s) {
+		h.Settle(holeCards)
+	}
+
+	return h, s, nil
+}
+
+// over returns whether a hand is over, i.e. one player is left or betting on
+// the river is complete.
+func over(h *poker.Hand, s *poker.TableState) bool {
+	return len(s.InHand) == 1 || len(h.Rounds) == 4 && s.ToAct == 0
+}
+
+// toAction converts an ACPC action of the player to act.
+func toAction(s *poker.TableState, pos poker.PlayerPosition, a Action) poker.Action {
+	switch a.Type {
+	case Fold:
+		return poker.NewFoldAction()
+	case Raise:
+		// Raises are to a total for the hand, rather than the betting round.
+		to := poker.Amount(a.Amount) - (s.Invested[pos] - s.Contributions[pos])
+		if s.CurrentBet == 0 {
+			return poker.NewBetAction(to)
+		}
+		return poker.NewRaiseAction(to)
+	default:
+		if toCall := s.ToCall(pos); toCall > 0 {
+			return poker.NewCallAction(toCall)
+		}
+		return poker.NewCheckAction()
+	}
+}
+
+// NewMatchState converts a hand to a match state seen by the player in a
+// seat. Hole cards are known for the hand's player and for players who show
+// them.
+func NewMatchState(h *poker.Hand, seat poker.PlayerPosition) (*MatchState, error) {
+
+	positions := order(h)
+	index := make(map[poker.PlayerPosition]int)
+	for i, pos := range positions {
+		index[pos] = i
+	}
+	position, ok := index[seat]
+	if !ok {
+		return nil, fmt.Errorf("acpc: seat %v is not in the hand", seat)
+	}
+
+	m := &MatchState{
+		Position:   position,
+		HandNumber: h.HandID,
+		HoleCards:  make([][]card.Card, len(positions)),
+	}
+	if h.ThisPlayer != nil && h.ThisPlayer.Position == seat {
+		m.HoleCards[position] = h.ThisPlayer.Cards
+	}
+	if h.Result != nil {
+		for _, sd := range h.Result.ShowDowns {
+			if i, ok := index[sd.Position]; ok {
+				m.HoleCards[i] = sd.Cards
+			}
+		}
+	}
+
+	s := poker.NewTableState(h)
+	var prev []card.Card
+	for r := 0; r < len(h.Rounds); r++ {
+		var dealt []card.Card
+		if r > 0 {
+			s.NextRound()
+			cards := h.Rounds[r].Cards
+			if len(cards) > len(prev) {
+				dealt = cards[len(prev):]
+				prev = cards
+			}
+		}
+		m.Board = append(m.Board, dealt)
+
+		var actions []Action
+		for _, pa := range h.Rounds[r].Actions {
+			s.Apply(pa)
+			switch pa.Action.Type() {
+			case poker.Fold:
+				actions = append(actions, Action{Type: Fold})
+			case poker.Check, poker.Call:
+				actions = append(actions, Action{Type: Call})
+			default:
+				actions = append(actions, Action{Raise, int(s.Invested[pa.Position])})
+			}
+		}
+		m.Betting = append(m.Betting, actions)
+	}
+
+	return m, nil
+}
+
+// order returns the seats of the players dealt in in ACPC position order, i.e.
+// starting left of the button. Players sitting out have no position.
+func order(h *poker.Hand) []poker.PlayerPosition {
+	var positions []poker.PlayerPosition
+	size := h.TableSize()
+	pos := h.Button
+	for i := 0; i < size; i++ {
+		pos = poker.NextPlayerPosition(pos, size)
+		if p := pos.Player(h); p != nil && p.IsDealtIn() {
+			positions = append(positions, pos)
+		}
+	}
+	return positions
+}
+
+// parseCards parses cards written without spaces, e.g. '9s8h'.
+func parseCards(str string) ([]card.Card, error) {
+	if len(str)%2 != 0 {
+		return nil, fmt.Errorf("acpc: invalid cards %v", str)
+	}
+	var cards []card.Card
+	for i := 0; i < len(str); i += 2 {
+		c, err := card.ParseCard(str[i : i+2])
+		if err != nil {
+			return nil, fmt.Errorf("acpc: %v", err)
+		}
+		cards = append(cards, c)
+	}
+	return cards, nil
+}
+
+// formatCards formats cards without spaces.
+func formatCards(cards []card.Card) string {
+	return strings.Replace(card.FormatCards(cards), " ", "", -1)
+}
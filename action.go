@@ -2,6 +2,36 @@ package poker
 
 import "fmt"
 
+// ActionType is the type of a player action.
+type ActionType int
+
+// List of action types
+const (
+	Fold ActionType = iota
+	Check
+	Call
+	Raise
+	Bet
+)
+
+// String returns the name of the action type.
+func (t ActionType) String() string {
+	switch t {
+	case Fold:
+		return "fold"
+	case Check:
+		return "check"
+	case Call:
+		return "call"
+	case Raise:
+		return "raise"
+	case Bet:
+		return "bet"
+	default:
+		return "unknown action"
+	}
+}
+
 // Action is the interface to a player action.
 type Action interface {
 
@@ -9,7 +39,30 @@ type Action interface {
 	String() string
 
 	// Amount returns the associated amount (not relevant to all action types).
+	// A call amount is the amount added, while bet and raise amounts are the
+	// player's total for the betting round. A negative amount means all-in.
 	Amount() Amount
+
+	// Type returns the action type.
+	Type() ActionType
+}
+
+// NewAction creates a new action of the given type.
+func NewAction(t ActionType, amount Amount) Action {
+	switch t {
+	case Fold:
+		return NewFoldAction()
+	case Check:
+		return NewCheckAction()
+	case Call:
+		return NewCallAction(amount)
+	case Raise:
+		return NewRaiseAction(amount)
+	case Bet:
+		return NewBetAction(amount)
+	default:
+		return nil
+	}
 }
 
 // Fold action /////////////////////////////////////////////////////////////////
@@ -24,6 +77,9 @@ type foldAction struct{}
 func (a *foldAction) Amount() Amount {
 	return 0
 }
+func (a *foldAction) Type() ActionType {
+	return Fold
+}
 func (a *foldAction) String() string {
 	return "folds "
 }
@@ -40,6 +96,9 @@ type checkAction struct{}
 func (a *checkAction) Amount() Amount {
 	return 0
 }
+func (a *checkAction) Type() ActionType {
+	return Check
+}
 func (a *checkAction) String() string {
 	return "checks "
 }
@@ -58,6 +117,9 @@ type callAction struct {
 func (a *callAction) Amount() Amount {
 	return a.amount
 }
+func (a *callAction) Type() ActionType {
+	return Call
+}
 func (a *callAction) String() string {
 	return fmt.Sprintf("calls %v", a.amount)
 }
@@ -76,6 +138,9 @@ type raiseAction struct {
 func (a *raiseAction) Amount() Amount {
 	return a.amount
 }
+func (a *raiseAction) Type() ActionType {
+	return Raise
+}
 func (a *raiseAction) String() string {
 	// TODO: Do not use $0.00
 	return fmt.Sprintf("raises to %v", a.amount)
@@ -95,6 +160,9 @@ type betAction struct {
 func (a *betAction) Amount() Amount {
 	return a.amount
 }
+func (a *betAction) Type() ActionType {
+	return Bet
+}
 func (a *betAction) String() string {
 	return fmt.Sprintf("bets %v", a.amount)
 }
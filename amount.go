@@ -3,14 +3,21 @@ package poker
 import (
 	"encoding/json"
 	"fmt"
-	"strconv"
-	"strings"
+	"math"
 )
 
-// Amount represents an amount of US cents.
+// Amount represents an amount in hundredths of the currency of the stakes it
+// belongs to, e.g. US cents, euro cents or hundredths of a tournament chip.
+//
+// Hands and stakes hold all amounts as Amount, so they cannot hold stakes
+// finer than hundredths, such as $0.005/$0.01: such amounts would have to be
+// rounded, which would change the bets. Money holds amounts of any precision,
+// e.g. for reporting, but hands are converted to it at a precision of 2.
 type Amount int
 
-// String returns a string representing the amount in the form '$2.32'.
+// String returns a string representing the amount in US dollars in the form
+// '$2.32', or '$2' if it is whole. Stakes.Format and Hand.Format format
+// amounts in the currency of the stakes.
 func (a Amount) String() string {
 	if a == -1 {
 		return fmt.Sprintf("All In")
@@ -23,27 +30,26 @@ func (a Amount) String() string {
 
 // NewAmount creates an amount from a float.
 func NewAmount(amount float64) Amount {
-	return Amount((amount * 100) + 0.5)
+	return Amount(math.Round(amount * 100))
 }
 
-// ParseAmount parses an amount from a string.
-// The string may contain '$' and 'USD'.
+// ParseAmount parses an amount from a string leniently, e.g. '$1,234.56',
+// '€1.234,56' or '1.5k'. The string may contain the symbol or code of any
+// currency, and amounts finer than cents are rounded. It returns an
+// *AmountError if the amount is invalid.
 func ParseAmount(amount string) (Amount, error) {
-	amount = strings.Replace(amount, "$", "", -1)
-	amount = strings.Replace(amount, "USD", "", -1)
-	amount = strings.Replace(amount, " ", "", -1)
-	amount = strings.Replace(amount, ",", "", -1)
-
-	val, err := strconv.ParseFloat(amount, 64)
+	_, _, c, err := splitCurrency(amount)
 	if err != nil {
-		fmt.Println("failed to parse amount:", amount)
-		// TODO: remove this
-		return NewAmount(0), nil
-
 		return 0, err
 	}
-
-	return NewAmount(val), nil
+	if c.Code == "" {
+		c = USD
+	}
+	m, err := ParseMoneyMode(amount, c.WithPrecision(2), Lenient)
+	if err != nil {
+		return 0, err
+	}
+	return m.Amount(), nil
 }
 
 // MarshalJSON marshals the string representation of the amount.
@@ -52,13 +58,18 @@ func (a Amount) MarshalJSON() ([]byte, error) {
 }
 
 // UnmarshalJSON parses an amount from JSON.
-func (a Amount) UnmarshalJSON(data []byte) error {
+func (a *Amount) UnmarshalJSON(data []byte) error {
 
 	var str string
 	if err := json.Unmarshal(data, &str); err != nil {
 		return err
 	}
 
-	a, err := ParseAmount(str)
+	if str == Amount(-1).String() {
+		*a = -1
+		return nil
+	}
+	var err error
+	*a, err = ParseAmount(str)
 	return err
 }
@@ -39,7 +39,7 @@ type testPairAmountString struct {
 
 var testsAmountString = []testPairAmountString{
 	{0.42, "$0.42"},
-	{42, "$42.00"},
+	{42, "$42"},
 	{-0.42, "$-0.42"},
 }
 
@@ -70,12 +70,23 @@ var testsParseAmount = []testPairParseAmount{
 	{"$42.00", 4200},
 	{"$-0.42", -42},
 	{"$123 USD", 12300},
+	{"$1,234.56", 123456},
+	{"€1.234,56", 123456},
+	{"£0.05", 5},
+	{"0,5", 50},
+	{"1.5k", 150000},
+	{"2M chips", 200000000},
+	{"$0.0049", 0},
+	{"$0.005", 1},
 }
 
 var testsParseAmountError = []string{
 	"$1.a3",
 	"asdasd",
 	"12346a",
+	"",
+	"$2 EUR",
+	"1.2.3,4,5",
 }
 
 func TestParseAmount(t *testing.T) {
@@ -104,5 +115,8 @@ func TestParseAmount(t *testing.T) {
 		if int(amount) != 0 {
 			t.Errorf("For %v expected $0.00, got %v", testsError[i], amount)
 		}
+		if e, ok := err.(*AmountError); !ok || e.Input != testsError[i] {
+			t.Errorf("For %v expected an amount error, got %#v", testsError[i], err)
+		}
 	}
 }
@@ -0,0 +1,74 @@
+package poker
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Unit is a unit amounts are expressed in.
+type Unit int
+
+// Units
+const (
+	// Native is the currency of the stakes, or chips in tournaments.
+	Native Unit = iota
+
+	// BigBlinds is the big blind of the stakes, for comparing amounts across
+	// stakes.
+	BigBlinds
+)
+
+// BigBlinds returns an amount in big blinds, or 0 if there is no big blind.
+func (s Stakes) BigBlinds(a Amount) float64 {
+	if s.BigBlind == 0 {
+		return 0
+	}
+	return float64(a) / float64(s.BigBlind)
+}
+
+// FromBigBlinds returns the amount of a number of big blinds, rounded to the
+// nearest cent.
+func (s Stakes) FromBigBlinds(bb float64) Amount {
+	return Amount(math.Round(bb * float64(s.BigBlind)))
+}
+
+// Format formats an amount in a unit, e.g. '$2.50' or '12.5bb'.
+func (s Stakes) Format(a Amount, u Unit) string {
+	if u == BigBlinds {
+		return FormatBigBlinds(s.BigBlinds(a))
+	}
+	c := s.currency()
+	if c.Suffix {
+		return chips(a)
+	}
+	return a.Money(c).Short()
+}
+
+// FormatBigBlinds formats a number of big blinds with up to two decimal
+// places, e.g. '12.5bb'.
+func FormatBigBlinds(bb float64) string {
+	return strconv.FormatFloat(math.Round(bb*100)/100, 'f', -1, 64) + "bb"
+}
+
+// BigBlinds returns an amount in the big blinds of the hand's stakes.
+func (h *Hand) BigBlinds(a Amount) float64 {
+	return h.Table.Stakes.BigBlinds(a)
+}
+
+// Format formats an amount in a unit. Native amounts of tournament hands are
+// in chips.
+func (h *Hand) Format(a Amount, u Unit) string {
+	if h.Tournament != nil && u == Native {
+		return chips(a)
+	}
+	return h.Table.Stakes.Format(a, u)
+}
+
+// StringIn returns the hand history in PokerStars format with amounts in a
+// unit. Hands in big blinds cannot be parsed.
+func (h *Hand) StringIn(u Unit) string {
+	var b strings.Builder
+	h.write(&b, u)
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package poker
+
+import (
+	"strings"
+	"testing"
+)
+
+// Stakes.Format() /////////////////////////////////////////////////////////////
+
+func TestStakesFormat(t *testing.T) {
+
+	type testPair struct {
+		stakes Stakes
+		amount Amount
+		native string
+		bb     string
+	}
+	tests := []testPair{
+		{Stakes{SmallBlind: 5, BigBlind: 10}, 125, "$1.25", "12.5bb"},
+		{Stakes{SmallBlind: 5, BigBlind: 10}, 100, "$1", "10bb"},
+		{Stakes{SmallBlind: 1, BigBlind: 3, Currency: EUR}, 1, "€0.01", "0.33bb"},
+		{Stakes{SmallBlind: 1000, BigBlind: 2000, Currency: PlayMoney}, 5000, "50", "2.5bb"},
+		{Stakes{}, 100, "$1", "0bb"},
+	}
+
+	for _, test := range tests {
+		if s := test.stakes.Format(test.amount, Native); s != test.native {
+			t.Errorf("For %v in %v expected %v, got %v", int(test.amount), test.stakes,
+				test.native, s)
+		}
+		if s := test.stakes.Format(test.amount, BigBlinds); s != test.bb {
+			t.Errorf("For %v in %v expected %v, got %v", int(test.amount), test.stakes,
+				test.bb, s)
+		}
+	}
+
+	stakes := Stakes{SmallBlind: 5, BigBlind: 10}
+	if a := stakes.FromBigBlinds(12.5); a != 125 {
+		t.Errorf("Expected 125, got %v", int(a))
+	}
+	if bb := stakes.BigBlinds(stakes.FromBigBlinds(3)); bb != 3 {
+		t.Errorf("Expected 3, got %v", bb)
+	}
+}
+
+// Hand.StringIn() /////////////////////////////////////////////////////////////
+
+func TestHandStringIn(t *testing.T) {
+
+	h, err := ParseHand(testHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.StringIn(Native) != h.String() {
+		t.Errorf("Expected the native history to match String")
+	}
+
+	written := h.StringIn(BigBlinds)
+	for _, line := range []string{
+		"Seat 4: frank (65bb in chips)",
+		"grace: posts small blind 0.5bb",
+		"erin: raises 2bb to 3bb",
+		"Uncalled bet (5bb) returned to erin",
+		"Total pot 160.5bb Main pot 87bb. Side pot-1 68bb. | Rake 5.5bb",
+	} {
+		if !strings.Contains(written, line+"\n") {
+			t.Errorf("Expected %q in\n%v", line, written)
+		}
+	}
+
+	h.Tournament = &Tournament{ID: 1, Level: 1}
+	if s := h.Format(150, Native); s != "1.5" {
+		t.Errorf("Expected 1.5 chips, got %v", s)
+	}
+}
@@ -0,0 +1,151 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ButtonRule is how the button and the blinds move between hands when players
+// join or leave the table.
+type ButtonRule int
+
+// Button rules
+const (
+	// MovingButton moves the button to the next player dealt in, who is
+	// followed by the blinds. Players may skip or post the big blind twice
+	// when others join or leave.
+	MovingButton ButtonRule = iota
+
+	// DeadButton moves the big blind to the next player dealt in. The small
+	// blind is posted by the previous big blind, or is dead if that player
+	// left, and the button moves to the previous small blind, which may be an
+	// empty seat. Heads-up, the button posts the small blind.
+	DeadButton
+)
+
+// Blinds are the button and the blinds of a hand.
+type Blinds struct {
+
+	// Button is the button, which may be an empty seat under the dead button
+	// rule.
+	Button PlayerPosition
+
+	// SmallBlind is the small blind, or 0 if it is dead.
+	SmallBlind PlayerPosition
+
+	// BigBlind is the big blind.
+	BigBlind PlayerPosition
+}
+
+// errNotEnoughPlayers is returned when fewer than two players are dealt in.
+var errNotEnoughPlayers = errors.New("not enough players dealt in")
+
+// NextBlinds returns the button and the blinds of the hand after a hand with
+// blinds prev, at a table of a size at which the players by seat are dealt in
+// according to their status. A hand with no big blind, e.g. the first of a
+// session, moves the button from prev.Button.
+func NextBlinds(rule ButtonRule, prev Blinds, size int,
+	players map[PlayerPosition]*Player) (Blinds, error) {
+
+	h := &Hand{Table: Table{Size: size}, Players: players}
+	if len(h.Seats()) < 2 {
+		return Blinds{}, errNotEnoughPlayers
+	}
+
+	if rule == MovingButton || !h.validSeat(prev.BigBlind) {
+		var b Blinds
+		b.Button = h.NextActive(prev.Button)
+		b.SmallBlind = h.NextActive(b.Button)
+		b.BigBlind = h.NextActive(b.SmallBlind)
+		if b.BigBlind == b.Button {
+			// Heads-up, the button posts the small blind.
+			b.SmallBlind, b.BigBlind = b.Button, b.SmallBlind
+		}
+		return b, nil
+	}
+
+	b := Blinds{BigBlind: h.NextActive(prev.BigBlind)}
+	if len(h.Seats()) == 2 {
+		b.Button = h.NextActive(b.BigBlind)
+		b.SmallBlind = b.Button
+		return b, nil
+	}
+	if h.dealtIn(prev.BigBlind) {
+		b.SmallBlind = prev.BigBlind
+	}
+
+	// Without a previous small blind, the button moves to the seat right of
+	// the previous big blind.
+	b.Button = prev.SmallBlind
+	if !h.validSeat(b.Button) || b.Button == prev.BigBlind {
+		b.Button = PreviousPlayerPosition(prev.BigBlind, h.TableSize())
+	}
+	return b, nil
+}
+
+// MissedBigBlind returns the seats of the players sitting out whom the big
+// blind passed between hands with blinds prev and next at a table of a size, in
+// the order it passed them.
+func MissedBigBlind(prev, next Blinds, size int,
+	players map[PlayerPosition]*Player) []PlayerPosition {
+	h := &Hand{Table: Table{Size: size}, Players: players}
+	if !h.validSeat(prev.BigBlind) || !h.validSeat(next.BigBlind) {
+		return nil
+	}
+	var missed []PlayerPosition
+	size = h.TableSize()
+	for pos := NextPlayerPosition(prev.BigBlind, size); pos != next.BigBlind &&
+		pos != prev.BigBlind; pos = NextPlayerPosition(pos, size) {
+		if h.seated(pos) && !h.dealtIn(pos) {
+			missed = append(missed, pos)
+		}
+	}
+	return missed
+}
+
+// validSeat returns whether a position is a seat of the table.
+func (h *Hand) validSeat(pos PlayerPosition) bool {
+	return pos >= 1 && int(pos) <= h.TableSize()
+}
+
+// Blinds returns the button and the blinds of the hand.
+func (h *Hand) Blinds() Blinds {
+	return Blinds{Button: h.Button, SmallBlind: h.SmallBlind, BigBlind: h.BigBlind}
+}
+
+// AssignBlinds sets the button and the blinds of the hand, which follows a
+// hand with blinds prev.
+func (h *Hand) AssignBlinds(rule ButtonRule, prev Blinds) error {
+	b, err := NextBlinds(rule, prev, h.Table.Size, h.Players)
+	if err != nil {
+		return fmt.Errorf("hand %v: %v", h.HandID, err)
+	}
+	h.Button, h.SmallBlind, h.BigBlind = b.Button, b.SmallBlind, b.BigBlind
+	return nil
+}
+
+// ValidateBlinds checks the button and the blinds of the hand, which follows a
+// hand with blinds prev, e.g. to catch blinds guessed wrong by a screen
+// scraper. It returns a violation for each which differs from the rule.
+func (h *Hand) ValidateBlinds(rule ButtonRule, prev Blinds) []Violation {
+	b, err := NextBlinds(rule, prev, h.Table.Size, h.Players)
+	if err != nil {
+		return []Violation{{InvalidPosition, -1, -1, err.Error()}}
+	}
+	var violations []Violation
+	for _, blind := range []struct {
+		name             string
+		actual, expected PlayerPosition
+	}{
+		{"button", h.Button, b.Button},
+		{"small blind", h.SmallBlind, b.SmallBlind},
+		{"big blind", h.BigBlind, b.BigBlind},
+	} {
+		if blind.actual != blind.expected {
+			violations = append(violations, Violation{InvalidPosition, -1, -1,
+				fmt.Sprintf("%v is seat %v, expected seat %v", blind.name, blind.actual,
+					blind.expected)})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,128 @@
+package poker
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testSeats returns players by seat from a string of seats, in which 'A' is a
+// player dealt in, 'S' a player sitting out and '.' an empty seat.
+func testSeats(seats string) map[PlayerPosition]*Player {
+	players := make(map[PlayerPosition]*Player)
+	for i := 0; i < len(seats); i++ {
+		switch seats[i] {
+		case 'A':
+			players[PlayerPosition(i+1)] = &Player{Name: string(rune('a' + i)), Stack: 100}
+		case 'S':
+			players[PlayerPosition(i+1)] = &Player{Name: string(rune('a' + i)), Stack: 100,
+				Status: SittingOut}
+		}
+	}
+	return players
+}
+
+// NextBlinds() ////////////////////////////////////////////////////////////////
+
+func TestNextBlinds(t *testing.T) {
+
+	type testPair struct {
+		rule   ButtonRule
+		prev   Blinds
+		seats  string
+		output Blinds
+		missed []PlayerPosition
+	}
+	tests := []testPair{
+		{MovingButton, Blinds{1, 2, 3}, "AAAA", Blinds{2, 3, 4}, nil},
+		{MovingButton, Blinds{1, 2, 4}, "AA.A", Blinds{2, 4, 1}, nil},
+		{MovingButton, Blinds{}, "AAA", Blinds{1, 2, 3}, nil},
+		// Heads-up, the button posts the small blind.
+		{MovingButton, Blinds{1, 2, 4}, "AS.A", Blinds{4, 4, 1}, nil},
+		{DeadButton, Blinds{1, 2, 3}, "AAAA", Blinds{2, 3, 4}, nil},
+		// The big blind left, so the small blind is dead.
+		{DeadButton, Blinds{1, 2, 3}, "AA.AA", Blinds{2, 0, 4}, nil},
+		// The small blind was dead, so the button is dead.
+		{DeadButton, Blinds{2, 0, 4}, "AA.AA", Blinds{3, 4, 5}, nil},
+		{DeadButton, Blinds{1, 2, 3}, "AAASA", Blinds{2, 3, 5}, []PlayerPosition{4}},
+		{DeadButton, Blinds{1, 1, 3}, "A.A", Blinds{3, 3, 1}, nil},
+		{DeadButton, Blinds{}, "AAA", Blinds{1, 2, 3}, nil},
+	}
+
+	for _, test := range tests {
+		players := testSeats(test.seats)
+		b, err := NextBlinds(test.rule, test.prev, len(test.seats), players)
+		if err != nil {
+			t.Errorf("For %v after %v unexpected error %v", test.seats, test.prev, err)
+			continue
+		}
+		if b != test.output {
+			t.Errorf("For %v after %v expected %v, got %v", test.seats, test.prev,
+				test.output, b)
+		}
+		missed := MissedBigBlind(test.prev, b, len(test.seats), players)
+		if !reflect.DeepEqual(missed, test.missed) {
+			t.Errorf("For %v after %v expected missed %v, got %v", test.seats, test.prev,
+				test.missed, missed)
+		}
+	}
+
+	if _, err := NextBlinds(DeadButton, Blinds{1, 2, 3}, 4, testSeats("AS.S")); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestNextBlindsPositions(t *testing.T) {
+
+	// The big blind left, so the small blind is dead and the button stays
+	// with the previous small blind.
+	h := &Hand{Table: Table{Size: 6}, Players: testSeats("AA.AAA")}
+	if err := h.AssignBlinds(DeadButton, Blinds{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if b := h.Blinds(); b != (Blinds{2, 0, 4}) {
+		t.Errorf("Expected %v, got %v", Blinds{2, 0, 4}, b)
+	}
+	expected := map[PlayerPosition]Position{4: BB, 5: UTG, 6: HJ, 1: CO, 2: BTN}
+	if positions := h.Positions(); !reflect.DeepEqual(positions, expected) {
+		t.Errorf("Expected %v, got %v", expected, positions)
+	}
+
+	// The button is dead on the seat the big blind left.
+	if err := h.AssignBlinds(DeadButton, h.Blinds()); err != nil {
+		t.Fatal(err)
+	}
+	if b := h.Blinds(); b != (Blinds{3, 4, 5}) {
+		t.Errorf("Expected %v, got %v", Blinds{3, 4, 5}, b)
+	}
+	expected = map[PlayerPosition]Position{4: SB, 5: BB, 6: HJ, 1: CO, 2: BTN}
+	if positions := h.Positions(); !reflect.DeepEqual(positions, expected) {
+		t.Errorf("Expected %v, got %v", expected, positions)
+	}
+}
+
+// Hand.ValidateBlinds() ///////////////////////////////////////////////////////
+
+func TestValidateBlinds(t *testing.T) {
+
+	h, err := ParseHand(testHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := h.ValidateBlinds(MovingButton, Blinds{2, 4, 6}); len(v) != 0 {
+		t.Errorf("Unexpected violations %v", v)
+	}
+	if v := h.ValidateBlinds(DeadButton, Blinds{2, 4, 6}); len(v) != 0 {
+		t.Errorf("Unexpected violations %v", v)
+	}
+	if v := h.ValidateBlinds(MovingButton, Blinds{1, 2, 4}); len(v) != 3 {
+		t.Errorf("Expected 3 violations, got %v", v)
+	}
+
+	blinds := h.Blinds()
+	if err := h.AssignBlinds(DeadButton, Blinds{2, 4, 6}); err != nil {
+		t.Fatal(err)
+	}
+	if h.Blinds() != blinds {
+		t.Errorf("Expected %v, got %v", blinds, h.Blinds())
+	}
+}
@@ -11,12 +11,34 @@ type card int
 type Card interface {
 	Card() card
 	String() string
+
+	// Value returns the card value, from 2 to 14 (Ace), or 0 for an invalid
+	// card.
+	Value() int
+
+	// Suit returns the suit; 0 (clubs), 1 (diamonds), 2 (hearts) or
+	// 3 (spades). It returns -1 for an invalid card.
+	Suit() int
 }
 
 func (c card) Card() card {
 	return c
 }
 
+func (c card) Value() int {
+	if c < Card2c || c > CardAs {
+		return 0
+	}
+	return int(c)%13 + 2
+}
+
+func (c card) Suit() int {
+	if c < Card2c || c > CardAs {
+		return -1
+	}
+	return int(c) / 13
+}
+
 func (c card) String() string {
 	switch c {
 	case Card2c:
@@ -181,6 +203,15 @@ func ParseCard(str string) (Card, error) {
 	return card((value - 2) + (13 * color)), nil
 }
 
+// NewCard returns the card of a value, from 2 to 14 (Ace), and a suit, from 0
+// (clubs) to 3 (spades). It returns CardInvalid for other values and suits.
+func NewCard(value, suit int) Card {
+	if value < 2 || value > 14 || suit < 0 || suit > 3 {
+		return CardInvalid
+	}
+	return card((value - 2) + (13 * suit))
+}
+
 // MarshalJSON marshals the string representation of a card.
 func (c card) MarshalJSON() ([]byte, error) {
 	return json.Marshal(c.String())
@@ -0,0 +1,56 @@
+package card
+
+import "math/rand"
+
+// Deck is a deck of cards, shuffled by a seeded random source so that the
+// order of the cards can be reproduced.
+type Deck struct {
+	cards []Card
+	rng   *rand.Rand
+}
+
+// NewDeck creates a full, shuffled deck.
+func NewDeck(seed int64) *Deck {
+	d := &Deck{rng: rand.New(rand.NewSource(seed))}
+	d.Shuffle()
+	return d
+}
+
+// Shuffle returns all cards to the deck and shuffles it.
+func (d *Deck) Shuffle() {
+	d.cards = d.cards[:0]
+	for c := Card2c; c <= CardAs; c++ {
+		d.cards = append(d.cards, c)
+	}
+	d.rng.Shuffle(len(d.cards), func(i, j int) {
+		d.cards[i], d.cards[j] = d.cards[j], d.cards[i]
+	})
+}
+
+// Deal removes n cards from the top of the deck. It returns fewer cards if
+// the deck runs out.
+func (d *Deck) Deal(n int) []Card {
+	if n > len(d.cards) {
+		n = len(d.cards)
+	}
+	cards := append([]Card(nil), d.cards[:n]...)
+	d.cards = d.cards[n:]
+	return cards
+}
+
+// Remove removes cards from the deck, e.g. cards which are known to be dealt.
+func (d *Deck) Remove(cards ...Card) {
+	for _, c := range cards {
+		for i := 0; i < len(d.cards); i++ {
+			if d.cards[i].Card() == c.Card() {
+				d.cards = append(d.cards[:i], d.cards[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Len returns the number of cards left in the deck.
+func (d *Deck) Len() int {
+	return len(d.cards)
+}
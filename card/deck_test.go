@@ -0,0 +1,35 @@
+package card
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Deck //////////////////////////////////////////////////////////////////////
+
+func TestDeck(t *testing.T) {
+
+	d := NewDeck(42)
+	cards := d.Deal(52)
+	if len(cards) != 52 || d.Len() != 0 {
+		t.Fatalf("Expected 52 cards, got %v", len(cards))
+	}
+	seen := make(map[card]bool)
+	for _, c := range cards {
+		if seen[c.Card()] {
+			t.Errorf("Duplicate card %v", c)
+		}
+		seen[c.Card()] = true
+	}
+
+	// The same seed deals the same cards.
+	if again := NewDeck(42).Deal(52); !reflect.DeepEqual(cards, again) {
+		t.Errorf("Expected %v, got %v", cards, again)
+	}
+
+	d.Shuffle()
+	d.Remove(cards[0], cards[1])
+	if d.Len() != 50 {
+		t.Errorf("Expected 50 cards, got %v", d.Len())
+	}
+}
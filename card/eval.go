@@ -0,0 +1,284 @@
+package card
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Category is the category of a poker hand.
+type Category int
+
+// List of hand categories, from weakest to strongest
+const (
+	HighCard Category = iota
+	OnePair
+	TwoPair
+	ThreeOfAKind
+	Straight
+	Flush
+	FullHouse
+	FourOfAKind
+	StraightFlush
+)
+
+// String returns the name of the category.
+func (c Category) String() string {
+	switch c {
+	case HighCard:
+		return "high card"
+	case OnePair:
+		return "a pair"
+	case TwoPair:
+		return "two pair"
+	case ThreeOfAKind:
+		return "three of a kind"
+	case Straight:
+		return "a straight"
+	case Flush:
+		return "a flush"
+	case FullHouse:
+		return "a full house"
+	case FourOfAKind:
+		return "four of a kind"
+	case StraightFlush:
+		return "a straight flush"
+	}
+	return "Invalid"
+}
+
+// Strength is the strength of the best five card poker hand out of a set of
+// cards. A stronger hand has a larger strength, and equal hands have equal
+// strength.
+type Strength int
+
+// Category returns the category of the hand.
+func (s Strength) Category() Category {
+	return Category(s >> 20)
+}
+
+// values returns the card values which make up the hand, in order of
+// significance.
+func (s Strength) values() []int {
+	v := make([]int, 5)
+	for i := 0; i < 5; i++ {
+		v[i] = int(s>>uint(16-4*i)) & 0xF
+	}
+	return v
+}
+
+// Description returns a description of the hand in the form used by hand
+// histories, e.g. 'two pair, Aces and Nines'.
+func (s Strength) Description() string {
+	v := s.values()
+	switch s.Category() {
+	case HighCard:
+		return fmt.Sprintf("high card %v", valueName(v[0]))
+	case OnePair:
+		return fmt.Sprintf("a pair of %v", valueNames(v[0]))
+	case TwoPair:
+		return fmt.Sprintf("two pair, %v and %v", valueNames(v[0]), valueNames(v[1]))
+	case ThreeOfAKind:
+		return fmt.Sprintf("three of a kind, %v", valueNames(v[0]))
+	case Straight:
+		return fmt.Sprintf("a straight, %v to %v", lowName(v), valueName(v[0]))
+	case Flush:
+		return fmt.Sprintf("a flush, %v high", valueName(v[0]))
+	case FullHouse:
+		return fmt.Sprintf("a full house, %v full of %v", valueNames(v[0]),
+			valueNames(v[1]))
+	case FourOfAKind:
+		return fmt.Sprintf("four of a kind, %v", valueNames(v[0]))
+	case StraightFlush:
+		if v[0] == 14 {
+			return "a Royal Flush"
+		}
+		return fmt.Sprintf("a straight flush, %v to %v", lowName(v),
+			valueName(v[0]))
+	}
+	return "Invalid"
+}
+
+var valueNamesSingular = []string{"", "", "Deuce", "Three", "Four", "Five", "Six",
+	"Seven", "Eight", "Nine", "Ten", "Jack", "Queen", "King", "Ace"}
+
+var valueNamesPlural = []string{"", "", "Deuces", "Threes", "Fours", "Fives",
+	"Sixes", "Sevens", "Eights", "Nines", "Tens", "Jacks", "Queens", "Kings", "Aces"}
+
+// valueName returns the name of a card value, e.g. 'Ace'.
+func valueName(v int) string {
+	if v < 2 || v > 14 {
+		return "Invalid"
+	}
+	return valueNamesSingular[v]
+}
+
+// lowName returns the name of the lowest card of a straight.
+func lowName(v []int) string {
+	if v[0] == 5 {
+		return valueName(14)
+	}
+	return valueName(v[4])
+}
+
+// valueNames returns the plural name of a card value, e.g. 'Aces'.
+func valueNames(v int) string {
+	if v < 2 || v > 14 {
+		return "Invalid"
+	}
+	return valueNamesPlural[v]
+}
+
+// strength builds a strength from a category and up to five card values.
+func strength(c Category, values ...int) Strength {
+	s := Strength(c) << 20
+	for i := 0; i < len(values) && i < 5; i++ {
+		s |= Strength(values[i]) << uint(16-4*i)
+	}
+	return s
+}
+
+// straightHigh returns the high card of the best straight within a mask of
+// card values, or 0 if there is none.
+func straightHigh(mask uint16) int {
+	for high := 14; high >= 6; high-- {
+		if (mask>>uint(high-4))&0x1F == 0x1F {
+			return high
+		}
+	}
+	// The wheel, A-2-3-4-5.
+	if mask&0x403C == 0x403C {
+		return 5
+	}
+	return 0
+}
+
+// topValues returns the n largest values within a mask of card values.
+func topValues(mask uint16, n int) []int {
+	var values []int
+	for v := 14; v >= 2 && len(values) < n; v-- {
+		if mask&(1<<uint(v)) != 0 {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Evaluate returns the strength of the best five card hand out of five to
+// seven cards.
+func Evaluate(cards ...Card) Strength {
+
+	var suits [4]uint16
+	var counts [15]int
+	var all uint16
+
+	for _, c := range cards {
+		v, s := c.Value(), c.Suit()
+		if v < 2 || s < 0 {
+			continue
+		}
+		suits[s] |= 1 << uint(v)
+		counts[v]++
+		all |= 1 << uint(v)
+	}
+
+	// Flushes and straight flushes.
+	for s := 0; s < 4; s++ {
+		if bitCount(suits[s]) < 5 {
+			continue
+		}
+		if high := straightHigh(suits[s]); high > 0 {
+			return strength(StraightFlush, high, high-1, high-2, high-3, high-4)
+		}
+		return strength(Flush, topValues(suits[s], 5)...)
+	}
+
+	var quads, trips, pairs []int
+	for v := 14; v >= 2; v-- {
+		switch counts[v] {
+		case 4:
+			quads = append(quads, v)
+		case 3:
+			trips = append(trips, v)
+		case 2:
+			pairs = append(pairs, v)
+		}
+	}
+
+	without := func(values ...int) uint16 {
+		mask := all
+		for _, v := range values {
+			mask &^= 1 << uint(v)
+		}
+		return mask
+	}
+
+	switch {
+	case len(quads) > 0:
+		return strength(FourOfAKind, append([]int{quads[0], quads[0], quads[0],
+			quads[0]}, topValues(without(quads[0]), 1)...)...)
+
+	case len(trips) > 0 && (len(trips) > 1 || len(pairs) > 0):
+		pair := 0
+		if len(pairs) > 0 {
+			pair = pairs[0]
+		}
+		if len(trips) > 1 && trips[1] > pair {
+			pair = trips[1]
+		}
+		return strength(FullHouse, trips[0], pair)
+	}
+
+	if high := straightHigh(all); high > 0 {
+		if high == 5 {
+			return strength(Straight, 5, 4, 3, 2, 1)
+		}
+		return strength(Straight, high, high-1, high-2, high-3, high-4)
+	}
+
+	switch {
+	case len(trips) > 0:
+		return strength(ThreeOfAKind, append([]int{trips[0]},
+			topValues(without(trips[0]), 2)...)...)
+	case len(pairs) > 1:
+		return strength(TwoPair, append([]int{pairs[0], pairs[1]},
+			topValues(without(pairs[0], pairs[1]), 1)...)...)
+	case len(pairs) > 0:
+		return strength(OnePair, append([]int{pairs[0]},
+			topValues(without(pairs[0]), 3)...)...)
+	}
+	return strength(HighCard, topValues(all, 5)...)
+}
+
+// bitCount returns the number of set bits.
+func bitCount(mask uint16) int {
+	n := 0
+	for ; mask != 0; mask &= mask - 1 {
+		n++
+	}
+	return n
+}
+
+// ParseCards parses a space separated list of cards, e.g. 'Ah Kd'. Enclosing
+// brackets are ignored.
+func ParseCards(str string) ([]Card, error) {
+	str = strings.Trim(strings.TrimSpace(str), "[]")
+
+	var cards []Card
+	for _, s := range strings.Fields(str) {
+		c, err := ParseCard(s)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, c)
+	}
+	return cards, nil
+}
+
+// FormatCards returns a space separated list of cards, e.g. 'Ah Kd'.
+func FormatCards(cards []Card) string {
+	strs := make([]string, len(cards))
+	for i := 0; i < len(cards); i++ {
+		strs[i] = cards[i].String()
+	}
+	return strings.Join(strs, " ")
+}
@@ -0,0 +1,57 @@
+package card
+
+import "testing"
+
+// Evaluate() //////////////////////////////////////////////////////////////////
+
+type testPairEvaluate struct {
+	input       string
+	description string
+}
+
+var testsEvaluate = []testPairEvaluate{
+	{"As Kd 9h 7c 4s 3d 2c", "high card Ace"},
+	{"As Ad 9h 7c 4s 3d 2c", "a pair of Aces"},
+	{"As Ad 9h 9c 4s 4d 2c", "two pair, Aces and Nines"},
+	{"Ks Kd Kh 9c 4s 3d 2c", "three of a kind, Kings"},
+	{"Ts Jd Qh Kc As 3d 2c", "a straight, Ten to Ace"},
+	{"As 2d 3h 4c 5s Kd Kc", "a straight, Ace to Five"},
+	{"As 9s 7s 4s 2s 3d 2c", "a flush, Ace high"},
+	{"Ks Kd Kh Ac As Ad 2c", "a full house, Aces full of Kings"},
+	{"Ks Kd Kh Kc As 3d 2c", "four of a kind, Kings"},
+	{"5s 6s 7s 8s 9s 3d 2c", "a straight flush, Five to Nine"},
+	{"Ts Js Qs Ks As 3d 2c", "a Royal Flush"},
+}
+
+func TestEvaluate(t *testing.T) {
+
+	tests := testsEvaluate
+
+	for i := 0; i < len(tests); i++ {
+		cards, err := ParseCards(tests[i].input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc := Evaluate(cards...).Description()
+		if desc != tests[i].description {
+			t.Errorf("For %v expected %v, got %v", tests[i].input,
+				tests[i].description, desc)
+		}
+	}
+
+	// Stronger hands compare greater.
+	for i := 1; i < len(tests); i++ {
+		a, _ := ParseCards(tests[i-1].input)
+		b, _ := ParseCards(tests[i].input)
+		if Evaluate(a...) >= Evaluate(b...) && i != 5 {
+			t.Errorf("Expected %v < %v", tests[i-1].input, tests[i].input)
+		}
+	}
+
+	// Kickers
+	a, _ := ParseCards("As Ad Kh 7c 4s 3d 2c")
+	b, _ := ParseCards("As Ad Qh Jc 4s 3d 2c")
+	if Evaluate(a...) <= Evaluate(b...) {
+		t.Errorf("Expected king kicker to beat queen kicker")
+	}
+}
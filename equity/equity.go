@@ -0,0 +1,306 @@
+// Package equity calculates the all-in equity of hole cards and ranges, i.e.
+// the share of the pot each player can expect to win if all cards are dealt.
+package equity
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/whomever000/poker-common/card"
+)
+
+// MaxExact is the most board cards left to deal for which Equity enumerates
+// every runout, rather than sampling them.
+const MaxExact = 2
+
+// MatrixBoards is the number of boards sampled by Matrix.
+const MatrixBoards = 1000
+
+// mask returns a set of cards as bits.
+func mask(cards ...card.Card) uint64 {
+	var m uint64
+	for _, c := range cards {
+		if c.Value() > 0 {
+			m |= 1 << uint(cardIndex(c))
+		}
+	}
+	return m
+}
+
+// cardIndex returns the index of a card in a deck, from 0 to 51.
+func cardIndex(c card.Card) int {
+	return c.Suit()*13 + c.Value() - 2
+}
+
+// deck returns the cards which are not in a set.
+func deck(used uint64) []card.Card {
+	var cards []card.Card
+	for suit := 0; suit < 4; suit++ {
+		for value := 2; value <= 14; value++ {
+			c := card.NewCard(value, suit)
+			if used&(1<<uint(cardIndex(c))) == 0 {
+				cards = append(cards, c)
+			}
+		}
+	}
+	return cards
+}
+
+// Equity returns the equity of each player's hole cards on a board of up to
+// five cards. Runouts are enumerated if at most MaxExact board cards are left
+// to deal, and sampled with a seeded random source otherwise. Ties split the
+// pot.
+func Equity(hands [][]card.Card, board []card.Card, samples int, seed int64) ([]float64, error) {
+
+	used := mask(board...)
+	n := len(board)
+	for _, h := range hands {
+		if len(h) != 2 {
+			return nil, fmt.Errorf("equity: expected two hole cards, got %v", card.FormatCards(h))
+		}
+		used |= mask(h...)
+		n += len(h)
+	}
+	if len(board) > 5 || bitCount(used) != n {
+		return nil, fmt.Errorf("equity: invalid or duplicate cards")
+	}
+
+	e := make([]float64, len(hands))
+	cards := make([]card.Card, 7)
+	strengths := make([]card.Strength, len(hands))
+	showdown := func(full []card.Card) {
+		copy(cards[2:], full)
+		best := card.Strength(-1)
+		winners := 0
+		for i, h := range hands {
+			copy(cards, h)
+			strengths[i] = card.Evaluate(cards...)
+			switch {
+			case strengths[i] > best:
+				best, winners = strengths[i], 1
+			case strengths[i] == best:
+				winners++
+			}
+		}
+		for i := 0; i < len(hands); i++ {
+			if strengths[i] == best {
+				e[i] += 1 / float64(winners)
+			}
+		}
+	}
+
+	rest := deck(used)
+	full := make([]card.Card, 5)
+	copy(full, board)
+	left := 5 - len(board)
+	runouts := 0
+
+	if left <= MaxExact {
+		var deal func(from, k int)
+		deal = func(from, k int) {
+			if k == 5 {
+				showdown(full)
+				runouts++
+				return
+			}
+			for i := from; i < len(rest); i++ {
+				full[k] = rest[i]
+				deal(i+1, k+1)
+			}
+		}
+		deal(0, len(board))
+	} else {
+		rng := rand.New(rand.NewSource(seed))
+		for s := 0; s < samples; s++ {
+			// A partial shuffle deals the missing cards.
+			for k := 0; k < left; k++ {
+				j := k + rng.Intn(len(rest)-k)
+				rest[k], rest[j] = rest[j], rest[k]
+				full[len(board)+k] = rest[k]
+			}
+			showdown(full)
+			runouts++
+		}
+	}
+
+	for i := 0; i < len(e); i++ {
+		if runouts > 0 {
+			e[i] /= float64(runouts)
+		}
+	}
+	return e, nil
+}
+
+// RangeEquity returns the equity of hole cards against a range on a board,
+// with each combination of the range counted by its weight. Combinations which
+// share a card with the hole cards or the board are left out.
+func RangeEquity(hand []card.Card, villain Range, board []card.Card, samples int,
+	seed int64) (float64, error) {
+
+	used := mask(append(append([]card.Card(nil), hand...), board...)...)
+	var total, weight float64
+	for h := 0; h < StartingHands; h++ {
+		if villain[h] <= 0 {
+			continue
+		}
+		for _, combo := range StartingHand(h).Combos() {
+			if used&mask(combo...) != 0 {
+				continue
+			}
+			e, err := Equity([][]card.Card{hand, combo}, board, samples, seed)
+			if err != nil {
+				return 0, err
+			}
+			total += villain[h] * e[0]
+			weight += villain[h]
+		}
+	}
+	if weight == 0 {
+		return 0, fmt.Errorf("equity: range is empty")
+	}
+	return total / weight, nil
+}
+
+// matrix is the preflop equity of each starting hand against every other.
+var matrix struct {
+	once sync.Once
+	m    [StartingHands][StartingHands]float64
+}
+
+// Matrix returns the preflop all-in equity of each starting hand against each
+// other starting hand, e.g. Matrix()[AKs][QQ], over all combinations of both
+// which do not share a card. It samples MatrixBoards boards with a fixed seed
+// the first time it is called.
+func Matrix() *[StartingHands][StartingHands]float64 {
+	matrix.once.Do(func() {
+		computeMatrix(&matrix.m, MatrixBoards, 1)
+	})
+	return &matrix.m
+}
+
+// computeMatrix computes the preflop equity matrix. On each sampled board,
+// every combination of hole cards is compared with every other by sorting them
+// by strength, and then leaving out the pairs which share a card.
+func computeMatrix(m *[StartingHands][StartingHands]float64, boards int, seed int64) {
+
+	const n = StartingHands
+	type combo struct {
+		cards []card.Card
+		mask  uint64
+		hand  int
+	}
+	var combos []combo
+	byCard := make([][]int, 52)
+	for h := 0; h < n; h++ {
+		for _, c := range StartingHand(h).Combos() {
+			for _, x := range c {
+				byCard[cardIndex(x)] = append(byCard[cardIndex(x)], len(combos))
+			}
+			combos = append(combos, combo{c, mask(c...), h})
+		}
+	}
+
+	// Wins count two and ties one, for each pair of starting hands.
+	wins := make([]int64, n*n)
+	counts := make([]int64, n*n)
+	all := deck(0)
+	rng := rand.New(rand.NewSource(seed))
+	cards := make([]card.Card, 7)
+	strengths := make([]card.Strength, len(combos))
+	valid := make([]bool, len(combos))
+	order := make([]int, 0, len(combos))
+
+	for b := 0; b < boards; b++ {
+		for k := 0; k < 5; k++ {
+			j := k + rng.Intn(len(all)-k)
+			all[k], all[j] = all[j], all[k]
+		}
+		used := mask(all[:5]...)
+		copy(cards[2:], all[:5])
+
+		var total [n]int64
+		order = order[:0]
+		for i, c := range combos {
+			valid[i] = c.mask&used == 0
+			if valid[i] {
+				copy(cards, c.cards)
+				strengths[i] = card.Evaluate(cards...)
+				order = append(order, i)
+				total[c.hand]++
+			}
+		}
+		sort.Slice(order, func(i, j int) bool {
+			return strengths[order[i]] < strengths[order[j]]
+		})
+
+		// Each combination beats the weaker ones and ties with the equal ones.
+		var lower, equal [n]int64
+		for i := 0; i < len(order); {
+			j := i
+			for j < len(order) && strengths[order[j]] == strengths[order[i]] {
+				equal[combos[order[j]].hand]++
+				j++
+			}
+			for k := i; k < j; k++ {
+				row := wins[combos[order[k]].hand*n:]
+				for y := 0; y < n; y++ {
+					row[y] += 2*lower[y] + equal[y]
+				}
+			}
+			for k := i; k < j; k++ {
+				h := combos[order[k]].hand
+				lower[h] += equal[h]
+				equal[h] = 0
+			}
+			i = j
+		}
+		for x := 0; x < n; x++ {
+			if total[x] > 0 {
+				row := counts[x*n:]
+				for y := 0; y < n; y++ {
+					row[y] += total[x] * total[y]
+				}
+			}
+		}
+
+		// Combinations which share a card, including the same combination,
+		// cannot meet.
+		for _, a := range order {
+			firstMask := mask(combos[a].cards[0])
+			for k, c := range combos[a].cards {
+				for _, o := range byCard[cardIndex(c)] {
+					if !valid[o] || k > 0 && combos[o].mask&firstMask != 0 {
+						continue
+					}
+					i := combos[a].hand*n + combos[o].hand
+					counts[i]--
+					switch {
+					case strengths[a] > strengths[o]:
+						wins[i] -= 2
+					case strengths[a] == strengths[o]:
+						wins[i]--
+					}
+				}
+			}
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if c := counts[i*n+j]; c > 0 {
+				m[i][j] = float64(wins[i*n+j]) / float64(2*c)
+			}
+		}
+	}
+}
+
+// bitCount returns the number of set bits.
+func bitCount(m uint64) int {
+	n := 0
+	for ; m != 0; m &= m - 1 {
+		n++
+	}
+	return n
+}
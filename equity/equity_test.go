@@ -0,0 +1,129 @@
+package equity
+
+import (
+	"math"
+	"testing"
+
+	"github.com/whomever000/poker-common/card"
+)
+
+// parse parses space separated cards, or panics.
+func parse(str string) []card.Card {
+	cards, err := card.ParseCards(str)
+	if err != nil {
+		panic(err)
+	}
+	return cards
+}
+
+// Equity() ////////////////////////////////////////////////////////////////////
+
+func TestEquity(t *testing.T) {
+
+	type testPair struct {
+		hands     []string
+		board     string
+		equities  []float64
+		tolerance float64
+	}
+	tests := []testPair{
+		// Exact
+		{[]string{"As Ad", "Ks Kd"}, "2c 7h 9d Tc 3s", []float64{1, 0}, 0},
+		{[]string{"As Kd", "Ac Kh"}, "2c 7h 9d Tc 3s", []float64{0.5, 0.5}, 0},
+		{[]string{"Ah Kh", "Qs Qd"}, "2h 7h 9c Qc", []float64{7.0 / 44, 37.0 / 44}, 1e-9},
+		{[]string{"Ah Kh", "Qs Qd", "Jc Tc"}, "2h 7h 9c", nil, -1},
+		// Sampled
+		{[]string{"As Ad", "Ks Kd"}, "", []float64{0.82, 0.18}, 0.02},
+		{[]string{"Ah Kd", "7c 2s"}, "", []float64{0.66, 0.34}, 0.02},
+	}
+
+	for i, test := range tests {
+		var hands [][]card.Card
+		for _, h := range test.hands {
+			hands = append(hands, parse(h))
+		}
+		e, err := Equity(hands, parse(test.board), 10000, 1)
+		if err != nil {
+			t.Fatalf("For test %v unexpected error %v", i, err)
+		}
+		var sum float64
+		for j := 0; j < len(e); j++ {
+			sum += e[j]
+			if test.tolerance >= 0 && math.Abs(e[j]-test.equities[j]) > test.tolerance {
+				t.Errorf("For test %v expected %v, got %v", i, test.equities, e)
+				break
+			}
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Errorf("For test %v expected equities to add up to 1, got %v", i, sum)
+		}
+	}
+
+	if _, err := Equity([][]card.Card{parse("As Ad"), parse("As Kd")}, nil, 100, 1); err == nil {
+		t.Errorf("Expected error for duplicate cards")
+	}
+	if _, err := Equity([][]card.Card{parse("As")}, nil, 100, 1); err == nil {
+		t.Errorf("Expected error for one hole card")
+	}
+}
+
+// RangeEquity() ///////////////////////////////////////////////////////////////
+
+func TestRangeEquity(t *testing.T) {
+
+	r, _ := ParseRange("KK, QQ")
+	e, err := RangeEquity(parse("As Ad"), r, parse("2c 7h 9d Tc"), 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Aces only lose to a set, i.e. to one of the 2 Kings or 2 Queens left.
+	if math.Abs(e-(1-2.0/44)) > 1e-9 {
+		t.Errorf("Expected %v, got %v", 1-2.0/44, e)
+	}
+
+	if _, err := RangeEquity(parse("As Ad"), Range{}, nil, 0, 1); err == nil {
+		t.Errorf("Expected error for an empty range")
+	}
+}
+
+// Matrix() ////////////////////////////////////////////////////////////////////
+
+func TestMatrix(t *testing.T) {
+
+	m := Matrix()
+	hand := func(str string) StartingHand {
+		h, err := ParseStartingHand(str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	type testPair struct {
+		hand, villain string
+		equity        float64
+	}
+	tests := []testPair{
+		{"AA", "KK", 0.82},
+		{"AKo", "72o", 0.66},
+		{"KK", "AKo", 0.70},
+		{"22", "AKs", 0.50},
+		{"AA", "AA", 0.50},
+	}
+	for _, test := range tests {
+		e := m[hand(test.hand)][hand(test.villain)]
+		if math.Abs(e-test.equity) > 0.03 {
+			t.Errorf("For %v against %v expected %v, got %v", test.hand, test.villain,
+				test.equity, e)
+		}
+	}
+
+	for i := 0; i < StartingHands; i++ {
+		for j := 0; j < StartingHands; j++ {
+			if math.Abs(m[i][j]+m[j][i]-1) > 1e-9 {
+				t.Fatalf("For %v and %v expected equities to add up to 1",
+					StartingHand(i), StartingHand(j))
+			}
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package equity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/whomever000/poker-common/card"
+)
+
+// StartingHands is the number of kinds of hole cards in Hold'em.
+const StartingHands = 169
+
+// StartingHand is a kind of hole cards, e.g. AKs. Starting hands are laid out
+// as a 13 by 13 chart with Aces first: pairs on the diagonal, suited hands
+// above and offsuit hands below it.
+type StartingHand int
+
+// rankChars are the characters of card values, from Ace down.
+const rankChars = "AKQJT98765432"
+
+// index returns the row or column of a card value in the chart.
+func index(value int) int {
+	return 14 - value
+}
+
+// NewStartingHand returns the starting hand of hole cards.
+func NewStartingHand(c1, c2 card.Card) StartingHand {
+	high, low := c1.Value(), c2.Value()
+	if low > high {
+		high, low = low, high
+	}
+	if c1.Suit() == c2.Suit() {
+		return StartingHand(index(high)*13 + index(low))
+	}
+	return StartingHand(index(low)*13 + index(high))
+}
+
+// ParseStartingHand parses a starting hand, e.g. 'AA', 'AKs' or 'AKo'.
+func ParseStartingHand(str string) (StartingHand, error) {
+	if len(str) < 2 || len(str) > 3 {
+		return 0, fmt.Errorf("equity: invalid starting hand %q", str)
+	}
+	high := strings.IndexByte(rankChars, upper(str[0]))
+	low := strings.IndexByte(rankChars, upper(str[1]))
+	if high < 0 || low < 0 {
+		return 0, fmt.Errorf("equity: invalid starting hand %q", str)
+	}
+	if low < high {
+		high, low = low, high
+	}
+
+	switch {
+	case high == low && len(str) == 2:
+		return StartingHand(high*13 + low), nil
+	case high != low && len(str) == 3 && (str[2] == 's' || str[2] == 'S'):
+		return StartingHand(high*13 + low), nil
+	case high != low && len(str) == 3 && (str[2] == 'o' || str[2] == 'O'):
+		return StartingHand(low*13 + high), nil
+	}
+	return 0, fmt.Errorf("equity: invalid starting hand %q", str)
+}
+
+// upper returns the upper case of a character.
+func upper(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+// Values returns the card values of the starting hand, the higher first.
+func (h StartingHand) Values() (int, int) {
+	row, col := int(h)/13, int(h)%13
+	if row > col {
+		row, col = col, row
+	}
+	return 14 - row, 14 - col
+}
+
+// IsPair returns whether the starting hand is a pair.
+func (h StartingHand) IsPair() bool {
+	return int(h)/13 == int(h)%13
+}
+
+// IsSuited returns whether the starting hand is suited.
+func (h StartingHand) IsSuited() bool {
+	return int(h)/13 < int(h)%13
+}
+
+// String returns the starting hand, e.g. 'AKs'.
+func (h StartingHand) String() string {
+	if h < 0 || h >= StartingHands {
+		return "Invalid"
+	}
+	high, low := h.Values()
+	str := string([]byte{rankChars[index(high)], rankChars[index(low)]})
+	switch {
+	case h.IsPair():
+		return str
+	case h.IsSuited():
+		return str + "s"
+	default:
+		return str + "o"
+	}
+}
+
+// Combos returns every combination of hole cards of the starting hand.
+func (h StartingHand) Combos() [][]card.Card {
+	high, low := h.Values()
+	var combos [][]card.Card
+	for s1 := 0; s1 < 4; s1++ {
+		for s2 := 0; s2 < 4; s2++ {
+			switch {
+			case h.IsPair() && s2 <= s1:
+			case h.IsSuited() && s2 != s1:
+			case !h.IsPair() && !h.IsSuited() && s2 == s1:
+			default:
+				combos = append(combos, []card.Card{card.NewCard(high, s1),
+					card.NewCard(low, s2)})
+			}
+		}
+	}
+	return combos
+}
+
+// NumCombos returns the number of combinations of hole cards of the starting
+// hand; 6 for pairs, 4 for suited and 12 for offsuit hands.
+func (h StartingHand) NumCombos() int {
+	switch {
+	case h.IsPair():
+		return 6
+	case h.IsSuited():
+		return 4
+	default:
+		return 12
+	}
+}
+
+// Range is a range of hole cards, as the weight of each starting hand from 0
+// (never) to 1 (always).
+type Range [StartingHands]float64
+
+// ParseRange parses a comma separated range, e.g. '77+, A2s+, KTo+, QJ,
+// 99-66, K9s-K6s'. A hand without 's' or 'o' includes both.
+func ParseRange(str string) (Range, error) {
+	var r Range
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if err := r.add(item); err != nil {
+			return Range{}, err
+		}
+	}
+	return r, nil
+}
+
+// add adds a single item of a range.
+func (r *Range) add(item string) error {
+
+	plus := strings.HasSuffix(item, "+")
+	item = strings.TrimSuffix(item, "+")
+	from, to := item, item
+	if i := strings.Index(item, "-"); i >= 0 && !plus {
+		from, to = item[:i], item[i+1:]
+	}
+
+	// Hands without 's' or 'o' are both.
+	if len(from) == 2 && len(to) == 2 && from[0] != from[1] {
+		if err := r.add(withSuffix(item, "s", plus)); err != nil {
+			return err
+		}
+		return r.add(withSuffix(item, "o", plus))
+	}
+
+	first, err := ParseStartingHand(from)
+	if err != nil {
+		return err
+	}
+	last, err := ParseStartingHand(to)
+	if err != nil {
+		return err
+	}
+	high, low := first.Values()
+	lastHigh, lastLow := last.Values()
+
+	switch {
+	case first.IsPair() && last.IsPair():
+		if plus {
+			lastHigh = 14
+		}
+		if lastHigh < high {
+			high, lastHigh = lastHigh, high
+		}
+		for v := high; v <= lastHigh; v++ {
+			r[NewStartingHand(card.NewCard(v, 0), card.NewCard(v, 1))] = 1
+		}
+	case !first.IsPair() && !last.IsPair() && first.IsSuited() == last.IsSuited() &&
+		high == lastHigh:
+		if plus {
+			lastLow = high - 1
+		}
+		if lastLow < low {
+			low, lastLow = lastLow, low
+		}
+		suit := 1
+		if first.IsSuited() {
+			suit = 0
+		}
+		for v := low; v <= lastLow; v++ {
+			r[NewStartingHand(card.NewCard(high, 0), card.NewCard(v, suit))] = 1
+		}
+	default:
+		return fmt.Errorf("equity: invalid range %q", item)
+	}
+	return nil
+}
+
+// withSuffix adds a suffix to each hand of an item, e.g. 'KT-K7' to
+// 'KTs-K7s'.
+func withSuffix(item, suffix string, plus bool) string {
+	parts := strings.Split(item, "-")
+	for i := 0; i < len(parts); i++ {
+		parts[i] += suffix
+	}
+	item = strings.Join(parts, "-")
+	if plus {
+		item += "+"
+	}
+	return item
+}
+
+// String returns the range in the form parsed by ParseRange, including every
+// hand with a weight above 0. Pairs come first, then suited and then offsuit
+// hands.
+func (r Range) String() string {
+
+	var items []string
+	in := func(high, low int, suited bool) bool {
+		suit := 1
+		if suited {
+			suit = 0
+		}
+		return r[NewStartingHand(card.NewCard(high, 0), card.NewCard(low, suit))] > 0
+	}
+
+	// Pairs
+	for v := 14; v >= 2; v-- {
+		if !in(v, v, false) {
+			continue
+		}
+		end := v
+		for end > 2 && in(end-1, end-1, false) {
+			end--
+		}
+		top := string(rankChars[index(v)]) + string(rankChars[index(v)])
+		bottom := string(rankChars[index(end)]) + string(rankChars[index(end)])
+		switch {
+		case v == 14 && end < v:
+			items = append(items, bottom+"+")
+		case end == v:
+			items = append(items, top)
+		default:
+			items = append(items, top+"-"+bottom)
+		}
+		v = end
+	}
+
+	// Suited and offsuit hands, by their higher card.
+	for _, suited := range []bool{true, false} {
+		suffix := "o"
+		if suited {
+			suffix = "s"
+		}
+		for high := 14; high >= 3; high-- {
+			name := func(low int) string {
+				return string(rankChars[index(high)]) + string(rankChars[index(low)]) + suffix
+			}
+			for low := high - 1; low >= 2; low-- {
+				if !in(high, low, suited) {
+					continue
+				}
+				end := low
+				for end > 2 && in(high, end-1, suited) {
+					end--
+				}
+				switch {
+				case low == high-1 && end < low:
+					items = append(items, name(end)+"+")
+				case end == low:
+					items = append(items, name(low))
+				default:
+					items = append(items, name(low)+"-"+name(end))
+				}
+				low = end
+			}
+		}
+	}
+
+	return strings.Join(items, ", ")
+}
+
+// Weight returns the weight of hole cards in the range.
+func (r Range) Weight(c1, c2 card.Card) float64 {
+	return r[NewStartingHand(c1, c2)]
+}
+
+// Combos returns the number of combinations of hole cards in the range,
+// counted by weight.
+func (r Range) Combos() float64 {
+	var n float64
+	for h := 0; h < StartingHands; h++ {
+		n += r[h] * float64(StartingHand(h).NumCombos())
+	}
+	return n
+}
+
+// Fraction returns the fraction of all hole cards in the range, counted by
+// weight.
+func (r Range) Fraction() float64 {
+	return r.Combos() / 1326
+}
+
+// Format returns the range as a 13 by 13 chart, with 'X' for hands in the
+// range, a digit for hands with a weight in tenths, and '.' for hands not in
+// the range.
+func (r Range) Format() string {
+	var b strings.Builder
+	b.WriteString("   ")
+	for col := 0; col < 13; col++ {
+		b.WriteString(" " + string(rankChars[col]))
+	}
+	b.WriteString("\n")
+	for row := 0; row < 13; row++ {
+		b.WriteString(" " + string(rankChars[row]) + " ")
+		for col := 0; col < 13; col++ {
+			w := r[row*13+col]
+			switch {
+			case w >= 1:
+				b.WriteString(" X")
+			case w <= 0:
+				b.WriteString(" .")
+			default:
+				b.WriteString(" " + strconv.Itoa(int(w*10)))
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package equity
+
+import (
+	"testing"
+
+	"github.com/whomever000/poker-common/card"
+)
+
+// ParseStartingHand() /////////////////////////////////////////////////////////
+
+func TestStartingHand(t *testing.T) {
+
+	type testPair struct {
+		cards  string
+		hand   string
+		combos int
+	}
+	tests := []testPair{
+		{"As Ad", "AA", 6},
+		{"Kh Ah", "AKs", 4},
+		{"Ac Kd", "AKo", 12},
+		{"2c 7d", "72o", 12},
+		{"3s 2s", "32s", 4},
+	}
+
+	for _, test := range tests {
+		cards, _ := card.ParseCards(test.cards)
+		h := NewStartingHand(cards[0], cards[1])
+		if h.String() != test.hand {
+			t.Errorf("For %v expected %v, got %v", test.cards, test.hand, h)
+		}
+		parsed, err := ParseStartingHand(test.hand)
+		if err != nil || parsed != h {
+			t.Errorf("For %v expected %v, got %v (%v)", test.hand, h, parsed, err)
+		}
+		combos := h.Combos()
+		if len(combos) != test.combos || h.NumCombos() != test.combos {
+			t.Errorf("For %v expected %v combos, got %v", test.hand, test.combos, len(combos))
+		}
+		for _, c := range combos {
+			if NewStartingHand(c[0], c[1]) != h {
+				t.Errorf("For %v unexpected combo %v", test.hand, card.FormatCards(c))
+			}
+		}
+	}
+
+	for _, str := range []string{"", "A", "AAs", "AK", "AKx", "1K"} {
+		if _, err := ParseStartingHand(str); err == nil {
+			t.Errorf("For %q expected error", str)
+		}
+	}
+}
+
+// ParseRange() ////////////////////////////////////////////////////////////////
+
+func TestParseRange(t *testing.T) {
+
+	type testPair struct {
+		input  string
+		output string
+		combos float64
+	}
+	tests := []testPair{
+		{"", "", 0},
+		{"AA", "AA", 6},
+		{"77+", "77+", 48},
+		{"99-66", "99-66", 24},
+		{"AK", "AKs, AKo", 16},
+		{"A2s+", "A2s+", 48},
+		{"KTo+", "KTo+", 36},
+		{"K9s-K6s", "K9s-K6s", 16},
+		{"QJ+", "QJs, QJo", 16},
+		{"22+, A2+, K9s+, KJo+, T9s", "22+, A2s+, K9s+, T9s, A2o+, KJo+", 78 + 48 + 16 + 4 + 144 + 24},
+		{"A5s, A3s, A2s", "A5s, A3s-A2s", 12},
+	}
+
+	for _, test := range tests {
+		r, err := ParseRange(test.input)
+		if err != nil {
+			t.Errorf("For %q unexpected error %v", test.input, err)
+			continue
+		}
+		if r.String() != test.output {
+			t.Errorf("For %q expected %q, got %q", test.input, test.output, r.String())
+		}
+		if r.Combos() != test.combos {
+			t.Errorf("For %q expected %v combos, got %v", test.input, test.combos, r.Combos())
+		}
+		again, err := ParseRange(r.String())
+		if err != nil || again != r {
+			t.Errorf("For %q expected the range to round trip, got %q", test.input, again)
+		}
+	}
+
+	for _, str := range []string{"AX", "77-AK", "K9s-Q8s", "AKs-AKo"} {
+		if _, err := ParseRange(str); err == nil {
+			t.Errorf("For %q expected error", str)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package poker
+
+import "fmt"
+
+// ParseError is returned when a hand history cannot be parsed.
+type ParseError struct {
+
+	// Line is the 1-indexed line within the hand history at which parsing
+	// failed. A value of 0 means the line is unknown.
+	Line int
+
+	// Reason describes why parsing failed.
+	Reason string
+}
+
+// Error returns the string representation of the error.
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return e.Reason
+	}
+	return fmt.Sprintf("line %v: %v", e.Line, e.Reason)
+}
+
+// AmountError is returned when an amount cannot be parsed.
+type AmountError struct {
+
+	// Input is the string which failed to parse.
+	Input string
+
+	// Reason describes why parsing failed.
+	Reason string
+}
+
+// Error returns the string representation of the error.
+func (e *AmountError) Error() string {
+	return fmt.Sprintf("failed to parse amount %q: %v", e.Input, e.Reason)
+}
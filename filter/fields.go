@@ -0,0 +1,257 @@
+package filter
+
+import (
+	poker "github.com/whomever000/poker-common"
+	"github.com/whomever000/poker-common/card"
+)
+
+// kind is the type of a field.
+type kind int
+
+// Kinds
+const (
+	boolKind kind = iota
+	intKind
+	amountKind
+	stringKind
+	positionKind
+	streetKind
+	textureKind
+	spotKind
+)
+
+// kindNames are the names of the kinds.
+var kindNames = []string{"boolean", "integer", "amount", "string", "position", "street",
+	"texture", "pot type"}
+
+// String returns the name of the kind.
+func (k kind) String() string {
+	return kindNames[k]
+}
+
+// ordered returns whether values of the kind can be compared by order.
+func (k kind) ordered() bool {
+	return k == intKind || k == amountKind || k == streetKind || k == spotKind
+}
+
+// Streets, as the index of the last betting round of a hand
+var streetNames = []string{"preflop", "flop", "turn", "river"}
+
+// Textures of the flop, by its number of suits
+var textureNames = []string{"", "monotone", "twotone", "rainbow"}
+
+// field is a field of a hand. Values of all kinds but strings are numbers.
+// A field returns false if it has no value, e.g. the hero of a hand without
+// one, and comparisons with it are false.
+type field struct {
+	kind kind
+	num  func(h *poker.Hand) (int64, bool)
+	str  func(h *poker.Hand) (string, bool)
+}
+
+// fields are the fields by name.
+var fields = map[string]field{
+	"client": {kind: stringKind, str: func(h *poker.Hand) (string, bool) {
+		return h.Client, true
+	}},
+	"table": {kind: stringKind, str: func(h *poker.Hand) (string, bool) {
+		return h.Table.Name, true
+	}},
+	"id": {kind: intKind, num: number(func(h *poker.Hand) int {
+		return h.HandID
+	})},
+	"players": {kind: intKind, num: number(func(h *poker.Hand) int {
+		return len(h.Seats())
+	})},
+	"tournament": {kind: boolKind, num: boolean(func(h *poker.Hand) bool {
+		return h.Tournament != nil
+	})},
+	"stakes.sb": {kind: amountKind, num: amount(func(h *poker.Hand) poker.Amount {
+		return h.Table.Stakes.SmallBlind
+	})},
+	"stakes.bb": {kind: amountKind, num: amount(func(h *poker.Hand) poker.Amount {
+		return h.Table.Stakes.BigBlind
+	})},
+	"pot": {kind: amountKind, num: pot},
+	"rake": {kind: amountKind, num: func(h *poker.Hand) (int64, bool) {
+		if h.Result == nil {
+			return 0, false
+		}
+		return int64(h.Result.Rake), true
+	}},
+	"street": {kind: streetKind, num: func(h *poker.Hand) (int64, bool) {
+		if len(h.Rounds) == 0 {
+			return 0, false
+		}
+		return int64(minInt(len(h.Rounds), len(streetNames)) - 1), true
+	}},
+	"showdown": {kind: boolKind, num: boolean(func(h *poker.Hand) bool {
+		return h.Result != nil && len(h.Result.ShowDowns) > 0
+	})},
+	"board.cards": {kind: intKind, num: number(func(h *poker.Hand) int {
+		return len(board(h))
+	})},
+	"board.texture": {kind: textureKind, num: texture},
+	"board.paired":  {kind: boolKind, num: boolean(paired)},
+	"hero.name": {kind: stringKind, str: func(h *poker.Hand) (string, bool) {
+		if p := hero(h); p != nil {
+			return p.Name, true
+		}
+		return "", false
+	}},
+	"hero.position": {kind: positionKind, num: func(h *poker.Hand) (int64, bool) {
+		if hero(h) == nil {
+			return 0, false
+		}
+		return int64(h.Positions()[h.ThisPlayer.Position]), true
+	}},
+	"hero.stack": {kind: amountKind, num: func(h *poker.Hand) (int64, bool) {
+		if p := hero(h); p != nil {
+			return int64(p.Stack), true
+		}
+		return 0, false
+	}},
+	"hero.net": {kind: amountKind, num: net},
+	"hero.aggressor": {kind: boolKind, num: role(func(r poker.SpotRole) bool {
+		return r.Aggressor
+	})},
+	"hero.inposition": {kind: boolKind, num: role(func(r poker.SpotRole) bool {
+		return r.InPosition
+	})},
+	"spot": {kind: spotKind, num: func(h *poker.Hand) (int64, bool) {
+		return int64(h.Spot().Type), true
+	}},
+	"spot.isolated": {kind: boolKind, num: boolean(func(h *poker.Hand) bool {
+		return h.Spot().Isolated
+	})},
+	"spot.squeeze": {kind: boolKind, num: boolean(func(h *poker.Hand) bool {
+		return h.Spot().Squeeze
+	})},
+	"spot.multiway": {kind: boolKind, num: boolean(func(h *poker.Hand) bool {
+		return h.Spot().Multiway()
+	})},
+}
+
+// number returns a field of an integer.
+func number(f func(h *poker.Hand) int) func(h *poker.Hand) (int64, bool) {
+	return func(h *poker.Hand) (int64, bool) {
+		return int64(f(h)), true
+	}
+}
+
+// amount returns a field of an amount.
+func amount(f func(h *poker.Hand) poker.Amount) func(h *poker.Hand) (int64, bool) {
+	return func(h *poker.Hand) (int64, bool) {
+		return int64(f(h)), true
+	}
+}
+
+// boolean returns a field of a boolean.
+func boolean(f func(h *poker.Hand) bool) func(h *poker.Hand) (int64, bool) {
+	return func(h *poker.Hand) (int64, bool) {
+		if f(h) {
+			return 1, true
+		}
+		return 0, true
+	}
+}
+
+// pot returns the total pot, including rake and jackpot, or the pot of the
+// last betting round of a hand without a result.
+func pot(h *poker.Hand) (int64, bool) {
+	if h.Result == nil {
+		if len(h.Rounds) == 0 {
+			return 0, false
+		}
+		return int64(h.Rounds[len(h.Rounds)-1].Pot), true
+	}
+	total := h.Result.Rake + h.Result.Jackpot
+	for i := 0; i < len(h.Result.Pots); i++ {
+		total += h.Result.Pots[i].Amount
+	}
+	return int64(total), true
+}
+
+// hero returns the player whose hole cards are known, or nil.
+func hero(h *poker.Hand) *poker.Player {
+	if h.ThisPlayer == nil {
+		return nil
+	}
+	return h.ThisPlayer.Position.Player(h)
+}
+
+// net returns the hero's winnings less the amount they put in the pot.
+func net(h *poker.Hand) (int64, bool) {
+	p := hero(h)
+	if p == nil || h.Result == nil {
+		return 0, false
+	}
+	r := poker.Replay(h)
+	for r.Next() {
+	}
+	if r.Err() != nil {
+		return 0, false
+	}
+	s := r.State()
+	s.NextRound()
+	pos := h.ThisPlayer.Position
+	return int64(s.Stacks[pos] + h.Result.Winnings()[pos] - p.Stack), true
+}
+
+// role returns a field of the hero's role in the spot, which has no value if
+// the hero did not see the flop.
+func role(f func(r poker.SpotRole) bool) func(h *poker.Hand) (int64, bool) {
+	return func(h *poker.Hand) (int64, bool) {
+		if hero(h) == nil {
+			return 0, false
+		}
+		r, ok := h.Spot().Roles[h.ThisPlayer.Position]
+		if !ok {
+			return 0, false
+		}
+		if f(r) {
+			return 1, true
+		}
+		return 0, true
+	}
+}
+
+// board returns the board cards.
+func board(h *poker.Hand) []card.Card {
+	if len(h.Rounds) == 0 {
+		return nil
+	}
+	return h.Rounds[len(h.Rounds)-1].Cards
+}
+
+// texture returns the number of suits of the flop.
+func texture(h *poker.Hand) (int64, bool) {
+	b := board(h)
+	if len(b) < 3 {
+		return 0, false
+	}
+	suits := make(map[int]bool)
+	for _, c := range b[:3] {
+		suits[c.Suit()] = true
+	}
+	return int64(len(suits)), true
+}
+
+// paired returns whether two board cards have the same value.
+func paired(h *poker.Hand) bool {
+	values := make(map[int]bool)
+	for _, c := range board(h) {
+		if values[c.Value()] {
+			return true
+		}
+		values[c.Value()] = true
+	}
+	return false
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,296 @@
+// Package filter selects hands with filters such as
+//
+//	hero.position=BTN and pot>20bb and street>=turn and showdown
+//
+// A filter compares fields of a hand with values, and combines comparisons
+// with 'and', 'or', 'not' and parentheses. Boolean fields, such as showdown,
+// stand alone. Fields are checked against their types when a filter is
+// compiled, so that a compiled filter cannot fail.
+//
+// Amounts are in the currency of the hand, e.g. 'pot>20' is a pot of more
+// than $20, or in big blinds with the suffix 'bb'. Comparisons with a field
+// which has no value, e.g. the hero of a hand without one, are false.
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	poker "github.com/whomever000/poker-common"
+	"github.com/whomever000/poker-common/store"
+)
+
+// Error is returned when a filter cannot be compiled.
+type Error struct {
+
+	// Column is the 1-indexed column of the filter at which the error was
+	// found.
+	Column int
+
+	// Reason describes the error.
+	Reason string
+}
+
+// Error returns the string representation of the error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("filter: column %v: %v", e.Column, e.Reason)
+}
+
+// Filter is a compiled filter.
+type Filter struct {
+	src   string
+	match func(h *poker.Hand) bool
+}
+
+// Compile parses and type checks a filter.
+func Compile(src string) (*Filter, error) {
+	n, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	match, err := compile(n)
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{src: src, match: match}, nil
+}
+
+// MustCompile is like Compile but panics if the filter cannot be compiled.
+func MustCompile(src string) *Filter {
+	f, err := Compile(src)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Fields returns the names of the fields filters may use.
+func Fields() []string {
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String returns the source of the filter.
+func (f *Filter) String() string {
+	return f.src
+}
+
+// Match returns whether a hand matches the filter.
+func (f *Filter) Match(h *poker.Hand) bool {
+	return f.match(h)
+}
+
+// Slice returns the hands which match the filter.
+func (f *Filter) Slice(hands []*poker.Hand) []*poker.Hand {
+	var matches []*poker.Hand
+	for _, h := range hands {
+		if f.match(h) {
+			matches = append(matches, h)
+		}
+	}
+	return matches
+}
+
+// Stream passes on the hands of a channel which match the filter. The returned
+// channel is closed when the input channel is.
+func (f *Filter) Stream(hands <-chan *poker.Hand) <-chan *poker.Hand {
+	matches := make(chan *poker.Hand)
+	go func() {
+		defer close(matches)
+		for h := range hands {
+			if f.match(h) {
+				matches <- h
+			}
+		}
+	}()
+	return matches
+}
+
+// Cursor returns a cursor over the hands of a cursor which match the filter,
+// e.g. to filter the hands of a store query.
+func (f *Filter) Cursor(c store.Cursor) store.Cursor {
+	return &cursor{Cursor: c, filter: f}
+}
+
+// cursor is a cursor which skips hands not matching a filter.
+type cursor struct {
+	store.Cursor
+	filter *Filter
+}
+
+// Next advances to the next matching hand.
+func (c *cursor) Next() bool {
+	for c.Cursor.Next() {
+		if c.filter.match(c.Hand()) {
+			return true
+		}
+	}
+	return false
+}
+
+// compile compiles the syntax tree of a filter.
+func compile(n node) (func(h *poker.Hand) bool, error) {
+	switch n := n.(type) {
+	case *logical:
+		left, err := compile(n.left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := compile(n.right)
+		if err != nil {
+			return nil, err
+		}
+		if n.or {
+			return func(h *poker.Hand) bool { return left(h) || right(h) }, nil
+		}
+		return func(h *poker.Hand) bool { return left(h) && right(h) }, nil
+	case *negation:
+		expr, err := compile(n.expr)
+		if err != nil {
+			return nil, err
+		}
+		return func(h *poker.Hand) bool { return !expr(h) }, nil
+	default:
+		return compileComparison(n.(*comparison))
+	}
+}
+
+// compileComparison type checks and compiles a comparison.
+func compileComparison(c *comparison) (func(h *poker.Hand) bool, error) {
+
+	f, ok := fields[strings.ToLower(c.field.text)]
+	if !ok {
+		return nil, &Error{c.field.at, fmt.Sprintf("unknown field %v", c.field.text)}
+	}
+	if c.value == nil {
+		if f.kind != boolKind {
+			return nil, &Error{c.field.at, fmt.Sprintf("%v is not boolean but %v",
+				c.field.text, f.kind)}
+		}
+		return func(h *poker.Hand) bool {
+			v, ok := f.num(h)
+			return ok && v != 0
+		}, nil
+	}
+	if c.op != "=" && c.op != "!=" && !f.kind.ordered() {
+		return nil, &Error{c.field.at, fmt.Sprintf("%v %v cannot be compared with %v",
+			f.kind, c.field.text, c.op)}
+	}
+
+	if f.kind == stringKind {
+		if c.value.kind == numberToken {
+			return nil, &Error{c.value.at, fmt.Sprintf("expected a string, got %v",
+				c.value.text)}
+		}
+		value := c.value.text
+		equal := c.op == "="
+		return func(h *poker.Hand) bool {
+			s, ok := f.str(h)
+			return ok && (s == value) == equal
+		}, nil
+	}
+
+	value, err := literal(f.kind, c.value)
+	if err != nil {
+		return nil, err
+	}
+	compare := comparisons[c.op]
+	return func(h *poker.Hand) bool {
+		v, ok := f.num(h)
+		return ok && compare(v, value(h))
+	}, nil
+}
+
+// comparisons are the comparisons by operator.
+var comparisons = map[string]func(a, b int64) bool{
+	"=":  func(a, b int64) bool { return a == b },
+	"!=": func(a, b int64) bool { return a != b },
+	"<":  func(a, b int64) bool { return a < b },
+	"<=": func(a, b int64) bool { return a <= b },
+	">":  func(a, b int64) bool { return a > b },
+	">=": func(a, b int64) bool { return a >= b },
+}
+
+// literal type checks a value of a kind, and returns its value in a hand.
+// Amounts in big blinds depend on the hand.
+func literal(k kind, t *token) (func(h *poker.Hand) int64, error) {
+
+	fail := func() (func(h *poker.Hand) int64, error) {
+		return nil, &Error{t.at, fmt.Sprintf("expected %v, got %v", article(k), t.text)}
+	}
+	constant := func(v int64) (func(h *poker.Hand) int64, error) {
+		return func(h *poker.Hand) int64 { return v }, nil
+	}
+	name := strings.ToLower(t.text)
+
+	switch k {
+	case boolKind:
+		switch name {
+		case "true":
+			return constant(1)
+		case "false":
+			return constant(0)
+		}
+	case intKind:
+		if v, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return constant(v)
+		}
+	case amountKind:
+		if t.kind != numberToken {
+			return fail()
+		}
+		sign := int64(1)
+		if strings.HasPrefix(name, "-") {
+			sign, name = -1, name[1:]
+		}
+		if strings.HasSuffix(name, "bb") {
+			bb, err := strconv.ParseFloat(name[:len(name)-2], 64)
+			if err != nil || bb < 0 {
+				return fail()
+			}
+			return func(h *poker.Hand) int64 {
+				return sign * int64(h.Table.Stakes.FromBigBlinds(bb))
+			}, nil
+		}
+		a, err := poker.ParseAmount(name)
+		if err != nil || a < 0 {
+			return fail()
+		}
+		return constant(sign * int64(a))
+	case positionKind:
+		if p, err := poker.ParsePosition(t.text); err == nil {
+			return constant(int64(p))
+		}
+	case streetKind:
+		for i := 0; i < len(streetNames); i++ {
+			if name == streetNames[i] {
+				return constant(int64(i))
+			}
+		}
+	case spotKind:
+		if t, err := poker.ParsePotType(t.text); err == nil {
+			return constant(int64(t))
+		}
+	case textureKind:
+		for i := 1; i < len(textureNames); i++ {
+			if name == textureNames[i] {
+				return constant(int64(i))
+			}
+		}
+	}
+	return fail()
+}
+
+// article returns the name of a kind with its indefinite article.
+func article(k kind) string {
+	if strings.ContainsRune("aeiou", rune(k.String()[0])) {
+		return "an " + k.String()
+	}
+	return "a " + k.String()
+}
@@ -0,0 +1,186 @@
+package filter
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	poker "github.com/whomever000/poker-common"
+	"github.com/whomever000/poker-common/store"
+)
+
+const testHistory = `PokerStars Hand #1001: Hold'em No Limit ($0.05/$0.10 USD) - 2016/03/04 20:15:42 UTC
+Table 'Alcyone' 6-max Seat #4 is the button
+Seat 1: dave ($3 in chips)
+Seat 2: erin ($10 in chips)
+Seat 4: frank ($6.50 in chips)
+Seat 6: grace ($10 in chips)
+grace: posts small blind $0.05
+dave: posts big blind $0.10
+*** HOLE CARDS ***
+Dealt to erin [Qh Qd]
+erin: raises $0.20 to $0.30
+frank: calls $0.30
+grace: folds
+dave: calls $0.20
+*** FLOP *** [2c 7d Kh]
+dave: bets $2.70 and is all-in
+erin: raises $4 to $6.70
+frank: calls $6.20 and is all-in
+Uncalled bet ($0.50) returned to erin
+*** TURN *** [2c 7d Kh] [3s]
+*** RIVER *** [2c 7d Kh 3s] [9c]
+*** SHOW DOWN ***
+erin: shows [Qh Qd] (a pair of Queens)
+frank: shows [Kd Js] (a pair of Kings)
+dave: mucks hand
+frank collected $6.80 from side pot-1
+frank collected $8.70 from main pot
+*** SUMMARY ***
+Total pot $16.05 Main pot $8.70. Side pot-1 $6.80. | Rake $0.55
+Board [2c 7d Kh 3s 9c]
+Seat 1: dave (big blind) mucked [Ac 4c]
+Seat 2: erin showed [Qh Qd] and lost with a pair of Queens
+Seat 4: frank (button) showed [Kd Js] and won ($15.50) with a pair of Kings
+Seat 6: grace (small blind) folded before Flop
+`
+
+// testHands returns the test hand, and the test hand without a hero as hand
+// 1002.
+func testHands(t *testing.T) []*poker.Hand {
+	h1, err := poker.ParseHand(testHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2, _ := poker.ParseHand(testHistory)
+	h2.HandID = 1002
+	h2.ThisPlayer = nil
+	return []*poker.Hand{h1, h2}
+}
+
+// Filter.Match() //////////////////////////////////////////////////////////////
+
+func TestMatch(t *testing.T) {
+
+	hands := testHands(t)
+
+	type testPair struct {
+		filter string
+		output []bool
+	}
+	tests := []testPair{
+		{"hero.position=CO and pot>20bb and street>=turn and showdown", []bool{true, false}},
+		{"not hero.position=CO", []bool{false, true}},
+		{"board.texture=rainbow", []bool{true, true}},
+		{"board.texture = monotone or board.paired", []bool{false, false}},
+		{"hero.net<-50bb and hero.net>=-$6.50", []bool{true, false}},
+		{"hero.name='erin' and not tournament", []bool{true, false}},
+		{"players=4 and id!=1001", []bool{false, true}},
+		{"(street=flop or street=turn) and showdown", []bool{false, false}},
+		{"stakes.bb=0.10 and stakes.sb<1bb and rake>0", []bool{true, true}},
+		{"pot<=16.05 AND pot>16", []bool{true, true}},
+		{"showdown=false or client=\"PokerStars\"", []bool{true, true}},
+		{"hero.position=utg+1", []bool{false, false}},
+		{"spot=SRP and spot.multiway and not spot.isolated", []bool{true, true}},
+		{"spot<3bet and hero.aggressor and not hero.inposition", []bool{true, false}},
+	}
+
+	for _, test := range tests {
+		f, err := Compile(test.filter)
+		if err != nil {
+			t.Errorf("For %v unexpected error %v", test.filter, err)
+			continue
+		}
+		for i, h := range hands {
+			if m := f.Match(h); m != test.output[i] {
+				t.Errorf("For %v and hand %v expected %v, got %v", test.filter, h.HandID,
+					test.output[i], m)
+			}
+		}
+	}
+}
+
+// Compile() ///////////////////////////////////////////////////////////////////
+
+func TestCompileError(t *testing.T) {
+
+	type testPair struct {
+		filter string
+		column int
+	}
+	tests := []testPair{
+		{"foo=1", 1},
+		{"showdown and pot", 14},
+		{"hero.position>BTN", 1},
+		{"street>=showdown", 9},
+		{"pot>abc", 5},
+		{"pot>2bbb", 5},
+		{"client=1", 8},
+		{"players=2.5", 9},
+		{"showdown and", 13},
+		{"(pot>1", 7},
+		{"pot>2 players", 7},
+		{"pot>", 5},
+		{"pot ! 2", 5},
+		{"client='x", 8},
+		{"pot>2 # 3", 7},
+	}
+
+	for _, test := range tests {
+		_, err := Compile(test.filter)
+		e, ok := err.(*Error)
+		if !ok {
+			t.Errorf("For %v expected error, got %v", test.filter, err)
+		} else if e.Column != test.column {
+			t.Errorf("For %v expected column %v, got %v", test.filter, test.column, e)
+		}
+	}
+}
+
+// Filter.Slice(), Stream() and Cursor() ///////////////////////////////////////
+
+func TestFilterHands(t *testing.T) {
+
+	hands := testHands(t)
+	f := MustCompile("hero.name=erin")
+
+	if matches := f.Slice(hands); !reflect.DeepEqual(matches, hands[:1]) {
+		t.Errorf("Expected %v, got %v", hands[:1], matches)
+	}
+
+	in := make(chan *poker.Hand)
+	go func() {
+		for _, h := range hands {
+			in <- h
+		}
+		close(in)
+	}()
+	var streamed []*poker.Hand
+	for h := range f.Stream(in) {
+		streamed = append(streamed, h)
+	}
+	if !reflect.DeepEqual(streamed, hands[:1]) {
+		t.Errorf("Expected %v, got %v", hands[:1], streamed)
+	}
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "hands.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.Put(hands...); err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.Query(store.Query{Player: "erin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = MustCompile("not hero.name=erin").Cursor(c)
+	var ids []int
+	for c.Next() {
+		ids = append(ids, c.Hand().HandID)
+	}
+	if c.Err() != nil || !reflect.DeepEqual(ids, []int{1002}) {
+		t.Errorf("Expected hand 1002, got %v (%v)", ids, c.Err())
+	}
+}
@@ -0,0 +1,212 @@
+package filter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tokenKind is the kind of a token.
+type tokenKind int
+
+// Token kinds
+const (
+	endToken tokenKind = iota
+	identToken
+	numberToken
+	stringToken
+	opToken
+	openToken
+	closeToken
+)
+
+// token is a token of a filter.
+type token struct {
+	kind tokenKind
+	text string
+
+	// at is the 1-indexed column of the token.
+	at int
+}
+
+// lex splits a filter into tokens, ending with an end token.
+func lex(src string) ([]token, error) {
+
+	var tokens []token
+	r := []rune(src)
+	for i := 0; i < len(r); {
+		c := r[i]
+		start := i
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case c == '(':
+			tokens = append(tokens, token{openToken, "(", i + 1})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{closeToken, ")", i + 1})
+			i++
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			i++
+			if i < len(r) && r[i] == '=' {
+				i++
+			}
+			op := string(r[start:i])
+			if op == "!" {
+				return nil, &Error{start + 1, "expected '!='"}
+			}
+			if op == "==" {
+				op = "="
+			}
+			tokens = append(tokens, token{opToken, op, start + 1})
+		case c == '"' || c == '\'':
+			i++
+			for i < len(r) && r[i] != c {
+				i++
+			}
+			if i == len(r) {
+				return nil, &Error{start + 1, "unterminated string"}
+			}
+			i++
+			tokens = append(tokens, token{stringToken, string(r[start+1 : i-1]), start + 1})
+		case c == '$' || c == '-' || unicode.IsDigit(c):
+			i++
+			for i < len(r) && (unicode.IsDigit(r[i]) || unicode.IsLetter(r[i]) ||
+				strings.ContainsRune("$.,", r[i])) {
+				i++
+			}
+			tokens = append(tokens, token{numberToken, string(r[start:i]), start + 1})
+		case unicode.IsLetter(c) || c == '_':
+			for i < len(r) && (unicode.IsLetter(r[i]) || unicode.IsDigit(r[i]) ||
+				strings.ContainsRune("._+", r[i])) {
+				i++
+			}
+			tokens = append(tokens, token{identToken, string(r[start:i]), start + 1})
+		default:
+			return nil, &Error{start + 1, "unexpected " + string(c)}
+		}
+	}
+	return append(tokens, token{endToken, "", len(r) + 1}), nil
+}
+
+// node is a node of the syntax tree of a filter.
+type node interface{}
+
+// logical is a conjunction or disjunction.
+type logical struct {
+	or          bool
+	left, right node
+}
+
+// negation negates a filter.
+type negation struct {
+	expr node
+}
+
+// comparison compares a field to a value. A field without a value is a
+// boolean field.
+type comparison struct {
+	field token
+	op    string
+	value *token
+}
+
+// parser parses a filter, with the grammar
+//
+//	expr       = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" expr ")" | comparison
+//	comparison = field [ op value ]
+type parser struct {
+	tokens []token
+	next   int
+}
+
+// parse parses a filter into its syntax tree.
+func parse(src string) (node, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != endToken {
+		return nil, &Error{t.at, "unexpected " + t.text}
+	}
+	return n, nil
+}
+
+// peek returns the next token.
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+// keyword consumes the next token if it is a keyword.
+func (p *parser) keyword(word string) bool {
+	if t := p.peek(); t.kind == identToken && strings.EqualFold(t.text, word) {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expr() (node, error) {
+	left, err := p.and()
+	for err == nil && p.keyword("or") {
+		var right node
+		right, err = p.and()
+		left = &logical{true, left, right}
+	}
+	return left, err
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.unary()
+	for err == nil && p.keyword("and") {
+		var right node
+		right, err = p.unary()
+		left = &logical{false, left, right}
+	}
+	return left, err
+}
+
+func (p *parser) unary() (node, error) {
+	if p.keyword("not") {
+		n, err := p.unary()
+		return &negation{n}, err
+	}
+	t := p.peek()
+	switch t.kind {
+	case openToken:
+		p.next++
+		n, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if c := p.peek(); c.kind != closeToken {
+			return nil, &Error{c.at, "expected ')'"}
+		}
+		p.next++
+		return n, nil
+	case identToken:
+		p.next++
+		c := &comparison{field: t}
+		if op := p.peek(); op.kind == opToken {
+			p.next++
+			v := p.peek()
+			if v.kind != identToken && v.kind != numberToken && v.kind != stringToken {
+				return nil, &Error{v.at, "expected a value"}
+			}
+			p.next++
+			c.op, c.value = op.text, &v
+		}
+		return c, nil
+	case endToken:
+		return nil, &Error{t.at, "unexpected end of filter"}
+	default:
+		return nil, &Error{t.at, "unexpected " + t.text}
+	}
+}
@@ -3,6 +3,8 @@ package poker
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/whomever000/poker-common/card"
@@ -14,22 +16,101 @@ type Round struct {
 	Actions []PlayerAction
 }
 
+// Result is the outcome of a hand.
 type Result struct {
-	Winner    PlayerPosition
-	Pot       Amount
+
+	// Pots are the main pot followed by the side pots. Pot amounts exclude
+	// rake and jackpot.
+	Pots []Pot
+
+	// Rake is the amount taken by the house.
+	Rake Amount
+
+	// Jackpot is the amount taken for a jackpot, such as a bad beat jackpot.
+	Jackpot Amount
+
+	// ShowDowns are the cards shown at showdown.
 	ShowDowns []PlayerCards
+
+	// Mucked are the hands mucked at showdown. The cards are empty if they
+	// are unknown.
+	Mucked []PlayerCards
+}
+
+// Winnings returns the total amount won by each player.
+func (r *Result) Winnings() map[PlayerPosition]Amount {
+	winnings := make(map[PlayerPosition]Amount)
+	for i := 0; i < len(r.Pots); i++ {
+		for _, w := range r.Pots[i].Winners {
+			winnings[w.Position] += w.Amount
+		}
+	}
+	return winnings
 }
 
 type Date time.Time
 
+// String returns the date in the form '2006/01/02 15:04:05 MST'. Eastern Time
+// is written 'ET', as by PokerStars.
 func (d Date) String() string {
-	return time.Time(d).Format("2006/01/02 15:04:05 MST")
+	t := time.Time(d)
+	if t.Location().String() == easternTime {
+		return t.Format("2006/01/02 15:04:05") + " ET"
+	}
+	return t.Format("2006/01/02 15:04:05 MST")
 }
 
 func (d Date) MarshalJSON() ([]byte, error) {
 	return json.Marshal(d.String())
 }
 
+// easternTime is the location of the time zone 'ET', in which PokerStars
+// writes dates.
+const easternTime = "America/New_York"
+
+// timeZones are the locations of the time zones poker clients write dates in.
+var timeZones = map[string]string{
+	"UTC":  "UTC",
+	"GMT":  "UTC",
+	"ET":   easternTime,
+	"EST":  easternTime,
+	"EDT":  easternTime,
+	"PT":   "America/Los_Angeles",
+	"PST":  "America/Los_Angeles",
+	"PDT":  "America/Los_Angeles",
+	"WET":  "Europe/Lisbon",
+	"WEST": "Europe/Lisbon",
+	"CET":  "Europe/Paris",
+	"CEST": "Europe/Paris",
+	"EET":  "Europe/Athens",
+	"EEST": "Europe/Athens",
+}
+
+// ParseDate parses a date in the form '2006/01/02 15:04:05 MST'. The time zone
+// is optional, and dates without one are UTC. Unknown time zones are an error.
+func ParseDate(str string) (Date, error) {
+	fields := strings.Fields(str)
+	if len(fields) < 2 || len(fields) > 3 {
+		return Date{}, fmt.Errorf("failed to parse date: %v", str)
+	}
+	loc := time.UTC
+	if len(fields) == 3 {
+		name, ok := timeZones[fields[2]]
+		if !ok {
+			return Date{}, fmt.Errorf("unknown time zone %v", fields[2])
+		}
+		var err error
+		if loc, err = time.LoadLocation(name); err != nil {
+			return Date{}, fmt.Errorf("time zone %v: %v", fields[2], err)
+		}
+	}
+	t, err := time.ParseInLocation("2006/01/02 15:04:05", fields[0]+" "+fields[1], loc)
+	if err != nil {
+		return Date{}, fmt.Errorf("failed to parse date: %v", str)
+	}
+	return Date(t), nil
+}
+
 type Hand struct {
 	Client     string
 	Table      Table
@@ -39,80 +120,44 @@ type Hand struct {
 	SmallBlind PlayerPosition
 	BigBlind   PlayerPosition
 	ThisPlayer *PlayerCards
-	Players    []Player
-	Rounds     []Round
-	Result     *Result
-}
-
-func (h *Hand) String() string {
-	var str string
-
-	// Header
-	str += fmt.Sprintf("%v Hand #%v: %v ", h.Client, h.HandID, h.Table.Game)
-	str += fmt.Sprintf("(%v) - %v\n", h.Table.Stakes, h.Date)
-	str += fmt.Sprintf("Table '%v' %v-max Seat #%v is the button\n",
-		h.Table.Name, h.Table.Size, h.Button)
-
-	// Player names and stacks
-	for i := 0; i < h.Table.Size; i++ {
-		str += fmt.Sprintf("Seat %v: %v (%v in chips) \n", i+1, h.Players[i].Name,
-			h.Players[i].Stack)
-	}
 
-	// Print small and big blind
-	smallBlind := h.SmallBlind.Player(h).Name
-	bigBlind := h.BigBlind.Player(h).Name
-
-	str += fmt.Sprintf("%v: posts small blind %v\n", smallBlind,
-		h.Table.Stakes.SmallBlind)
-	str += fmt.Sprintf("%v: posts big blind %v\n", bigBlind,
-		h.Table.Stakes.BigBlind)
-
-	// Print betting rounds
-	for r := 0; r < len(h.Rounds); r++ {
-		var i int
-		if r == 0 {
-			str += fmt.Sprintf("*** HOLE CARDS ***\n")
-		} else if r == 1 {
-			str += fmt.Sprintf("*** FLOP *** [%v %v %v]\n", h.Rounds[r].Cards[0],
-				h.Rounds[r].Cards[1], h.Rounds[r].Cards[2])
-		} else if r == 2 {
-			str += fmt.Sprintf("*** TURN *** [%v %v %v] [%v]\n", h.Rounds[r].Cards[0],
-				h.Rounds[r].Cards[1], h.Rounds[r].Cards[2], h.Rounds[r].Cards[3])
-		} else if r == 3 {
-			str += fmt.Sprintf("*** RIVER *** [%v %v %v %v] [%v]\n", h.Rounds[r].Cards[0],
-				h.Rounds[r].Cards[1], h.Rounds[r].Cards[2], h.Rounds[r].Cards[3],
-				h.Rounds[r].Cards[4])
-		}
+	// Players are the players by seat. Empty seats have no player.
+	Players map[PlayerPosition]*Player
 
-		for ; i < len(h.Rounds[r].Actions); i++ {
-			player := h.Rounds[r].Actions[i].Position.Player(h).Name
-			action := h.Rounds[r].Actions[i].Action
+	Rounds []Round
+	Result *Result
 
-			str += fmt.Sprintf("%v: %v\n", player, action)
-		}
-	}
+	// Tournament is the tournament the hand is played in, or nil for cash
+	// games.
+	Tournament *Tournament `json:",omitempty"`
 
-	if h.Result == nil {
-		return str
-	}
+	// Extensions holds data from other hand history formats which Hand does
+	// not model, keyed by format and path, so that it survives a round trip.
+	Extensions map[string]json.RawMessage `json:",omitempty"`
+}
 
-	// Print showdown
-	if len(h.Result.ShowDowns) > 0 {
-		str += fmt.Sprintf("*** SHOW DOWN ***\n")
+// Straddler returns the seat which posts the straddle, i.e. the first seat
+// dealt in left of the big blind, or 0 if the stakes have no straddle or fewer
+// than three players are dealt in.
+func (h *Hand) Straddler() PlayerPosition {
+	if h.Table.Stakes.Straddle == 0 || h.BigBlind == 0 {
+		return 0
 	}
-
-	for i := 0; i < len(h.Result.ShowDowns); i++ {
-		player := h.Result.ShowDowns[i].Position.Player(h).Name
-		c1 := h.Result.ShowDowns[i].Cards[0]
-		c2 := h.Result.ShowDowns[i].Cards[1]
-
-		str += fmt.Sprintf("%v: shows [%v %v]\n", player, c1, c2)
+	if len(h.Seats()) < 3 {
+		return 0
 	}
+	return h.NextActive(h.BigBlind)
+}
 
-	// Print winnings
-	str += fmt.Sprintf("%v collected %v from pot", h.Result.Winner.Player(h).Name,
-		h.Result.Pot)
+// String returns the hand history in PokerStars format.
+func (h *Hand) String() string {
+	var b strings.Builder
+	h.write(&b, Native)
+	return b.String()
+}
 
-	return str
+// WriteTo writes the hand history in PokerStars format.
+func (h *Hand) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, h.String())
+	return int64(n), err
 }
@@ -0,0 +1,261 @@
+// Package icm calculates the tournament equity of stacks with the Independent
+// Chip Model, i.e. the money each player can expect to win, rather than the
+// chips.
+//
+// The model assumes that the chance of a player finishing first is their share
+// of the chips, and that finishing second is like finishing first among the
+// other players, and so on. Equities are in the units of the payouts, e.g.
+// cents.
+package icm
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	poker "github.com/whomever000/poker-common"
+)
+
+// MaxExact is the most players Equity calculates exactly. The exact
+// calculation takes time exponential in the number of players.
+const MaxExact = 10
+
+// Samples is the number of finishing orders Equity samples for more players
+// than MaxExact, and for bounties.
+const Samples = 20000
+
+// Progressive is the part of a bounty which is added to the bounty of the
+// player who wins it, in progressive knockout tournaments.
+const Progressive = 0.5
+
+// seed seeds the sampling, so that equities can be repeated.
+const seed = 1
+
+// Equity returns the equity of each stack. It is exact for up to MaxExact
+// players and sampled for more. Players without chips have no equity.
+func Equity(stacks []poker.Amount, payouts poker.Payouts) []float64 {
+	if len(stacks) <= MaxExact {
+		return Exact(stacks, payouts)
+	}
+	return Approximate(stacks, payouts, Samples, seed)
+}
+
+// Exact calculates the equity of each stack exactly. It supports at most 63
+// players, but takes time exponential in the number of players.
+func Exact(stacks []poker.Amount, payouts poker.Payouts) []float64 {
+
+	n := len(stacks)
+	if n > 63 {
+		panic(fmt.Sprintf("icm: too many players: %v", n))
+	}
+
+	// Players who have been placed are a set of bits. The equity each player
+	// gets from the remaining places only depends on that set.
+	memo := make(map[uint64][]float64)
+	var equity func(placed uint64, place int, chips float64) []float64
+	equity = func(placed uint64, place int, chips float64) []float64 {
+		if place >= len(payouts) || chips <= 0 {
+			return nil
+		}
+		if e, ok := memo[placed]; ok {
+			return e
+		}
+		e := make([]float64, n)
+		for i := 0; i < n; i++ {
+			if placed&(1<<uint(i)) != 0 || stacks[i] <= 0 {
+				continue
+			}
+			p := float64(stacks[i]) / chips
+			e[i] += p * float64(payouts[place])
+			rest := equity(placed|1<<uint(i), place+1, chips-float64(stacks[i]))
+			for j := 0; j < len(rest); j++ {
+				e[j] += p * rest[j]
+			}
+		}
+		memo[placed] = e
+		return e
+	}
+
+	e := equity(0, 0, total(stacks))
+	if e == nil {
+		e = make([]float64, n)
+	}
+	return e
+}
+
+// Approximate estimates the equity of each stack from a number of finishing
+// orders, sampled with a seeded random source. Only the paid places are
+// sampled, so it is fast for large fields.
+func Approximate(stacks []poker.Amount, payouts poker.Payouts, samples int,
+	seed int64) []float64 {
+
+	e := make([]float64, len(stacks))
+	if samples <= 0 {
+		return e
+	}
+	rng := rand.New(rand.NewSource(seed))
+	s := newSampler(stacks)
+	for k := 0; k < samples; k++ {
+		order := s.order(rng, len(payouts))
+		for place, i := range order {
+			e[i] += float64(payouts[place])
+		}
+	}
+	for i := 0; i < len(e); i++ {
+		e[i] /= float64(samples)
+	}
+	return e
+}
+
+// Bounties returns the expected winnings of each player from bounties, given
+// the bounty on each player's head. When a player is knocked out, their bounty
+// goes to the player who knocks them out, except for a part progressive which
+// is added to that player's own bounty. The winner of the tournament gets
+// their own bounty.
+//
+// Finishing orders are sampled as for Approximate, and each knockout is
+// credited to one of the remaining players in proportion to their chips.
+func Bounties(stacks, bounties []poker.Amount, progressive float64, samples int,
+	seed int64) []float64 {
+
+	n := len(stacks)
+	e := make([]float64, n)
+	if samples <= 0 {
+		return e
+	}
+	rng := rand.New(rand.NewSource(seed))
+	s := newSampler(stacks)
+	chips := make([]float64, n)
+	heads := make([]float64, n)
+	for k := 0; k < samples; k++ {
+		order := s.order(rng, n)
+		for _, i := range order {
+			chips[i] = float64(stacks[i])
+			heads[i] = float64(bounties[i])
+		}
+
+		// Players are knocked out from the last place up. Chips only move
+		// between players, so their total stays the same.
+		all := total(stacks)
+		for place := len(order) - 1; place > 0; place-- {
+			out := order[place]
+			x := rng.Float64() * (all - chips[out])
+			by := order[0]
+			for j := 0; j < place; j++ {
+				if x < chips[order[j]] {
+					by = order[j]
+					break
+				}
+				x -= chips[order[j]]
+			}
+
+			e[by] += heads[out] * (1 - progressive)
+			heads[by] += heads[out] * progressive
+			chips[by] += chips[out]
+		}
+		if len(order) > 0 {
+			e[order[0]] += heads[order[0]]
+		}
+	}
+	for i := 0; i < n; i++ {
+		e[i] /= float64(samples)
+	}
+	return e
+}
+
+// Hand returns the equity of each player at the start of a tournament hand,
+// from the tournament's payouts, plus the bounties if they are known.
+func Hand(h *poker.Hand) (map[poker.PlayerPosition]float64, error) {
+
+	t := h.Tournament
+	if t == nil {
+		return nil, fmt.Errorf("icm: hand %v is not a tournament hand", h.HandID)
+	}
+	if len(t.Payouts) == 0 {
+		return nil, fmt.Errorf("icm: tournament %v has no payouts", t.ID)
+	}
+
+	var positions []poker.PlayerPosition
+	var stacks, bounties []poker.Amount
+	for _, pos := range h.Occupied() {
+		if stack := pos.Player(h).Stack; stack > 0 {
+			positions = append(positions, pos)
+			stacks = append(stacks, stack)
+			bounties = append(bounties, t.Bounties[pos])
+		}
+	}
+
+	e := Equity(stacks, t.Payouts)
+	if len(t.Bounties) > 0 {
+		progressive := 0.0
+		if t.Progressive {
+			progressive = Progressive
+		}
+		b := Bounties(stacks, bounties, progressive, Samples, seed)
+		for i := 0; i < len(e); i++ {
+			e[i] += b[i]
+		}
+	}
+
+	equities := make(map[poker.PlayerPosition]float64)
+	for i, pos := range positions {
+		equities[pos] = e[i]
+	}
+	return equities, nil
+}
+
+// total returns the total of stacks.
+func total(stacks []poker.Amount) float64 {
+	var t float64
+	for _, s := range stacks {
+		if s > 0 {
+			t += float64(s)
+		}
+	}
+	return t
+}
+
+// sampler samples finishing orders.
+type sampler struct {
+	stacks []float64
+	keys   []float64
+	index  []int
+}
+
+// newSampler creates a sampler for stacks. Players without chips are never
+// placed.
+func newSampler(stacks []poker.Amount) *sampler {
+	s := &sampler{}
+	for i, stack := range stacks {
+		if stack > 0 {
+			s.stacks = append(s.stacks, float64(stack))
+			s.index = append(s.index, i)
+		}
+	}
+	s.keys = make([]float64, len(s.stacks))
+	return s
+}
+
+// order samples the players finishing in the first places, first place
+// first. Each player draws an exponentially distributed time divided by their
+// chips, and the earliest finishes highest, which places each player with a
+// chance proportional to their chips among those left.
+func (s *sampler) order(rng *rand.Rand, places int) []int {
+	for i := 0; i < len(s.stacks); i++ {
+		s.keys[i] = rng.ExpFloat64() / s.stacks[i]
+	}
+	order := make([]int, len(s.stacks))
+	for i := 0; i < len(order); i++ {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return s.keys[order[i]] < s.keys[order[j]]
+	})
+	if places < len(order) {
+		order = order[:places]
+	}
+	for i := 0; i < len(order); i++ {
+		order[i] = s.index[order[i]]
+	}
+	return order
+}
@@ -0,0 +1,150 @@
+package icm
+
+import (
+	"math"
+	"testing"
+
+	poker "github.com/whomever000/poker-common"
+)
+
+// near returns whether two equities are within a tolerance.
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+// sum returns the sum of equities.
+func sum(e []float64) float64 {
+	var s float64
+	for _, v := range e {
+		s += v
+	}
+	return s
+}
+
+// Equity() ////////////////////////////////////////////////////////////////////
+
+func TestEquity(t *testing.T) {
+
+	type testPair struct {
+		stacks   []poker.Amount
+		payouts  poker.Payouts
+		equities []float64
+	}
+	tests := []testPair{
+		{[]poker.Amount{5000, 3000, 2000}, poker.Payouts{5000, 3000, 2000},
+			[]float64{3839.2857, 3275, 2885.7143}},
+		{[]poker.Amount{100, 100, 100, 100}, poker.Payouts{650, 350},
+			[]float64{250, 250, 250, 250}},
+		{[]poker.Amount{900, 100}, poker.Payouts{1000}, []float64{900, 100}},
+		{[]poker.Amount{900, 0, 100}, poker.Payouts{600, 400}, []float64{580, 0, 420}},
+		{[]poker.Amount{900, 100}, nil, []float64{0, 0}},
+	}
+
+	for i, test := range tests {
+		e := Equity(test.stacks, test.payouts)
+		if len(e) != len(test.equities) {
+			t.Fatalf("For test %v expected %v, got %v", i, test.equities, e)
+		}
+		for j := 0; j < len(e); j++ {
+			if !near(e[j], test.equities[j], 0.001) {
+				t.Errorf("For test %v expected %v, got %v", i, test.equities, e)
+				break
+			}
+		}
+	}
+}
+
+func TestApproximate(t *testing.T) {
+
+	stacks := []poker.Amount{1500, 3000, 800, 2200, 1500, 600, 4000, 1000, 1900, 2500, 700, 1300}
+	payouts := poker.Payouts{5000, 3000, 2000}
+
+	exact := Exact(stacks, payouts)
+	approx := Equity(stacks, payouts)
+	if !near(sum(exact), float64(payouts.Total()), 0.001) {
+		t.Errorf("Expected a total of %v, got %v", payouts.Total(), sum(exact))
+	}
+	for i := 0; i < len(stacks); i++ {
+		if !near(exact[i], approx[i], 50) {
+			t.Errorf("For stack %v expected about %v, got %v", stacks[i], exact[i], approx[i])
+		}
+	}
+
+	// Sampling is repeatable.
+	again := Equity(stacks, payouts)
+	for i := 0; i < len(stacks); i++ {
+		if approx[i] != again[i] {
+			t.Errorf("For stack %v expected %v, got %v", stacks[i], approx[i], again[i])
+		}
+	}
+}
+
+// Bounties() //////////////////////////////////////////////////////////////////
+
+func TestBounties(t *testing.T) {
+
+	stacks := []poker.Amount{1000, 1000, 1000, 1000}
+	bounties := []poker.Amount{500, 500, 500, 500}
+
+	for _, progressive := range []float64{0, Progressive} {
+		e := Bounties(stacks, bounties, progressive, Samples, 1)
+
+		// Every bounty is paid out, and equal players expect the same.
+		if !near(sum(e), 2000, 0.001) {
+			t.Errorf("For %v expected a total of 2000, got %v", progressive, sum(e))
+		}
+		for i := 0; i < len(e); i++ {
+			if !near(e[i], 500, 25) {
+				t.Errorf("For %v expected about 500, got %v", progressive, e)
+				break
+			}
+		}
+	}
+
+	// A big stack wins more bounties.
+	e := Bounties([]poker.Amount{4000, 1000, 1000}, []poker.Amount{500, 500, 500},
+		Progressive, Samples, 1)
+	if e[0] <= e[1] || !near(e[1], e[2], 25) {
+		t.Errorf("Unexpected bounty equities %v", e)
+	}
+
+	// Heads-up, the winner gets both bounties.
+	e = Bounties([]poker.Amount{3000, 1000}, []poker.Amount{1500, 500}, Progressive, Samples, 1)
+	if !near(e[0], 1500, 25) || !near(e[1], 500, 25) {
+		t.Errorf("Unexpected bounty equities %v", e)
+	}
+}
+
+// Hand() //////////////////////////////////////////////////////////////////////
+
+func TestHand(t *testing.T) {
+
+	h := &poker.Hand{
+		HandID: 1,
+		Players: map[poker.PlayerPosition]*poker.Player{
+			1: {Name: "a", Stack: 5000}, 3: {Name: "b", Stack: 3000}, 4: {Name: "c", Stack: 2000},
+		},
+	}
+	if _, err := Hand(h); err == nil {
+		t.Errorf("Expected error for cash game")
+	}
+
+	h.Tournament = &poker.Tournament{ID: 1, Payouts: poker.Payouts{5000, 3000, 2000}}
+	e, err := Hand(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e) != 3 || !near(e[1], 3839.2857, 0.001) || !near(e[4], 2885.7143, 0.001) {
+		t.Errorf("Unexpected equities %v", e)
+	}
+
+	h.Tournament.Bounties = map[poker.PlayerPosition]poker.Amount{1: 500, 3: 500, 4: 500}
+	h.Tournament.Progressive = true
+	e, err = Hand(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(e[1]+e[3]+e[4], 11500, 0.001) || e[1] < 3839.2857+500 {
+		t.Errorf("Unexpected equities %v", e)
+	}
+}
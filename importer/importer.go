@@ -0,0 +1,281 @@
+// Package importer imports hand histories in bulk.
+//
+// Files, directories and archives (.zip, .gz) are split into individual
+// hands, which are parsed in parallel. A broken hand is recorded in the
+// import report and does not stop the import.
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	poker "github.com/whomever000/poker-common"
+)
+
+// Parser parses a single hand history.
+// Parsers should return a *poker.ParseError to report the offending line.
+type Parser func(text string) (*poker.Hand, error)
+
+// Failure describes a hand or file which could not be imported.
+type Failure struct {
+
+	// File is the name of the file. Files within archives are named
+	// 'archive.zip/file.txt'.
+	File string
+
+	// Line is the 1-indexed line within the file, or 0 if unknown.
+	Line int
+
+	// Reason describes why the import failed.
+	Reason string
+}
+
+// String returns a string representation of the failure in the form
+// 'file:line: reason'.
+func (f Failure) String() string {
+	if f.Line == 0 {
+		return fmt.Sprintf("%v: %v", f.File, f.Reason)
+	}
+	return fmt.Sprintf("%v:%v: %v", f.File, f.Line, f.Reason)
+}
+
+// Report summarizes an import.
+type Report struct {
+
+	// Files is the number of files read.
+	Files int
+
+	// Hands is the number of hands found.
+	Hands int
+
+	// Imported is the number of hands successfully parsed.
+	Imported int
+
+	// Failures lists the hands and files which could not be imported.
+	Failures []Failure
+}
+
+// String returns a summary of the report.
+func (r *Report) String() string {
+	str := fmt.Sprintf("%v files, %v hands, %v imported, %v failed\n",
+		r.Files, r.Hands, r.Imported, len(r.Failures))
+	for i := 0; i < len(r.Failures); i++ {
+		str += r.Failures[i].String() + "\n"
+	}
+	return str
+}
+
+// Importer imports hand histories in bulk.
+type Importer struct {
+
+	// Parser parses a single hand.
+	Parser Parser
+
+	// Workers is the number of hands parsed in parallel. A value of 0 means
+	// one worker per CPU.
+	Workers int
+}
+
+// New creates a new importer using the given parser. A nil parser parses
+// PokerStars hand histories.
+func New(parser Parser) *Importer {
+	if parser == nil {
+		parser = poker.ParseHand
+	}
+	return &Importer{Parser: parser}
+}
+
+// job is a single hand waiting to be parsed.
+type job struct {
+	file  string
+	chunk Chunk
+}
+
+// Import imports all hands from the given paths. A path may be a file, a
+// directory (which is walked recursively), a .zip archive or a .gz file.
+// Hands are returned in the order in which they appear in the input.
+func (im *Importer) Import(paths ...string) ([]poker.Hand, *Report) {
+
+	report := &Report{}
+	var jobs []job
+
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				report.fail(name, 0, err)
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			f, err := os.Open(name)
+			if err != nil {
+				report.fail(name, 0, err)
+				return nil
+			}
+			defer f.Close()
+
+			jobs = append(jobs, im.read(report, name, name, f)...)
+			return nil
+		})
+		if err != nil {
+			report.fail(path, 0, err)
+		}
+	}
+
+	return im.parse(report, jobs), report
+}
+
+// ImportReader imports all hands from a reader. The name is used to detect
+// archives and in the report.
+func (im *Importer) ImportReader(name string, r io.Reader) ([]poker.Hand, *Report) {
+	report := &Report{}
+	jobs := im.read(report, name, name, r)
+	return im.parse(report, jobs), report
+}
+
+// read splits a file into hands, unpacking it first if it is an archive. The
+// archive type is detected from the extension of format, which is the name
+// without the extensions of the .gz files it was unpacked from. Failures are
+// reported with the name.
+func (im *Importer) read(report *Report, name, format string, r io.Reader) []job {
+
+	switch strings.ToLower(filepath.Ext(format)) {
+	case ".gz":
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			report.fail(name, 0, err)
+			return nil
+		}
+		defer gz.Close()
+		return im.read(report, name, strings.TrimSuffix(format, filepath.Ext(format)), gz)
+
+	case ".zip":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			report.fail(name, 0, err)
+			return nil
+		}
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			report.fail(name, 0, err)
+			return nil
+		}
+
+		var jobs []job
+		for _, zf := range zr.File {
+			if zf.FileInfo().IsDir() {
+				continue
+			}
+			inner := name + "/" + zf.Name
+			f, err := zf.Open()
+			if err != nil {
+				report.fail(inner, 0, err)
+				continue
+			}
+			jobs = append(jobs, im.read(report, inner, inner, f)...)
+			f.Close()
+		}
+		return jobs
+	}
+
+	report.Files++
+
+	chunks, err := Split(r)
+	if err != nil {
+		report.fail(name, 0, err)
+	}
+
+	jobs := make([]job, len(chunks))
+	for i := 0; i < len(chunks); i++ {
+		jobs[i] = job{name, chunks[i]}
+	}
+	report.Hands += len(jobs)
+	return jobs
+}
+
+// parse parses all jobs in parallel.
+func (im *Importer) parse(report *Report, jobs []job) []poker.Hand {
+
+	workers := im.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	hands := make([]*poker.Hand, len(jobs))
+	failures := make([]*Failure, len(jobs))
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				hands[i], failures[i] = im.ParseChunk(jobs[i].file, jobs[i].chunk)
+			}
+		}()
+	}
+	for i := 0; i < len(jobs); i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var result []poker.Hand
+	for i := 0; i < len(jobs); i++ {
+		if failures[i] != nil {
+			report.Failures = append(report.Failures, *failures[i])
+			continue
+		}
+		result = append(result, *hands[i])
+	}
+	report.Imported += len(result)
+
+	return result
+}
+
+// ParseChunk parses a single hand found in the given file. Parser errors and
+// panics are returned as a failure.
+func (im *Importer) ParseChunk(file string, c Chunk) (hand *poker.Hand, failure *Failure) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			hand = nil
+			failure = &Failure{file, c.Line, fmt.Sprintf("parser panic: %v", r)}
+		}
+	}()
+
+	hand, err := im.Parser(c.Text)
+	if err != nil {
+		line := c.Line
+		if pe, ok := err.(*poker.ParseError); ok {
+			if pe.Line > 0 {
+				line += pe.Line - 1
+			}
+			return nil, &Failure{file, line, pe.Reason}
+		}
+		return nil, &Failure{file, line, err.Error()}
+	}
+	if hand == nil {
+		return nil, &Failure{file, c.Line, "parser returned no hand"}
+	}
+
+	return hand, nil
+}
+
+// fail records a failure.
+func (r *Report) fail(file string, line int, err error) {
+	r.Failures = append(r.Failures, Failure{file, line, err.Error()})
+}
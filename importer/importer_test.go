@@ -0,0 +1,144 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	poker "github.com/whomever000/poker-common"
+)
+
+// testParser parses hands of the form 'Hand #<id>' followed by arbitrary
+// lines. A line reading 'broken' fails the hand, a line reading 'panic'
+// panics.
+func testParser(text string) (*poker.Hand, error) {
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		switch lines[i] {
+		case "broken":
+			return nil, &poker.ParseError{Line: i + 1, Reason: "broken line"}
+		case "panic":
+			panic("unexpected input")
+		}
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(lines[0], "Hand #"))
+	if err != nil {
+		return nil, err
+	}
+	return &poker.Hand{HandID: id}, nil
+}
+
+const testHistory = "Hand #1\nline\n\n\nHand #2\nline\nbroken\n\nHand #3\npanic\n\nHand #4\n"
+
+// Split() /////////////////////////////////////////////////////////////////////
+
+func TestSplit(t *testing.T) {
+
+	chunks, err := Split(strings.NewReader(testHistory))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []int{1, 5, 9, 12}
+	if len(chunks) != len(expected) {
+		t.Fatalf("expected %v chunks, got %v", len(expected), len(chunks))
+	}
+	for i := 0; i < len(expected); i++ {
+		if chunks[i].Line != expected[i] {
+			t.Errorf("For chunk %v expected line %v, got %v",
+				i, expected[i], chunks[i].Line)
+		}
+	}
+}
+
+// Import() ////////////////////////////////////////////////////////////////////
+
+func checkImport(t *testing.T, name string, hands []poker.Hand, report *Report) {
+
+	if len(hands) != 2 || hands[0].HandID != 1 || hands[1].HandID != 4 {
+		t.Errorf("%v: expected hands 1 and 4, got %v", name, hands)
+	}
+	if report.Hands != 4 || report.Imported != 2 {
+		t.Errorf("%v: unexpected report: %v", name, report)
+	}
+	if len(report.Failures) != 2 {
+		t.Fatalf("%v: expected 2 failures, got %v", name, report.Failures)
+	}
+	if report.Failures[0].Line != 7 || report.Failures[0].Reason != "broken line" {
+		t.Errorf("%v: unexpected failure: %v", name, report.Failures[0])
+	}
+	if report.Failures[1].Line != 9 {
+		t.Errorf("%v: unexpected failure: %v", name, report.Failures[1])
+	}
+}
+
+func TestImportReader(t *testing.T) {
+	hands, report := New(testParser).ImportReader("hands.txt",
+		strings.NewReader(testHistory))
+	checkImport(t, "txt", hands, report)
+}
+
+func TestImportArchives(t *testing.T) {
+
+	dir := t.TempDir()
+
+	// Gzip file.
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(testHistory))
+	w.Close()
+
+	gzPath := filepath.Join(dir, "hands.txt.gz")
+	if err := os.WriteFile(gzPath, gz.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	hands, report := New(testParser).Import(gzPath)
+	checkImport(t, "gz", hands, report)
+	if report.Failures[0].File != gzPath {
+		t.Errorf("unexpected file name: %v", report.Failures[0].File)
+	}
+
+	// Zip file within a directory.
+	var zb bytes.Buffer
+	zw := zip.NewWriter(&zb)
+	f, _ := zw.Create("a/hands.txt")
+	f.Write([]byte(testHistory))
+	zw.Close()
+
+	sub := filepath.Join(dir, "sub")
+	os.Mkdir(sub, 0755)
+	zipPath := filepath.Join(sub, "hands.zip")
+	if err := os.WriteFile(zipPath, zb.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	hands, report = New(testParser).Import(sub)
+	checkImport(t, "zip", hands, report)
+	if report.Failures[0].File != zipPath+"/a/hands.txt" {
+		t.Errorf("unexpected file name: %v", report.Failures[0].File)
+	}
+
+	// Gzipped zip file, whose entries are named after the .gz file.
+	gz.Reset()
+	w = gzip.NewWriter(&gz)
+	w.Write(zb.Bytes())
+	w.Close()
+
+	zipGzPath := filepath.Join(dir, "hands.zip.gz")
+	if err := os.WriteFile(zipGzPath, gz.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	hands, report = New(testParser).Import(zipGzPath)
+	checkImport(t, "zip.gz", hands, report)
+	if report.Failures[0].File != zipGzPath+"/a/hands.txt" {
+		t.Errorf("unexpected file name: %v", report.Failures[0].File)
+	}
+}
@@ -0,0 +1,57 @@
+package importer
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Chunk is the text of a single hand history within a larger file.
+type Chunk struct {
+
+	// Line is the 1-indexed line of the file at which the hand starts.
+	Line int
+
+	// Text is the hand history text.
+	Text string
+}
+
+// Split splits a multi-hand history file into individual hands.
+// Hands are separated by one or more blank lines.
+func Split(r io.Reader) ([]Chunk, error) {
+
+	var chunks []Chunk
+	var lines []string
+	var start int
+
+	flush := func() {
+		if len(lines) > 0 {
+			chunks = append(chunks, Chunk{start, strings.Join(lines, "\n")})
+			lines = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if n == 1 {
+			// Strip UTF-8 byte order mark, which some clients write.
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+
+		if len(lines) == 0 {
+			start = n
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	return chunks, scanner.Err()
+}
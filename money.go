@@ -0,0 +1,417 @@
+package poker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Currency is a currency, or chips.
+type Currency struct {
+
+	// Code is the ISO 4217 code, e.g. 'EUR', or 'CHIPS' and 'PLAY' for
+	// tournament chips and play money.
+	Code string
+
+	// Symbol is written before amounts, e.g. '€', or after them if Suffix is
+	// set, e.g. 'chips'.
+	Symbol string
+
+	// Suffix is whether the symbol follows amounts, separated by a space.
+	Suffix bool
+
+	// Precision is the number of decimal places of the smallest unit, e.g. 2
+	// for cents.
+	Precision int
+}
+
+// Currencies
+var (
+	USD       = Currency{Code: "USD", Symbol: "$", Precision: 2}
+	EUR       = Currency{Code: "EUR", Symbol: "€", Precision: 2}
+	GBP       = Currency{Code: "GBP", Symbol: "£", Precision: 2}
+	Chips     = Currency{Code: "CHIPS", Symbol: "chips", Suffix: true}
+	PlayMoney = Currency{Code: "PLAY", Symbol: "play chips", Suffix: true}
+)
+
+// currencies are the known currencies, by which amounts are parsed.
+// Play money comes before chips, whose symbol ends its own.
+var currencies = []Currency{USD, EUR, GBP, PlayMoney, Chips}
+
+// LookupCurrency returns a known currency by its code or symbol, e.g. 'EUR' or
+// '€'.
+func LookupCurrency(str string) (Currency, bool) {
+	for _, c := range currencies {
+		if strings.EqualFold(str, c.Code) || str == c.Symbol {
+			return c, true
+		}
+	}
+	return Currency{}, false
+}
+
+// WithPrecision returns the currency with a number of decimal places, e.g. 4
+// for tables with stakes in hundredths of a cent.
+func (c Currency) WithPrecision(precision int) Currency {
+	c.Precision = precision
+	return c
+}
+
+// String returns the code of the currency.
+func (c Currency) String() string {
+	return c.Code
+}
+
+// scale returns 10 to the power of the precision, i.e. the number of units in
+// one.
+func (c Currency) scale() int64 {
+	s := int64(1)
+	for i := 0; i < c.Precision; i++ {
+		s *= 10
+	}
+	return s
+}
+
+// CurrencyError is returned by arithmetic between amounts of different
+// currencies.
+type CurrencyError struct {
+	A, B Currency
+}
+
+// Error returns the string representation of the error.
+func (e *CurrencyError) Error() string {
+	return fmt.Sprintf("currencies differ: %v and %v", e.A, e.B)
+}
+
+// Money is an amount of a currency, in its smallest unit. Unlike Amount, its
+// precision may be finer than hundredths; see Amount for why hands are not.
+type Money struct {
+
+	// Units is the amount in units of the currency's precision, e.g. cents.
+	Units int64
+
+	// Currency is the currency.
+	Currency Currency
+}
+
+// NewMoney creates an amount of units of a currency.
+func NewMoney(units int64, c Currency) Money {
+	return Money{Units: units, Currency: c}
+}
+
+// Money returns the amount in a currency with a precision of 2, such as US
+// cents.
+func (a Amount) Money(c Currency) Money {
+	return NewMoney(int64(a), c.WithPrecision(2)).Rescale(c.Precision)
+}
+
+// Amount returns the amount in units of a precision of 2, such as cents.
+// Finer units are rounded.
+func (m Money) Amount() Amount {
+	return Amount(m.Rescale(2).Units)
+}
+
+// Rescale returns the amount with another precision. Finer units are rounded
+// half away from zero.
+func (m Money) Rescale(precision int) Money {
+	c := m.Currency.WithPrecision(precision)
+	units := m.Units
+	for p := m.Currency.Precision; p < precision; p++ {
+		units *= 10
+	}
+	if p := m.Currency.Precision - precision; p > 0 {
+		d := m.Currency.WithPrecision(p).scale()
+		half := d / 2
+		if units < 0 {
+			half = -half
+		}
+		units = (units + half) / d
+	}
+	return NewMoney(units, c)
+}
+
+// align returns two amounts with the finer of their precisions, or an error if
+// they are in different currencies.
+func align(a, b Money) (Money, Money, error) {
+	if a.Currency.Code != b.Currency.Code {
+		return a, b, &CurrencyError{a.Currency, b.Currency}
+	}
+	if a.Currency.Precision < b.Currency.Precision {
+		a = a.Rescale(b.Currency.Precision)
+	} else {
+		b = b.Rescale(a.Currency.Precision)
+	}
+	return a, b, nil
+}
+
+// Add returns the sum of two amounts of the same currency.
+func (m Money) Add(o Money) (Money, error) {
+	a, b, err := align(m, o)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(a.Units+b.Units, a.Currency), nil
+}
+
+// Sub returns the difference of two amounts of the same currency.
+func (m Money) Sub(o Money) (Money, error) {
+	a, b, err := align(m, o)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(a.Units-b.Units, a.Currency), nil
+}
+
+// Cmp compares two amounts of the same currency. It returns -1, 0 or 1 if the
+// amount is less than, equal to or greater than the other.
+func (m Money) Cmp(o Money) (int, error) {
+	a, b, err := align(m, o)
+	switch {
+	case err != nil:
+		return 0, err
+	case a.Units < b.Units:
+		return -1, nil
+	case a.Units > b.Units:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Mul returns the amount multiplied by a number.
+func (m Money) Mul(n int64) Money {
+	return NewMoney(m.Units*n, m.Currency)
+}
+
+// IsZero returns whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.Units == 0
+}
+
+// String returns the amount with every decimal place, e.g. '$2.50',
+// '€0.0025' or '1500 chips'.
+func (m Money) String() string {
+	return m.format(false)
+}
+
+// Short returns the amount without decimal places if it is whole, as written
+// in hand histories, e.g. '$2' or '$2.50'.
+func (m Money) Short() string {
+	return m.format(true)
+}
+
+// format formats the amount, without decimal places if it is whole and short
+// is set.
+func (m Money) format(short bool) string {
+
+	c := m.Currency
+	units := m.Units
+	sign := ""
+	if units < 0 {
+		sign, units = "-", -units
+	}
+
+	scale := c.scale()
+	number := fmt.Sprint(units / scale)
+	if c.Precision > 0 && !(short && units%scale == 0) {
+		number += fmt.Sprintf(".%0*d", c.Precision, units%scale)
+	}
+
+	if c.Suffix {
+		return sign + number + " " + c.Symbol
+	}
+	return sign + c.Symbol + number
+}
+
+// ParseMode is how strictly amounts are parsed.
+type ParseMode int
+
+// Parse modes
+const (
+	// Strict rejects misplaced digit grouping and decimal places finer than
+	// the precision of the currency.
+	Strict ParseMode = iota
+
+	// Lenient ignores digit grouping and rounds finer decimal places half away
+	// from zero.
+	Lenient
+)
+
+// multipliers are the suffixes of abbreviated amounts, e.g. '1.5k', by their
+// number of zeros.
+var multipliers = map[byte]int{'k': 3, 'K': 3, 'm': 6, 'M': 6}
+
+// ParseMoney parses an amount of a currency strictly, e.g. '$2.50', '€1.234,56'
+// or '2M chips'. If the amount has a currency symbol or code, it must be the
+// currency's. A zero currency is taken from the symbol or code instead.
+func ParseMoney(str string, c Currency) (Money, error) {
+	return ParseMoneyMode(str, c, Strict)
+}
+
+// ParseMoneyMode parses an amount of a currency like ParseMoney, in a parse
+// mode.
+//
+// Either '.' or ',' may separate decimals. If both are used, the last one
+// does, and a single ',' followed by three digits groups them. Digits may also
+// be grouped by spaces or apostrophes.
+func ParseMoneyMode(str string, c Currency, mode ParseMode) (Money, error) {
+
+	sign, s, found, err := splitCurrency(str)
+	switch {
+	case err != nil:
+		return Money{}, err
+	case c.Code == "" && found.Code == "":
+		return Money{}, &AmountError{str, "unknown currency"}
+	case c.Code == "":
+		c = found
+	case found.Code != "" && found.Code != c.Code:
+		return Money{}, &AmountError{str, fmt.Sprintf("expected %v", c)}
+	}
+
+	units, reason := parseUnits(s, c.Precision, mode)
+	if reason != "" {
+		return Money{}, &AmountError{str, reason}
+	}
+	return NewMoney(sign*units, c), nil
+}
+
+// splitCurrency splits an amount into its sign, its number and the currency of
+// its symbol or code, if any. The currency may be written before or after the
+// number.
+func splitCurrency(str string) (int64, string, Currency, error) {
+
+	s := strings.TrimSpace(str)
+	sign := int64(1)
+	var found Currency
+	for _, k := range currencies {
+		for _, mark := range []string{k.Symbol, k.Code} {
+			if strings.HasPrefix(s, "-") {
+				sign, s = -1, strings.TrimSpace(s[1:])
+			}
+			switch {
+			case strings.HasPrefix(s, mark):
+				s = s[len(mark):]
+			case strings.HasSuffix(strings.ToUpper(s), strings.ToUpper(mark)):
+				s = s[:len(s)-len(mark)]
+			default:
+				continue
+			}
+			if found.Code != "" && found.Code != k.Code {
+				return 0, "", Currency{}, &AmountError{str,
+					fmt.Sprintf("%v and %v", found, k)}
+			}
+			s, found = strings.TrimSpace(s), k
+		}
+	}
+	if strings.HasPrefix(s, "-") {
+		sign, s = -1, strings.TrimSpace(s[1:])
+	}
+	return sign, s, found, nil
+}
+
+// parseUnits parses a decimal number into units of a precision, without
+// going through floating point. It returns why the number is invalid, if it
+// is.
+func parseUnits(s string, precision int, mode ParseMode) (int64, string) {
+
+	// Abbreviated amounts have more decimal places.
+	if n := len(s); n > 0 {
+		if zeros, ok := multipliers[s[n-1]]; ok {
+			s, precision = strings.TrimSpace(s[:n-1]), precision+zeros
+		}
+	}
+
+	// The decimal separator is the last of '.' and ',', unless there is only
+	// one kind and it is repeated, or it is a single ',' grouping thousands.
+	dot, comma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	point := -1
+	switch {
+	case dot >= 0 && comma >= 0:
+		point = dot
+		if comma > dot {
+			point = comma
+		}
+	case dot >= 0 && strings.Count(s, ".") == 1:
+		point = dot
+	case comma >= 0 && strings.Count(s, ",") == 1 && len(s)-comma-1 != 3:
+		point = comma
+	}
+	whole, frac := s, ""
+	if point >= 0 {
+		whole, frac = s[:point], s[point+1:]
+		if strings.IndexByte(whole, s[point]) >= 0 {
+			return 0, "repeated decimal separator"
+		}
+	}
+	if whole == "" && frac == "" {
+		return 0, "no digits"
+	}
+
+	groups := strings.FieldsFunc(whole, func(r rune) bool {
+		return r == '.' || r == ',' || r == ' ' || r == '\'' || r == '\u00a0'
+	})
+	if mode == Strict && len(groups) > 1 {
+		for i := 0; i < len(groups); i++ {
+			if n := len(groups[i]); n > 3 || n < 3 && i > 0 || n == 0 {
+				return 0, "misplaced digit grouping"
+			}
+		}
+	}
+	whole = strings.Join(groups, "")
+
+	// Finer decimal places are rounded by their first digit.
+	round := false
+	if len(frac) > precision {
+		if mode == Strict && strings.Trim(frac[precision:], "0") != "" {
+			return 0, fmt.Sprintf("more than %v decimal places", precision)
+		}
+		round = frac[precision] >= '5'
+		for _, r := range frac[precision:] {
+			if r < '0' || r > '9' {
+				return 0, fmt.Sprintf("invalid digit %q", r)
+			}
+		}
+		frac = frac[:precision]
+	}
+	frac += strings.Repeat("0", precision-len(frac))
+
+	var units int64
+	for _, r := range whole + frac {
+		if r < '0' || r > '9' {
+			return 0, fmt.Sprintf("invalid digit %q", r)
+		}
+		if units > (1<<63-1-9)/10 {
+			return 0, "out of range"
+		}
+		units = units*10 + int64(r-'0')
+	}
+	if round {
+		units++
+	}
+	return units, ""
+}
+
+// MarshalJSON marshals the string representation of the amount.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON parses an amount from JSON, in the currency of its symbol or
+// code.
+func (m *Money) UnmarshalJSON(data []byte) error {
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	// The precision follows the decimal places written.
+	_, number, c, err := splitCurrency(str)
+	if err != nil {
+		return err
+	}
+	if i := strings.Index(number, "."); i >= 0 && len(number)-i-1 > c.Precision {
+		c.Precision = len(number) - i - 1
+	}
+	*m, err = ParseMoney(str, c)
+	return err
+}
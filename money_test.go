@@ -0,0 +1,229 @@
+package poker
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// String() ////////////////////////////////////////////////////////////////////
+
+func TestMoneyString(t *testing.T) {
+
+	type testPair struct {
+		money         Money
+		output, short string
+	}
+	tests := []testPair{
+		{NewMoney(250, USD), "$2.50", "$2.50"},
+		{NewMoney(200, EUR), "€2.00", "€2"},
+		{NewMoney(-5, GBP), "-£0.05", "-£0.05"},
+		{NewMoney(25, USD.WithPrecision(4)), "$0.0025", "$0.0025"},
+		{NewMoney(1500, Chips), "1500 chips", "1500 chips"},
+		{NewMoney(20, PlayMoney), "20 play chips", "20 play chips"},
+	}
+
+	for _, test := range tests {
+		if s := test.money.String(); s != test.output {
+			t.Errorf("For %+v expected %v, got %v", test.money, test.output, s)
+		}
+		if s := test.money.Short(); s != test.short {
+			t.Errorf("For %+v expected %v, got %v", test.money, test.short, s)
+		}
+	}
+}
+
+// ParseMoney() ////////////////////////////////////////////////////////////////
+
+func TestParseMoney(t *testing.T) {
+
+	type testPair struct {
+		input    string
+		currency Currency
+		output   Money
+	}
+	tests := []testPair{
+		{"$2.50", Currency{}, NewMoney(250, USD)},
+		{"€0.05 EUR", Currency{}, NewMoney(5, EUR)},
+		{"-£1", Currency{}, NewMoney(-100, GBP)},
+		{"1,500 chips", Currency{}, NewMoney(1500, Chips)},
+		{"20 play chips", Currency{}, NewMoney(20, PlayMoney)},
+		{"0.0025", USD.WithPrecision(4), NewMoney(25, USD.WithPrecision(4))},
+		{"$0.0025", USD.WithPrecision(4), NewMoney(25, USD.WithPrecision(4))},
+		{"3", EUR, NewMoney(300, EUR)},
+	}
+
+	for _, test := range tests {
+		m, err := ParseMoney(test.input, test.currency)
+		if err != nil || m != test.output {
+			t.Errorf("For %q expected %+v, got %+v (%v)", test.input, test.output, m, err)
+		}
+	}
+
+	invalid := []struct {
+		input    string
+		currency Currency
+	}{
+		{"2.50", Currency{}},
+		{"$0.0025", USD},
+		{"€2", USD},
+		{"$2 EUR", Currency{}},
+		{"$2.5x", Currency{}},
+		{"", USD},
+	}
+	for _, test := range invalid {
+		if m, err := ParseMoney(test.input, test.currency); err == nil {
+			t.Errorf("For %q expected error, got %+v", test.input, m)
+		}
+	}
+}
+
+func TestParseMoneyMode(t *testing.T) {
+
+	type testPair struct {
+		input           string
+		strict, lenient int64
+	}
+	tests := []testPair{
+		{"€1.234,56", 123456, 123456},
+		{"$1,234,567", 123456700, 123456700},
+		{"1 234,50 EUR", 123450, 123450},
+		{"$1.5k", 150000, 150000},
+		{"$2.005", -1, 201},
+		{"-$2.005", -1, -201},
+		{"$12,34,567", -1, 123456700},
+		{"$1234,567.00", -1, 123456700},
+	}
+
+	for _, test := range tests {
+		for _, mode := range []ParseMode{Strict, Lenient} {
+			expected := test.strict
+			if mode == Lenient {
+				expected = test.lenient
+			}
+			m, err := ParseMoneyMode(test.input, Currency{}, mode)
+			switch {
+			case expected == -1 && err == nil:
+				t.Errorf("For %q in mode %v expected error, got %v", test.input, mode, m)
+			case expected == -1:
+				if e, ok := err.(*AmountError); !ok || e.Input != test.input {
+					t.Errorf("For %q expected an amount error, got %#v", test.input, err)
+				}
+			case err != nil || m.Units != expected:
+				t.Errorf("For %q in mode %v expected %v, got %v (%v)", test.input, mode,
+					expected, m.Units, err)
+			}
+		}
+	}
+}
+
+// Add() ///////////////////////////////////////////////////////////////////////
+
+func TestMoneyArithmetic(t *testing.T) {
+
+	sum, err := NewMoney(250, USD).Add(NewMoney(25, USD.WithPrecision(4)))
+	if err != nil || sum.String() != "$2.5025" {
+		t.Errorf("Expected $2.5025, got %v (%v)", sum, err)
+	}
+	diff, err := NewMoney(100, EUR).Sub(NewMoney(150, EUR))
+	if err != nil || diff != NewMoney(-50, EUR) {
+		t.Errorf("Expected -€0.50, got %v (%v)", diff, err)
+	}
+	if c, err := NewMoney(1, USD).Cmp(NewMoney(99, USD.WithPrecision(4))); err != nil || c != 1 {
+		t.Errorf("Expected 1, got %v (%v)", c, err)
+	}
+	if m := NewMoney(5, GBP).Mul(3); m != NewMoney(15, GBP) {
+		t.Errorf("Expected £0.15, got %v", m)
+	}
+
+	_, err = NewMoney(100, USD).Add(NewMoney(100, EUR))
+	if _, ok := err.(*CurrencyError); !ok {
+		t.Errorf("Expected a currency error, got %v", err)
+	}
+	if _, err := NewMoney(100, Chips).Cmp(NewMoney(100, PlayMoney)); err == nil {
+		t.Errorf("Expected error comparing chips and play money")
+	}
+}
+
+// Rescale() ///////////////////////////////////////////////////////////////////
+
+func TestMoneyRescale(t *testing.T) {
+
+	type testPair struct {
+		money     Money
+		precision int
+		output    int64
+	}
+	tests := []testPair{
+		{NewMoney(25, USD.WithPrecision(4)), 2, 0},
+		{NewMoney(50, USD.WithPrecision(4)), 2, 1},
+		{NewMoney(-50, USD.WithPrecision(4)), 2, -1},
+		{NewMoney(3, USD), 4, 300},
+	}
+	for _, test := range tests {
+		if m := test.money.Rescale(test.precision); m.Units != test.output {
+			t.Errorf("For %v expected %v units, got %v", test.money, test.output, m.Units)
+		}
+	}
+
+	if a := NewMoney(12345, USD.WithPrecision(4)).Amount(); a != 123 {
+		t.Errorf("Expected 123, got %v", int(a))
+	}
+	if m := Amount(150).Money(Chips); m != NewMoney(2, Chips) {
+		t.Errorf("Expected 2 chips, got %v", m)
+	}
+}
+
+// MarshalJSON() ///////////////////////////////////////////////////////////////
+
+func TestMoneyJSON(t *testing.T) {
+
+	for _, m := range []Money{
+		NewMoney(250, USD),
+		NewMoney(-5, EUR),
+		NewMoney(25, USD.WithPrecision(4)),
+		NewMoney(1500, Chips),
+	} {
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var again Money
+		if err := json.Unmarshal(data, &again); err != nil || again != m {
+			t.Errorf("For %s expected %+v, got %+v (%v)", data, m, again, err)
+		}
+	}
+}
+
+// ParseStakes() ///////////////////////////////////////////////////////////////
+
+func TestStakesCurrency(t *testing.T) {
+
+	type testPair struct {
+		input  string
+		output Stakes
+		str    string
+	}
+	tests := []testPair{
+		{"$0.01/$0.02 USD", Stakes{SmallBlind: 1, BigBlind: 2}, "$0.01/$0.02 USD"},
+		{"$1/$2", Stakes{SmallBlind: 100, BigBlind: 200}, "$1/$2 USD"},
+		{"€0.05/€0.10 EUR", Stakes{SmallBlind: 5, BigBlind: 10, Currency: EUR}, "€0.05/€0.10 EUR"},
+		{"10/20 play chips", Stakes{SmallBlind: 1000, BigBlind: 2000, Currency: PlayMoney},
+			"10/20 play chips"},
+	}
+
+	for _, test := range tests {
+		s, err := ParseStakes(test.input)
+		if err != nil || s != test.output {
+			t.Errorf("For %q expected %+v, got %+v (%v)", test.input, test.output, s, err)
+		}
+		if s.String() != test.str {
+			t.Errorf("For %q expected %v, got %v", test.input, test.str, s)
+		}
+	}
+
+	for _, str := range []string{"$0.01", "$0.01/€0.02"} {
+		if _, err := ParseStakes(str); err == nil {
+			t.Errorf("For %q expected error", str)
+		}
+	}
+}
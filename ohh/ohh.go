@@ -0,0 +1,887 @@
+// Package ohh converts hands to and from the Open Hand History (OHH) JSON
+// format.
+//
+// Fields which are not modelled by poker.Hand are kept in Hand.Extensions, so
+// that a hand survives a round trip through other software.
+package ohh
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	poker "github.com/whomever000/poker-common"
+	"github.com/whomever000/poker-common/card"
+)
+
+// SpecVersion is the version of the OHH specification which is written.
+const SpecVersion = "1.4.6"
+
+// Street names
+const (
+	preflop  = "Preflop"
+	flop     = "Flop"
+	turn     = "Turn"
+	river    = "River"
+	showdown = "Showdown"
+)
+
+var streets = []string{preflop, flop, turn, river}
+
+// Action names
+const (
+	dealtCards   = "Dealt Cards"
+	showsCards   = "Shows Cards"
+	mucksCards   = "Mucks Cards"
+	postAnte     = "Post Ante"
+	postSB       = "Post SB"
+	postBB       = "Post BB"
+	postDead     = "Post Dead"
+	postStraddle = "Post Straddle"
+	fold         = "Fold"
+	check        = "Check"
+	call         = "Call"
+	bet          = "Bet"
+	raise        = "Raise"
+)
+
+// extension is the prefix of keys in Hand.Extensions.
+const extension = "ohh"
+
+// object is a JSON object. Fields are removed as they are decoded, so that the
+// remaining fields are the unknown ones.
+type object map[string]json.RawMessage
+
+// take decodes and removes a field. A missing field is ignored.
+func (o object) take(key string, v interface{}) error {
+	raw, ok := o[key]
+	if !ok {
+		return nil
+	}
+	delete(o, key)
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("ohh: field %v: %v", key, err)
+	}
+	return nil
+}
+
+// put encodes a field.
+func (o object) put(key string, v interface{}) {
+	raw, err := json.Marshal(v)
+	if err == nil {
+		o[key] = raw
+	}
+}
+
+// keep saves the remaining unknown fields of an object in the hand.
+func keep(h *poker.Hand, path string, o object) {
+	if len(o) == 0 {
+		return
+	}
+	if h.Extensions == nil {
+		h.Extensions = make(map[string]json.RawMessage)
+	}
+	raw, _ := json.Marshal(o)
+	h.Extensions[path] = raw
+}
+
+// restore adds the saved unknown fields of an object, without replacing known
+// fields.
+func restore(h *poker.Hand, path string, o object) {
+	var saved object
+	if json.Unmarshal(h.Extensions[path], &saved) != nil {
+		return
+	}
+	for k, v := range saved {
+		if _, ok := o[k]; !ok {
+			o[k] = v
+		}
+	}
+}
+
+// amount converts an amount of a hand to OHH's decimal representation in a
+// unit. Amounts in big blinds are rounded to two decimal places.
+func amount(h *poker.Hand, u poker.Unit, a poker.Amount) float64 {
+	if u == poker.BigBlinds {
+		return math.Round(h.BigBlinds(a)*100) / 100
+	}
+	return float64(a) / 100
+}
+
+// Parse parses a single OHH hand. It can be used as an importer.Parser.
+func Parse(text string) (*poker.Hand, error) {
+	return Unmarshal([]byte(text))
+}
+
+// ReadAll reads all hands from a stream of OHH objects, e.g. a file in which
+// hands are separated by blank lines.
+func ReadAll(r io.Reader) ([]*poker.Hand, error) {
+	var hands []*poker.Hand
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err == io.EOF {
+			return hands, nil
+		}
+		if err != nil {
+			return hands, err
+		}
+		h, err := Unmarshal(raw)
+		if err != nil {
+			return hands, err
+		}
+		hands = append(hands, h)
+	}
+}
+
+// Unmarshal converts an OHH hand to a hand.
+func Unmarshal(data []byte) (*poker.Hand, error) {
+
+	var doc struct {
+		OHH object `json:"ohh"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("ohh: %v", err)
+	}
+	if doc.OHH == nil {
+		return nil, fmt.Errorf("ohh: missing ohh object")
+	}
+
+	h := &poker.Hand{}
+	o := doc.OHH
+
+	var version, gameNumber, startDate, gameType string
+	var betLimit, tournamentInfo object
+	var tournament bool
+	var sb, bb, ante float64
+	var players, rounds, pots []object
+
+	err := firstError(
+		o.take("spec_version", &version),
+		o.take("site_name", &h.Client),
+		o.take("game_number", &gameNumber),
+		o.take("start_date_utc", &startDate),
+		o.take("table_name", &h.Table.Name),
+		o.take("tournament", &tournament),
+		o.take("tournament_info", &tournamentInfo),
+		o.take("game_type", &gameType),
+		o.take("bet_limit", &betLimit),
+		o.take("table_size", &h.Table.Size),
+		o.take("dealer_seat", &h.Button),
+		o.take("small_blind_amount", &sb),
+		o.take("big_blind_amount", &bb),
+		o.take("ante_amount", &ante),
+		o.take("players", &players),
+		o.take("rounds", &rounds),
+		o.take("pots", &pots),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if gameNumber != "" {
+		if h.HandID, err = strconv.Atoi(gameNumber); err != nil {
+			return nil, fmt.Errorf("ohh: invalid game number %v", gameNumber)
+		}
+	}
+	if startDate != "" {
+		t, err := time.Parse(time.RFC3339, startDate)
+		if err != nil {
+			return nil, fmt.Errorf("ohh: invalid start date %v", startDate)
+		}
+		h.Date = poker.Date(t.UTC())
+	}
+
+	var betType string
+	if err := betLimit.take("bet_type", &betType); err != nil {
+		return nil, err
+	}
+	keep(h, extension+".bet_limit", betLimit)
+	if gameType != "Holdem" || betType != "NL" {
+		return nil, fmt.Errorf("ohh: unsupported game %v %v", betType, gameType)
+	}
+	h.Table.Game = poker.TexasHoldEmNoLimit
+	h.Table.Stakes = poker.Stakes{
+		SmallBlind: poker.NewAmount(sb),
+		BigBlind:   poker.NewAmount(bb),
+		Ante:       poker.NewAmount(ante),
+	}
+
+	if tournament {
+		if h.Tournament, err = unmarshalTournament(tournamentInfo); err != nil {
+			return nil, err
+		}
+		keep(h, extension+".tournament_info", tournamentInfo)
+	}
+
+	// Players are identified by id, which is mapped to their seat.
+	seats := make(map[int]poker.PlayerPosition)
+	for _, p := range players {
+		var id, seat int
+		var name string
+		var stack float64
+		var sittingOut bool
+		err := firstError(
+			p.take("id", &id),
+			p.take("seat", &seat),
+			p.take("name", &name),
+			p.take("starting_stack", &stack),
+			p.take("is_sitting_out", &sittingOut),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if seat < 1 {
+			return nil, fmt.Errorf("ohh: player %v has invalid seat %v", id, seat)
+		}
+		player := poker.Player{Name: name, Stack: poker.NewAmount(stack)}
+		if sittingOut {
+			player.Status = poker.SittingOut
+		}
+		h.Sit(poker.PlayerPosition(seat), player)
+		seats[id] = poker.PlayerPosition(seat)
+		if id != seat {
+			p.put("id", id)
+		}
+		keep(h, fmt.Sprintf("%v.players.%v", extension, seat), p)
+	}
+
+	var heroID *int
+	if err := o.take("hero_player_id", &heroID); err != nil {
+		return nil, err
+	}
+	var hero poker.PlayerPosition
+	if heroID != nil {
+		pos, ok := seats[*heroID]
+		if !ok {
+			return nil, fmt.Errorf("ohh: unknown player id %v", *heroID)
+		}
+		hero = pos
+		h.ThisPlayer = &poker.PlayerCards{Position: hero}
+	}
+
+	if err := unmarshalRounds(h, rounds, seats, hero); err != nil {
+		return nil, err
+	}
+
+	if err := unmarshalPots(h, pots, seats); err != nil {
+		return nil, err
+	}
+
+	keep(h, extension, o)
+	return h, nil
+}
+
+// unmarshalTournament converts OHH tournament info.
+func unmarshalTournament(info object) (*poker.Tournament, error) {
+	var number int
+	var buyIn, fee, bounty float64
+	err := firstError(
+		info.take("tournament_number", &number),
+		info.take("buyin_amount", &buyIn),
+		info.take("fee_amount", &fee),
+		info.take("bounty_fee_amount", &bounty),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &poker.Tournament{
+		ID:     number,
+		BuyIn:  poker.NewAmount(buyIn),
+		Fee:    poker.NewAmount(fee),
+		Bounty: poker.NewAmount(bounty),
+	}, nil
+}
+
+// unmarshalRounds converts OHH rounds.
+func unmarshalRounds(h *poker.Hand, rounds []object, seats map[int]poker.PlayerPosition,
+	hero poker.PlayerPosition) error {
+
+	var board []card.Card
+	count := make(map[actionKey]int)
+
+	// The players who post an ante, and the largest ante posted.
+	var antes []poker.PlayerPosition
+	var posted poker.Amount
+
+	for _, r := range rounds {
+		var street string
+		var cards []string
+		var actions []object
+		err := firstError(
+			r.take("id", new(int)),
+			r.take("street", &street),
+			r.take("cards", &cards),
+			r.take("actions", &actions),
+		)
+		if err != nil {
+			return err
+		}
+
+		dealt, err := card.ParseCards(strings.Join(cards, " "))
+		if err != nil {
+			return fmt.Errorf("ohh: %v", err)
+		}
+		board = append(append([]card.Card(nil), board...), dealt...)
+
+		var round *poker.Round
+		if street != showdown {
+			h.Rounds = append(h.Rounds, poker.Round{Cards: board})
+			round = &h.Rounds[len(h.Rounds)-1]
+			if street == preflop {
+				round.Cards = nil
+			}
+		}
+		keep(h, fmt.Sprintf("%v.rounds.%v", extension, street), r)
+
+		for _, a := range actions {
+			var number, playerID int
+			var name string
+			var value float64
+			var shown []string
+			err := firstError(
+				a.take("action_number", &number),
+				a.take("player_id", &playerID),
+				a.take("action", &name),
+				a.take("amount", &value),
+				a.take("is_allin", new(bool)),
+				a.take("cards", &shown),
+			)
+			if err != nil {
+				return err
+			}
+			pos, ok := seats[playerID]
+			if !ok {
+				return fmt.Errorf("ohh: unknown player id %v", playerID)
+			}
+			amount := poker.NewAmount(value)
+			keep(h, actionPath(count, street, pos, name), a)
+
+			var action poker.Action
+			switch name {
+			case dealtCards, showsCards, mucksCards:
+				c, err := card.ParseCards(strings.Join(shown, " "))
+				if err != nil {
+					return fmt.Errorf("ohh: %v", err)
+				}
+				pc := poker.PlayerCards{Position: pos, Cards: c}
+				switch name {
+				case dealtCards:
+					if pos == hero || hero == 0 {
+						h.ThisPlayer = &pc
+					}
+				case showsCards:
+					result(h).ShowDowns = append(result(h).ShowDowns, pc)
+				default:
+					result(h).Mucked = append(result(h).Mucked, pc)
+				}
+				continue
+			case postAnte:
+				antes = append(antes, pos)
+				if amount > posted {
+					posted = amount
+				}
+				continue
+			case postStraddle:
+				if amount > h.Table.Stakes.Straddle {
+					h.Table.Stakes.Straddle = amount
+				}
+				continue
+			case postSB:
+				h.SmallBlind = pos
+				continue
+			case postBB:
+				h.BigBlind = pos
+				continue
+			case postDead:
+				if p := pos.Player(h); p != nil {
+					p.Status = poker.DeadBlind
+				}
+				continue
+			case fold:
+				action = poker.NewFoldAction()
+			case check:
+				action = poker.NewCheckAction()
+			case call:
+				action = poker.NewCallAction(amount)
+			case bet:
+				action = poker.NewBetAction(amount)
+			case raise:
+				action = poker.NewRaiseAction(amount)
+			default:
+				return fmt.Errorf("ohh: unsupported action %v", name)
+			}
+			if round == nil {
+				return fmt.Errorf("ohh: %v action during showdown", name)
+			}
+			round.Actions = append(round.Actions, poker.PlayerAction{
+				Position: pos,
+				Action:   action,
+			})
+		}
+	}
+
+	// An ante posted by the big blind alone is a big blind ante.
+	if len(antes) == 1 && antes[0] == h.BigBlind && len(h.Seats()) > 1 {
+		ante := h.Table.Stakes.Ante
+		if posted > ante {
+			ante = posted
+		}
+		h.Table.Stakes.BigBlindAnte, h.Table.Stakes.Ante = ante, 0
+	}
+
+	// Record the pot after each round.
+	s := poker.NewTableState(h)
+	for r := 0; r < len(h.Rounds); r++ {
+		if r > 0 {
+			s.NextRound()
+		}
+		for _, pa := range h.Rounds[r].Actions {
+			s.Apply(pa)
+		}
+		_, uncalled := s.Uncalled()
+		h.Rounds[r].Pot = s.Pot - uncalled
+	}
+
+	return nil
+}
+
+// unmarshalPots converts OHH pots.
+func unmarshalPots(h *poker.Hand, pots []object, seats map[int]poker.PlayerPosition) error {
+
+	if len(pots) == 0 {
+		return nil
+	}
+
+	derived, _ := poker.SidePots(h)
+	r := result(h)
+	r.Pots = make([]poker.Pot, len(pots))
+
+	for _, p := range pots {
+		var number int
+		var total, rake, jackpot float64
+		var wins []object
+		err := firstError(
+			p.take("number", &number),
+			p.take("amount", &total),
+			p.take("rake", &rake),
+			p.take("jackpot", &jackpot),
+			p.take("player_wins", &wins),
+		)
+		if err != nil {
+			return err
+		}
+		if number < 0 || number >= len(pots) {
+			return fmt.Errorf("ohh: invalid pot number %v", number)
+		}
+		keep(h, fmt.Sprintf("%v.pots.%v", extension, number), p)
+
+		pot := &r.Pots[number]
+		pot.Amount = poker.NewAmount(total) - poker.NewAmount(rake) - poker.NewAmount(jackpot)
+		r.Rake += poker.NewAmount(rake)
+		r.Jackpot += poker.NewAmount(jackpot)
+		if number < len(derived) {
+			pot.Eligible = derived[number].Eligible
+		}
+
+		for _, w := range wins {
+			var playerID int
+			var win float64
+			err := firstError(
+				w.take("player_id", &playerID),
+				w.take("win_amount", &win),
+			)
+			if err != nil {
+				return err
+			}
+			pos, ok := seats[playerID]
+			if !ok {
+				return fmt.Errorf("ohh: unknown player id %v", playerID)
+			}
+			keep(h, fmt.Sprintf("%v.pots.%v.player_wins.%v", extension, number, pos), w)
+			pot.Winners = append(pot.Winners, poker.Share{
+				Position: pos,
+				Amount:   poker.NewAmount(win),
+			})
+		}
+	}
+
+	return nil
+}
+
+// result returns the result of a hand, creating it if needed.
+func result(h *poker.Hand) *poker.Result {
+	if h.Result == nil {
+		h.Result = &poker.Result{}
+	}
+	return h.Result
+}
+
+// actionKey identifies the actions of a kind by a player on a street.
+type actionKey struct {
+	street string
+	pos    poker.PlayerPosition
+	name   string
+}
+
+// actionPath returns the path in Hand.Extensions of the next action of a kind
+// by a player on a street. Actions are identified by the street, the player's
+// seat, the action and how many such actions came before, rather than by their
+// action_number, which is not kept.
+func actionPath(count map[actionKey]int, street string, pos poker.PlayerPosition,
+	name string) string {
+
+	k := actionKey{street, pos, name}
+	n := count[k]
+	count[k]++
+	return fmt.Sprintf("%v.actions.%v.%v.%v.%v", extension, street, pos, name, n)
+}
+
+// playerIDs returns the OHH ids of the seated players: the ids they were read
+// with, which are kept if they differ from their seats. If the ids are not
+// distinct, the players are identified by their seats.
+func playerIDs(h *poker.Hand) map[poker.PlayerPosition]int {
+	ids := make(map[poker.PlayerPosition]int)
+	used := make(map[int]bool)
+	for _, pos := range h.Occupied() {
+		saved := struct {
+			ID int `json:"id"`
+		}{int(pos)}
+		json.Unmarshal(h.Extensions[fmt.Sprintf("%v.players.%v", extension, pos)], &saved)
+		if used[saved.ID] {
+			for _, pos := range h.Occupied() {
+				ids[pos] = int(pos)
+			}
+			return ids
+		}
+		ids[pos] = saved.ID
+		used[saved.ID] = true
+	}
+	return ids
+}
+
+// Marshal converts a hand to an OHH hand.
+func Marshal(h *poker.Hand) ([]byte, error) {
+	return MarshalIn(h, poker.Native)
+}
+
+// MarshalIn converts a hand to an OHH hand with amounts in a unit, except for
+// the tournament buy-in. Hands in big blinds are for comparing hands across
+// stakes, and are read back in big blinds rather than at their stakes.
+func MarshalIn(h *poker.Hand, u poker.Unit) ([]byte, error) {
+
+	if h.Table.Game == nil || h.Table.Game.Game() != poker.TexasHoldEmNoLimit {
+		return nil, fmt.Errorf("ohh: unsupported game %v", h.Table.Game)
+	}
+
+	o := object{}
+	o.put("spec_version", SpecVersion)
+	o.put("site_name", h.Client)
+	o.put("game_number", strconv.Itoa(h.HandID))
+	o.put("start_date_utc", time.Time(h.Date).UTC().Format(time.RFC3339))
+	o.put("table_name", h.Table.Name)
+	o.put("game_type", "Holdem")
+
+	if t := h.Tournament; t != nil {
+		info := object{}
+		info.put("tournament_number", t.ID)
+		info.put("buyin_amount", amount(h, poker.Native, t.BuyIn))
+		info.put("fee_amount", amount(h, poker.Native, t.Fee))
+		if t.Bounty > 0 {
+			info.put("bounty_fee_amount", amount(h, poker.Native, t.Bounty))
+		}
+		restore(h, extension+".tournament_info", info)
+		o.put("tournament", true)
+		o.put("tournament_info", info)
+	}
+
+	betLimit := object{}
+	betLimit.put("bet_type", "NL")
+	restore(h, extension+".bet_limit", betLimit)
+	o.put("bet_limit", betLimit)
+
+	o.put("table_size", h.Table.Size)
+	o.put("dealer_seat", h.Button)
+	o.put("small_blind_amount", amount(h, u, h.Table.Stakes.SmallBlind))
+	o.put("big_blind_amount", amount(h, u, h.Table.Stakes.BigBlind))
+	if h.Table.Stakes.Ante > 0 {
+		o.put("ante_amount", amount(h, u, h.Table.Stakes.Ante))
+	} else if h.Table.Stakes.BigBlindAnte > 0 {
+		o.put("ante_amount", amount(h, u, h.Table.Stakes.BigBlindAnte))
+	}
+	ids := playerIDs(h)
+	if h.ThisPlayer != nil {
+		o.put("hero_player_id", ids[h.ThisPlayer.Position])
+	}
+
+	var players []object
+	for _, pos := range h.Occupied() {
+		player := pos.Player(h)
+		p := object{}
+		p.put("id", ids[pos])
+		p.put("seat", pos)
+		p.put("name", player.Name)
+		p.put("starting_stack", amount(h, u, player.Stack))
+		if !player.IsDealtIn() {
+			p.put("is_sitting_out", true)
+		}
+		restore(h, fmt.Sprintf("%v.players.%v", extension, pos), p)
+		players = append(players, p)
+	}
+	o.put("players", players)
+
+	o.put("rounds", marshalRounds(h, u, ids))
+	o.put("pots", marshalPots(h, u, ids))
+
+	restore(h, extension, o)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(map[string]object{"ohh": o}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Write writes a hand as OHH, followed by a blank line which separates it from
+// the next hand.
+func Write(w io.Writer, h *poker.Hand) error {
+	data, err := Marshal(h)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// marshalRounds converts the betting rounds and showdown to OHH rounds.
+func marshalRounds(h *poker.Hand, u poker.Unit, ids map[poker.PlayerPosition]int) []object {
+
+	var rounds []object
+	var street string
+	number := 0
+	count := make(map[actionKey]int)
+
+	// The saved unknown fields are restored first, as the known fields
+	// replace them when they are added.
+	newAction := func(pos poker.PlayerPosition, name string) object {
+		number++
+		a := object{}
+		restore(h, actionPath(count, street, pos, name), a)
+		a.put("action_number", number)
+		a.put("player_id", ids[pos])
+		a.put("action", name)
+		return a
+	}
+
+	s := poker.NewTableState(h)
+	var prev []card.Card
+
+	for r := 0; r < len(h.Rounds) && r < len(streets); r++ {
+		street = streets[r]
+		round := object{}
+		round.put("id", r)
+		round.put("street", street)
+
+		cards := h.Rounds[r].Cards
+		var dealt []string
+		if len(cards) > len(prev) {
+			for _, c := range cards[len(prev):] {
+				dealt = append(dealt, c.String())
+			}
+			prev = cards
+		}
+		if r > 0 {
+			round.put("cards", dealt)
+			s.NextRound()
+		}
+
+		var actions []object
+		if r == 0 {
+			if h.ThisPlayer != nil && len(h.ThisPlayer.Cards) > 0 {
+				a := newAction(h.ThisPlayer.Position, dealtCards)
+				a.put("cards", cardStrings(h.ThisPlayer.Cards))
+				actions = append(actions, a)
+			}
+			for _, pos := range h.Seats() {
+				if h.Table.Stakes.Ante == 0 {
+					break
+				}
+				a := newAction(pos, postAnte)
+				a.put("amount", amount(h, u, ante(h, pos)))
+				a.put("is_allin", pos.Player(h).Stack <= h.Table.Stakes.Ante)
+				actions = append(actions, a)
+			}
+			if bbAnte := h.Table.Stakes.BigBlindAnte; bbAnte > 0 && s.Invested[h.BigBlind] > 0 {
+				stack := h.BigBlind.Player(h).Stack - ante(h, h.BigBlind)
+				a := newAction(h.BigBlind, postAnte)
+				if bbAnte > stack {
+					bbAnte = stack
+				}
+				a.put("amount", amount(h, u, bbAnte))
+				a.put("is_allin", stack <= h.Table.Stakes.BigBlindAnte)
+				actions = append(actions, a)
+			}
+			for _, blind := range []struct {
+				pos  poker.PlayerPosition
+				name string
+			}{{h.SmallBlind, postSB}, {h.BigBlind, postBB}} {
+				if blind.pos == 0 || s.Invested[blind.pos] == 0 {
+					continue
+				}
+				a := newAction(blind.pos, blind.name)
+				a.put("amount", amount(h, u, s.Contributions[blind.pos]))
+				a.put("is_allin", s.IsAllIn(blind.pos))
+				actions = append(actions, a)
+			}
+			if straddler := h.Straddler(); straddler != 0 {
+				a := newAction(straddler, postStraddle)
+				a.put("amount", amount(h, u, s.Contributions[straddler]))
+				a.put("is_allin", s.IsAllIn(straddler))
+				actions = append(actions, a)
+			}
+
+			// Dead blinds are posted as one action of both blinds.
+			for _, pos := range h.Seats() {
+				if pos.Player(h).Status != poker.DeadBlind || pos == h.SmallBlind ||
+					pos == h.BigBlind {
+					continue
+				}
+				a := newAction(pos, postDead)
+				a.put("amount", amount(h, u, pos.Player(h).Stack-s.Stacks[pos]-ante(h, pos)))
+				a.put("is_allin", s.IsAllIn(pos))
+				actions = append(actions, a)
+			}
+		}
+
+		for _, pa := range h.Rounds[r].Actions {
+			stack := s.Stacks[pa.Position]
+			s.Apply(pa)
+			put := stack - s.Stacks[pa.Position]
+
+			var a object
+			switch pa.Action.Type() {
+			case poker.Fold:
+				a = newAction(pa.Position, fold)
+			case poker.Check:
+				a = newAction(pa.Position, check)
+			case poker.Call:
+				a = newAction(pa.Position, call)
+				a.put("amount", amount(h, u, put))
+			case poker.Bet:
+				a = newAction(pa.Position, bet)
+				a.put("amount", amount(h, u, put))
+			case poker.Raise:
+				a = newAction(pa.Position, raise)
+				a.put("amount", amount(h, u, s.Contributions[pa.Position]))
+			}
+			if put > 0 {
+				a.put("is_allin", s.Stacks[pa.Position] == 0)
+			}
+			actions = append(actions, a)
+		}
+
+		round.put("actions", actions)
+		restore(h, fmt.Sprintf("%v.rounds.%v", extension, street), round)
+		rounds = append(rounds, round)
+	}
+
+	if h.Result != nil && (len(h.Result.ShowDowns) > 0 || len(h.Result.Mucked) > 0) {
+		street = showdown
+		round := object{}
+		round.put("id", len(rounds))
+		round.put("street", street)
+
+		var actions []object
+		for _, sd := range h.Result.ShowDowns {
+			a := newAction(sd.Position, showsCards)
+			a.put("cards", cardStrings(sd.Cards))
+			actions = append(actions, a)
+		}
+		for _, m := range h.Result.Mucked {
+			a := newAction(m.Position, mucksCards)
+			a.put("cards", cardStrings(m.Cards))
+			actions = append(actions, a)
+		}
+		round.put("actions", actions)
+		restore(h, fmt.Sprintf("%v.rounds.%v", extension, showdown), round)
+		rounds = append(rounds, round)
+	}
+
+	return rounds
+}
+
+// marshalPots converts the pots to OHH pots. The rake and jackpot are taken
+// from the main pot.
+func marshalPots(h *poker.Hand, u poker.Unit, ids map[poker.PlayerPosition]int) []object {
+
+	var pots []object
+	if h.Result == nil {
+		return pots
+	}
+
+	for i, pot := range h.Result.Pots {
+		var rake, jackpot poker.Amount
+		if i == 0 {
+			rake, jackpot = h.Result.Rake, h.Result.Jackpot
+		}
+
+		p := object{}
+		p.put("number", i)
+		p.put("amount", amount(h, u, pot.Amount+rake+jackpot))
+		p.put("rake", amount(h, u, rake))
+		if jackpot > 0 {
+			p.put("jackpot", amount(h, u, jackpot))
+		}
+
+		var wins []object
+		for _, w := range pot.Winners {
+			win := object{}
+			win.put("player_id", ids[w.Position])
+			win.put("win_amount", amount(h, u, w.Amount))
+			restore(h, fmt.Sprintf("%v.pots.%v.player_wins.%v", extension, i, w.Position),
+				win)
+			wins = append(wins, win)
+		}
+		p.put("player_wins", wins)
+		restore(h, fmt.Sprintf("%v.pots.%v", extension, i), p)
+		pots = append(pots, p)
+	}
+
+	return pots
+}
+
+// cardStrings returns the string representation of cards.
+func cardStrings(cards []card.Card) []string {
+	strs := make([]string, len(cards))
+	for i := 0; i < len(cards); i++ {
+		strs[i] = cards[i].String()
+	}
+	return strs
+}
+
+// firstError returns the first non-nil error.
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ante returns the ante posted by a player, who may be all in.
+func ante(h *poker.Hand, pos poker.PlayerPosition) poker.Amount {
+	stack := pos.Player(h).Stack
+	if h.Table.Stakes.Ante > stack {
+		return stack
+	}
+	return h.Table.Stakes.Ante
+}
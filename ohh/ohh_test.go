@@ -0,0 +1,295 @@
+package ohh
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	poker "github.com/whomever000/poker-common"
+)
+
+const testHistory = `PokerStars Hand #1001: Hold'em No Limit ($0.05/$0.10 USD) - 2016/03/04 20:15:42 UTC
+Table 'Alcyone' 6-max Seat #4 is the button
+Seat 1: dave ($3 in chips)
+Seat 2: erin ($10 in chips)
+Seat 4: frank ($6.50 in chips)
+Seat 6: grace ($10 in chips)
+grace: posts small blind $0.05
+dave: posts big blind $0.10
+*** HOLE CARDS ***
+Dealt to erin [Qh Qd]
+erin: raises $0.20 to $0.30
+frank: calls $0.30
+grace: folds
+dave: calls $0.20
+*** FLOP *** [2c 7d Kh]
+dave: bets $2.70 and is all-in
+erin: raises $4 to $6.70
+frank: calls $6.20 and is all-in
+Uncalled bet ($0.50) returned to erin
+*** TURN *** [2c 7d Kh] [3s]
+*** RIVER *** [2c 7d Kh 3s] [9c]
+*** SHOW DOWN ***
+erin: shows [Qh Qd] (a pair of Queens)
+frank: shows [Kd Js] (a pair of Kings)
+dave: mucks hand
+frank collected $6.80 from side pot-1
+frank collected $8.70 from main pot
+*** SUMMARY ***
+Total pot $16.05 Main pot $8.70. Side pot-1 $6.80. | Rake $0.55
+Board [2c 7d Kh 3s 9c]
+Seat 1: dave (big blind) mucked [Ac 4c]
+Seat 2: erin showed [Qh Qd] and lost with a pair of Queens
+Seat 4: frank (button) showed [Kd Js] and won ($15.50) with a pair of Kings
+Seat 6: grace (small blind) folded before Flop
+`
+
+const testSittingOutHistory = `PokerStars Hand #1003: Hold'em No Limit ($0.05/$0.10 USD) - 2016/03/04 20:18:03 UTC
+Table 'Alcyone' 6-max Seat #1 is the button
+Seat 1: dave ($10 in chips)
+Seat 2: erin ($10 in chips)
+Seat 3: frank ($10 in chips) is sitting out
+Seat 4: grace ($10 in chips)
+Seat 5: heidi ($10 in chips)
+erin: posts small blind $0.05
+grace: posts big blind $0.10
+heidi: posts small & big blinds $0.15
+*** HOLE CARDS ***
+heidi: raises $0.20 to $0.30
+dave: folds
+erin: folds
+grace: folds
+Uncalled bet ($0.20) returned to heidi
+heidi collected $0.30 from pot
+*** SUMMARY ***
+Total pot $0.30 | Rake $0
+Seat 1: dave (button) folded before Flop (didn't bet)
+Seat 2: erin (small blind) folded before Flop
+Seat 4: grace (big blind) folded before Flop
+Seat 5: heidi collected ($0.30)
+`
+
+func TestRoundTrip(t *testing.T) {
+
+	for _, history := range []string{testHistory, testSittingOutHistory,
+		testStraddleHistory} {
+		h, err := poker.ParseHand(history)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := Marshal(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h2, err := Parse(string(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(h, h2) {
+			t.Errorf("Expected %+v, got %+v", h, h2)
+		}
+		if h.String() != h2.String() {
+			t.Errorf("Expected\n%v\ngot\n%v", h, h2)
+		}
+	}
+}
+
+const testStraddleHistory = `PokerStars Hand #1004: Hold'em No Limit ($0.05/$0.10/$0.20 USD - BB Ante $0.10) - 2016/03/04 20:20:00 UTC
+Table 'Alcyone' 6-max Seat #1 is the button
+Seat 1: dave ($10 in chips)
+Seat 2: erin ($10 in chips)
+Seat 3: frank ($10 in chips)
+Seat 4: grace ($10 in chips)
+frank: posts the ante $0.10
+erin: posts small blind $0.05
+frank: posts big blind $0.10
+grace: posts straddle $0.20
+*** HOLE CARDS ***
+dave: raises $0.40 to $0.60
+erin: folds
+frank: folds
+grace: folds
+Uncalled bet ($0.40) returned to dave
+dave collected $0.65 from pot
+*** SUMMARY ***
+Total pot $0.65 | Rake $0
+Seat 1: dave (button) collected ($0.65)
+Seat 2: erin (small blind) folded before Flop
+Seat 3: frank (big blind) folded before Flop
+Seat 4: grace folded before Flop
+`
+
+const testOHH = `{"ohh": {
+  "spec_version": "1.4.6",
+  "site_name": "ACR",
+  "network_name": "WPN",
+  "game_number": "77",
+  "start_date_utc": "2021-04-20T14:00:00Z",
+  "table_name": "Orion",
+  "game_type": "Holdem",
+  "bet_limit": {"bet_type": "NL", "bet_cap": 0},
+  "table_size": 2,
+  "dealer_seat": 1,
+  "small_blind_amount": 1,
+  "big_blind_amount": 2,
+  "currency": "USD",
+  "players": [
+    {"id": 7, "seat": 1, "name": "ann", "starting_stack": 100, "display": "Ann"},
+    {"id": 9, "seat": 2, "name": "ben", "starting_stack": 100}
+  ],
+  "rounds": [
+    {"id": 0, "street": "Preflop", "actions": [
+      {"action_number": 1, "player_id": 7, "action": "Post SB", "amount": 1},
+      {"action_number": 2, "player_id": 9, "action": "Post BB", "amount": 2},
+      {"action_number": 3, "player_id": 7, "action": "Raise", "amount": 6},
+      {"action_number": 4, "player_id": 9, "action": "Fold", "note": "timeout"}
+    ]}
+  ],
+  "pots": [
+    {"number": 0, "amount": 4, "rake": 0, "player_wins": [
+      {"player_id": 7, "win_amount": 4, "cashout_fee": 0}
+    ]}
+  ]
+}}`
+
+func TestUnknownFields(t *testing.T) {
+
+	h, err := Parse(testOHH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.HandID != 77 || h.SmallBlind != 1 || h.BigBlind != 2 ||
+		h.Result.Pots[0].Winners[0].Position != 1 {
+		t.Errorf("Unexpected hand %+v", h)
+	}
+	if v := h.Validate(); len(v) != 0 {
+		t.Errorf("Unexpected violations %v", v)
+	}
+
+	data, err := Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc map[string]map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	o := doc["ohh"]
+	if o["network_name"] != "WPN" || o["currency"] != "USD" {
+		t.Errorf("Unknown root fields were lost:\n%s", data)
+	}
+	for _, field := range []string{`"display": "Ann"`, `"note": "timeout"`,
+		`"bet_cap": 0`, `"cashout_fee": 0`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("Unknown field %v was lost:\n%s", field, data)
+		}
+	}
+
+	// References to players who are not seated are errors.
+	for _, r := range [][2]string{
+		{`"player_id": 9, "action": "Fold"`, `"player_id": 8, "action": "Fold"`},
+		{`"player_id": 7, "win_amount"`, `"player_id": 8, "win_amount"`},
+		{`"currency": "USD",`, `"currency": "USD", "hero_player_id": 8,`},
+	} {
+		if _, err := Parse(strings.Replace(testOHH, r[0], r[1], 1)); err == nil {
+			t.Errorf("For %v expected error", r[1])
+		}
+	}
+}
+
+func TestPlayerIDs(t *testing.T) {
+
+	// The actions are numbered from 11, so they are renumbered when written.
+	text := strings.NewReplacer(`"action_number": 1,`, `"action_number": 11,`,
+		`"action_number": 2,`, `"action_number": 12,`,
+		`"action_number": 3,`, `"action_number": 13,`,
+		`"action_number": 4,`, `"action_number": 14,`).Replace(testOHH)
+	h, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		OHH struct {
+			Players []struct {
+				ID   int
+				Seat int
+			}
+			Rounds []struct {
+				Actions []struct {
+					PlayerID int `json:"player_id"`
+					Action   string
+					Note     string
+				}
+			}
+			Pots []struct {
+				PlayerWins []struct {
+					PlayerID int `json:"player_id"`
+				} `json:"player_wins"`
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	o := doc.OHH
+	if len(o.Players) != 2 || o.Players[0].ID != 7 || o.Players[1].ID != 9 {
+		t.Errorf("Expected players 7 and 9, got %+v", o.Players)
+	}
+	if len(o.Rounds) != 1 || len(o.Rounds[0].Actions) != 4 {
+		t.Fatalf("Expected 4 actions:\n%s", data)
+	}
+	for i, a := range o.Rounds[0].Actions {
+		if i == 3 && (a.PlayerID != 9 || a.Action != fold || a.Note != "timeout") {
+			t.Errorf("Expected ben's fold with a note, got %+v", a)
+		} else if i != 3 && a.Note != "" {
+			t.Errorf("For action %v unexpected note %v", i, a.Note)
+		}
+	}
+	if o.Pots[0].PlayerWins[0].PlayerID != 7 {
+		t.Errorf("Expected ann to win, got %+v", o.Pots[0].PlayerWins)
+	}
+
+	again, err := Parse(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, h) {
+		t.Errorf("Expected %+v, got %+v", h, again)
+	}
+}
+
+func TestMarshalIn(t *testing.T) {
+
+	h, err := poker.ParseHand(testHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := MarshalIn(h, poker.BigBlinds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The hand is read back at stakes of 0.5/1, in big blinds.
+	bb, err := Parse(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bb.Table.Stakes.SmallBlind != 50 || bb.Table.Stakes.BigBlind != 100 {
+		t.Errorf("Expected stakes of 0.5/1, got %v", bb.Table.Stakes)
+	}
+	if bb.Players[1].Stack != 3000 || bb.Players[4].Stack != 6500 || bb.Result.Rake != 550 {
+		t.Errorf("Expected amounts in big blinds:\n%s", data)
+	}
+	if v := bb.Validate(); len(v) != 0 {
+		t.Errorf("Unexpected violations %v", v)
+	}
+}
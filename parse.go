@@ -0,0 +1,455 @@
+package poker
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/whomever000/poker-common/card"
+)
+
+var (
+	headerRegexp    = regexp.MustCompile(`^(.+?) Hand #(\d+):\s+(.+?) \((.+?)\) - (.+)$`)
+	tourneyRegexp   = regexp.MustCompile(`^(.+?) Hand #(\d+):\s+Tournament #(\d+), (Freeroll|\S+(?: [A-Z]{3})?)\s+(.+?) - Level (\w+) \((\S+)/(\S+)\) - (.+)$`)
+	tableRegexp     = regexp.MustCompile(`^Table '(.*)' (\d+)-max Seat #(\d+) is the button`)
+	seatRegexp      = regexp.MustCompile(`^Seat (\d+): (.+) \((\S+) in chips(?:, [^)]*)?\)( is sitting out)?`)
+	blindRegexp     = regexp.MustCompile(`^(.+): posts (small|big) blind (\S+)`)
+	deadBlindRegexp = regexp.MustCompile(`^(.+): posts small & big blinds (\S+)`)
+	anteRegexp      = regexp.MustCompile(`^(.+): posts the ante (\S+)`)
+	straddleRegexp  = regexp.MustCompile(`^(.+): posts straddle (\S+)`)
+	dealtRegexp     = regexp.MustCompile(`^Dealt to (.+?) \[(.+)\]$`)
+	streetRegexp    = regexp.MustCompile(`^\*\*\* (FLOP|TURN|RIVER) \*\*\* (.*)$`)
+	collectedRegexp = regexp.MustCompile(`^(.+) collected (\S+) from (pot|main pot|side pot(?:-(\d+))?)$`)
+	totalRegexp     = regexp.MustCompile(`^Total pot (\S+).*\| Rake (\S+)(?: \| Jackpot (\S+))?`)
+	summaryRegexp   = regexp.MustCompile(`^Seat (\d+): .* mucked \[(.+)\]`)
+	cardsRegexp     = regexp.MustCompile(`\[([^\]]*)\]`)
+)
+
+// handParser holds the state of ParseHand.
+type handParser struct {
+	hand     *Hand
+	line     int
+	names    map[string]PlayerPosition
+	winners  map[int][]Share
+	pots     int
+	antes    []PlayerPosition
+	showDown bool
+	summary  bool
+}
+
+// ParseHand parses a single hand history in PokerStars format, as written by
+// Hand.String.
+func ParseHand(text string) (*Hand, error) {
+
+	p := &handParser{
+		hand:    &Hand{},
+		names:   make(map[string]PlayerPosition),
+		winners: make(map[int][]Share),
+	}
+
+	lines := strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n")
+	for i := 0; i < len(lines); i++ {
+		p.line = i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		if err := p.parseLine(line); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.hand.Table.Game == nil {
+		return nil, &ParseError{0, "missing hand header"}
+	}
+	if err := p.finish(); err != nil {
+		return nil, err
+	}
+
+	return p.hand, nil
+}
+
+// fail returns a parse error for the current line.
+func (p *handParser) fail(format string, args ...interface{}) error {
+	return &ParseError{p.line, fmt.Sprintf(format, args...)}
+}
+
+// player returns the player a line refers to, and the remainder of the line.
+// Lines referring to a player start with '<name>: '.
+func (p *handParser) player(line string) (PlayerPosition, string) {
+	var pos PlayerPosition
+	var rest string
+	longest := -1
+	for name, np := range p.names {
+		if len(name) > longest && strings.HasPrefix(line, name+": ") {
+			pos, rest, longest = np, line[len(name)+2:], len(name)
+		}
+	}
+	return pos, rest
+}
+
+// parseHeader parses the client, hand number, game and date of the header.
+func (p *handParser) parseHeader(client, id, game, date string) error {
+
+	h := p.hand
+	h.Client = client
+	var err error
+	if h.HandID, err = strconv.Atoi(id); err != nil {
+		return p.fail("invalid hand number %v", id)
+	}
+	if h.Table.Game, err = ParseGame(game); err != nil {
+		return p.fail("unknown game %v", game)
+	}
+
+	// A second date in brackets may follow.
+	if i := strings.Index(date, "["); i > 0 {
+		date = date[:i]
+	}
+	if h.Date, err = ParseDate(date); err != nil {
+		return p.fail("%v", err)
+	}
+	return nil
+}
+
+// parseLine parses a single line.
+func (p *handParser) parseLine(line string) error {
+
+	h := p.hand
+
+	if m := tourneyRegexp.FindStringSubmatch(line); m != nil && h.Table.Game == nil {
+		t := &Tournament{}
+		var err error
+		if t.ID, err = strconv.Atoi(m[3]); err != nil {
+			return p.fail("invalid tournament number %v", m[3])
+		}
+		if t.BuyIn, t.Bounty, t.Fee, err = ParseBuyIn(m[4]); err != nil {
+			return p.fail("%v", err)
+		}
+		if t.Currency, err = parseBuyInCurrency(m[4]); err != nil {
+			return p.fail("%v", err)
+		}
+		if t.Level, err = parseRoman(m[6]); err != nil {
+			return p.fail("%v", err)
+		}
+
+		// Blinds are in chips.
+		if h.Table.Stakes.SmallBlind, err = ParseAmount(m[7]); err != nil {
+			return p.fail("%v", err)
+		}
+		if h.Table.Stakes.BigBlind, err = ParseAmount(m[8]); err != nil {
+			return p.fail("%v", err)
+		}
+		h.Tournament = t
+		return p.parseHeader(m[1], m[2], m[5], m[9])
+	}
+	if m := headerRegexp.FindStringSubmatch(line); m != nil && h.Table.Game == nil {
+		var err error
+		h.Table.Stakes, err = ParseStakes(m[4])
+		if err != nil {
+			return p.fail("%v", err)
+		}
+		return p.parseHeader(m[1], m[2], m[3], m[5])
+	}
+	if h.Table.Game == nil {
+		return p.fail("expected hand header")
+	}
+
+	if m := tableRegexp.FindStringSubmatch(line); m != nil {
+		h.Table.Name = m[1]
+		h.Table.Size, _ = strconv.Atoi(m[2])
+		button, _ := strconv.Atoi(m[3])
+		h.Button = PlayerPosition(button)
+		return nil
+	}
+
+	if strings.HasPrefix(line, "*** ") {
+		return p.parseSection(line)
+	}
+
+	if p.summary {
+		if m := totalRegexp.FindStringSubmatch(line); m != nil {
+			rake, err := ParseAmount(m[2])
+			if err != nil {
+				return p.fail("%v", err)
+			}
+			h.result().Rake = rake
+			if m[3] != "" {
+				if h.result().Jackpot, err = ParseAmount(m[3]); err != nil {
+					return p.fail("%v", err)
+				}
+			}
+		} else if m := summaryRegexp.FindStringSubmatch(line); m != nil {
+			seat, _ := strconv.Atoi(m[1])
+			cards, err := card.ParseCards(m[2])
+			if err != nil {
+				return p.fail("%v", err)
+			}
+			r := h.result()
+			if mucked := r.mucked(PlayerPosition(seat)); mucked != nil {
+				mucked.Cards = cards
+			} else {
+				r.Mucked = append(r.Mucked, PlayerCards{PlayerPosition(seat), cards})
+			}
+		}
+		return nil
+	}
+
+	if len(h.Rounds) == 0 {
+		if m := seatRegexp.FindStringSubmatch(line); m != nil {
+			seat, _ := strconv.Atoi(m[1])
+			stack, err := ParseAmount(m[3])
+			if err != nil {
+				return p.fail("%v", err)
+			}
+			player := Player{Name: m[2], Stack: stack}
+			if m[4] != "" {
+				player.Status = SittingOut
+			}
+			h.Sit(PlayerPosition(seat), player)
+			p.names[m[2]] = PlayerPosition(seat)
+			return nil
+		}
+		if m := blindRegexp.FindStringSubmatch(line); m != nil {
+			pos, ok := p.names[m[1]]
+			if !ok {
+				return p.fail("unknown player %v", m[1])
+			}
+			if m[2] == "small" {
+				h.SmallBlind = pos
+			} else {
+				h.BigBlind = pos
+			}
+			return nil
+		}
+		if m := deadBlindRegexp.FindStringSubmatch(line); m != nil {
+			pos, ok := p.names[m[1]]
+			if !ok {
+				return p.fail("unknown player %v", m[1])
+			}
+			pos.Player(h).Status = DeadBlind
+			return nil
+		}
+		if m := anteRegexp.FindStringSubmatch(line); m != nil {
+			ante, err := ParseAmount(m[2])
+			if err != nil {
+				return p.fail("%v", err)
+			}
+			p.antes = append(p.antes, p.names[m[1]])
+			// Short stacked players post less than the ante.
+			if ante > h.Table.Stakes.Ante && h.Table.Stakes.BigBlindAnte == 0 {
+				h.Table.Stakes.Ante = ante
+			}
+			return nil
+		}
+		if m := straddleRegexp.FindStringSubmatch(line); m != nil {
+			straddle, err := ParseAmount(m[2])
+			if err != nil {
+				return p.fail("%v", err)
+			}
+			if straddle > h.Table.Stakes.Straddle {
+				h.Table.Stakes.Straddle = straddle
+			}
+			return nil
+		}
+	}
+
+	if m := dealtRegexp.FindStringSubmatch(line); m != nil {
+		cards, err := card.ParseCards(m[2])
+		if err != nil {
+			return p.fail("%v", err)
+		}
+		h.ThisPlayer = &PlayerCards{p.names[m[1]], cards}
+		return nil
+	}
+
+	if m := collectedRegexp.FindStringSubmatch(line); m != nil {
+		pos, ok := p.names[m[1]]
+		if !ok {
+			return p.fail("unknown player %v", m[1])
+		}
+		amount, err := ParseAmount(m[2])
+		if err != nil {
+			return p.fail("%v", err)
+		}
+		pot := 0
+		if strings.HasPrefix(m[3], "side pot") {
+			pot = 1
+			if m[4] != "" {
+				pot, _ = strconv.Atoi(m[4])
+			}
+		}
+		p.winners[pot] = append(p.winners[pot], Share{pos, amount})
+		if pot+1 > p.pots {
+			p.pots = pot + 1
+		}
+		return nil
+	}
+
+	pos, rest := p.player(line)
+	if pos == 0 {
+		// Chat, table messages and uncalled bets are ignored.
+		return nil
+	}
+	return p.parseAction(pos, rest)
+}
+
+// parseSection parses a section header, e.g. '*** FLOP *** [Ah Kd 2c]'.
+func (p *handParser) parseSection(line string) error {
+
+	h := p.hand
+
+	switch {
+	case strings.HasPrefix(line, "*** HOLE CARDS ***"):
+		h.Rounds = append(h.Rounds, Round{})
+
+		// An ante posted by the big blind alone is a big blind ante.
+		if len(p.antes) == 1 && p.antes[0] == h.BigBlind && len(p.names) > 1 &&
+			h.Table.Stakes.Ante > 0 {
+			h.Table.Stakes.BigBlindAnte, h.Table.Stakes.Ante = h.Table.Stakes.Ante, 0
+		}
+	case strings.HasPrefix(line, "*** SHOW DOWN ***"):
+		p.showDown = true
+	case strings.HasPrefix(line, "*** SUMMARY ***"):
+		p.summary = true
+	default:
+		m := streetRegexp.FindStringSubmatch(line)
+		if m == nil {
+			return nil
+		}
+		var cards []card.Card
+		for _, group := range cardsRegexp.FindAllStringSubmatch(m[2], -1) {
+			c, err := card.ParseCards(group[1])
+			if err != nil {
+				return p.fail("%v", err)
+			}
+			cards = append(cards, c...)
+		}
+		if len(h.Rounds) == 0 {
+			h.Rounds = append(h.Rounds, Round{})
+		}
+		h.Rounds = append(h.Rounds, Round{Cards: cards})
+	}
+	return nil
+}
+
+// parseAction parses the part of a line following '<name>: '.
+func (p *handParser) parseAction(pos PlayerPosition, line string) error {
+
+	h := p.hand
+	line = strings.TrimSuffix(line, " and is all-in")
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	amount := func(i int) (Amount, error) {
+		if i >= len(fields) {
+			return 0, p.fail("missing amount")
+		}
+		a, err := ParseAmount(fields[i])
+		if err != nil {
+			return 0, p.fail("%v", err)
+		}
+		return a, nil
+	}
+
+	var action Action
+	switch fields[0] {
+	case "folds":
+		action = NewFoldAction()
+	case "checks":
+		action = NewCheckAction()
+	case "calls":
+		a, err := amount(1)
+		if err != nil {
+			return err
+		}
+		action = NewCallAction(a)
+	case "bets":
+		a, err := amount(1)
+		if err != nil {
+			return err
+		}
+		action = NewBetAction(a)
+	case "raises":
+		a, err := amount(3)
+		if err != nil {
+			return err
+		}
+		action = NewRaiseAction(a)
+	case "shows":
+		m := cardsRegexp.FindStringSubmatch(line)
+		if m == nil {
+			return p.fail("missing cards")
+		}
+		cards, err := card.ParseCards(m[1])
+		if err != nil {
+			return p.fail("%v", err)
+		}
+		r := h.result()
+		r.ShowDowns = append(r.ShowDowns, PlayerCards{pos, cards})
+		return nil
+	case "mucks":
+		r := h.result()
+		r.Mucked = append(r.Mucked, PlayerCards{pos, nil})
+		return nil
+	default:
+		// Other player messages, e.g. "doesn't show hand".
+		return nil
+	}
+
+	if len(h.Rounds) == 0 {
+		return p.fail("action before hole cards")
+	}
+	r := &h.Rounds[len(h.Rounds)-1]
+	r.Actions = append(r.Actions, PlayerAction{pos, action})
+	return nil
+}
+
+// result returns the result of the hand, creating it if needed.
+func (h *Hand) result() *Result {
+	if h.Result == nil {
+		h.Result = &Result{}
+	}
+	return h.Result
+}
+
+// finish derives the pots once all lines have been parsed.
+func (p *handParser) finish() error {
+
+	h := p.hand
+
+	// Replay the hand to find the pot after each round, and the players
+	// eligible for each pot.
+	s := NewTableState(h)
+	for r := 0; r < len(h.Rounds); r++ {
+		if r > 0 {
+			s.NextRound()
+		}
+		for _, pa := range h.Rounds[r].Actions {
+			s.Apply(pa)
+		}
+		_, uncalled := s.Uncalled()
+		h.Rounds[r].Pot = s.Pot - uncalled
+	}
+
+	if p.pots == 0 {
+		return nil
+	}
+
+	pots := s.Pots()
+	r := h.result()
+	r.Pots = make([]Pot, p.pots)
+	for i := 0; i < p.pots; i++ {
+		r.Pots[i].Winners = p.winners[i]
+		for _, w := range p.winners[i] {
+			r.Pots[i].Amount += w.Amount
+		}
+		if i < len(pots) {
+			r.Pots[i].Eligible = pots[i].Eligible
+		}
+	}
+
+	return nil
+}
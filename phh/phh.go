@@ -0,0 +1,843 @@
+// Package phh converts hands to and from the Poker Hand History (PHH) format,
+// a TOML based format used for hand history datasets.
+//
+// Players are numbered p1, p2, ... starting left of the button, so that the
+// last player has the button. Fields which are not modelled by poker.Hand are
+// kept in Hand.Extensions, so that a hand survives a round trip through other
+// software. The hero and the pot sizes, which PHH does not record, are written
+// to the user-defined fields '_hero' and '_pots'. Players who are not dealt in
+// are not numbered, and are written to the fields '_sitting_out_*', and the
+// players who post a dead blind to '_dead_blinds'.
+package phh
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	poker "github.com/whomever000/poker-common"
+	"github.com/whomever000/poker-common/card"
+)
+
+// noLimitHoldEm is the PHH variant code of No Limit Texas Hold'em.
+const noLimitHoldEm = "NT"
+
+// extension is the key of unknown fields in Hand.Extensions.
+const extension = "phh"
+
+// Actions
+const (
+	dealer        = "d"
+	dealHole      = "dh"
+	dealBoard     = "db"
+	fold          = "f"
+	checkOrCall   = "cc"
+	completeBetTo = "cbr"
+	showOrMuck    = "sm"
+)
+
+// unknownCard is a card which is not known, e.g. another player's hole card.
+const unknownCard = "??"
+
+// timeLayout is the layout of the time field.
+const timeLayout = "15:04:05"
+
+// amount converts an amount of a hand to PHH's decimal representation in a
+// unit. Amounts in big blinds are rounded to two decimal places.
+func amount(h *poker.Hand, u poker.Unit, a poker.Amount) float64 {
+	if u == poker.BigBlinds {
+		return math.Round(h.BigBlinds(a)*100) / 100
+	}
+	return float64(a) / 100
+}
+
+// Parse parses a single PHH hand. It can be used as an importer.Parser.
+func Parse(text string) (*poker.Hand, error) {
+	return Unmarshal([]byte(text))
+}
+
+// ReadAll reads all hands from a PHH file. Hands in a .phhs file are separated
+// by table headers, e.g. '[1]'.
+func ReadAll(r io.Reader) ([]*poker.Hand, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := parseTOML(string(data))
+	if err != nil {
+		return nil, err
+	}
+	var hands []*poker.Hand
+	for _, doc := range docs {
+		h, err := unmarshal(doc)
+		if err != nil {
+			return hands, err
+		}
+		hands = append(hands, h)
+	}
+	return hands, nil
+}
+
+// Unmarshal converts a PHH hand to a hand.
+func Unmarshal(data []byte) (*poker.Hand, error) {
+	docs, err := parseTOML(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) != 1 {
+		return nil, fmt.Errorf("phh: expected 1 hand, got %v", len(docs))
+	}
+	return unmarshal(docs[0])
+}
+
+// fields decodes the fields of a document. Fields are removed as they are
+// decoded, so that the remaining fields are the unknown ones.
+type fields struct {
+	doc *document
+	err error
+}
+
+// take removes and returns a field, or nil if it is missing.
+func (f *fields) take(key string) interface{} {
+	v, ok := f.doc.values[key]
+	if !ok {
+		return nil
+	}
+	delete(f.doc.values, key)
+	delete(f.doc.raw, key)
+	return v
+}
+
+// string decodes a string field.
+func (f *fields) string(key string) string {
+	switch v := f.take(key).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case rawValue:
+		return string(v)
+	default:
+		f.fail(key, v)
+		return ""
+	}
+}
+
+// int decodes an integer field.
+func (f *fields) int(key string) int {
+	switch v := f.take(key).(type) {
+	case nil:
+		return 0
+	case int64:
+		return int(v)
+	default:
+		f.fail(key, v)
+		return 0
+	}
+}
+
+// amount decodes a number field.
+func (f *fields) amount(key string) poker.Amount {
+	v := f.take(key)
+	if v == nil {
+		return 0
+	}
+	a, ok := toAmount(v)
+	if !ok {
+		f.fail(key, v)
+	}
+	return a
+}
+
+// list decodes an array field.
+func (f *fields) list(key string) []interface{} {
+	switch v := f.take(key).(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	default:
+		f.fail(key, v)
+		return nil
+	}
+}
+
+// amounts decodes an array of numbers.
+func (f *fields) amounts(key string) []poker.Amount {
+	var amounts []poker.Amount
+	for _, v := range f.list(key) {
+		a, ok := toAmount(v)
+		if !ok {
+			f.fail(key, v)
+		}
+		amounts = append(amounts, a)
+	}
+	return amounts
+}
+
+// fail records an invalid field.
+func (f *fields) fail(key string, v interface{}) {
+	if f.err == nil {
+		f.err = fmt.Errorf("phh: field %v: invalid value %v", key, v)
+	}
+}
+
+// toAmount converts a number to an amount.
+func toAmount(v interface{}) (poker.Amount, bool) {
+	switch n := v.(type) {
+	case int64:
+		return poker.Amount(n * 100), true
+	case float64:
+		return poker.NewAmount(n), true
+	}
+	return 0, false
+}
+
+// unmarshal converts a parsed PHH document to a hand.
+func unmarshal(doc *document) (*poker.Hand, error) {
+
+	f := &fields{doc: doc}
+	h := &poker.Hand{}
+
+	variant := f.string("variant")
+	antes := f.amounts("antes")
+	blinds := f.amounts("blinds_or_straddles")
+	f.amount("min_bet")
+	stacks := f.amounts("starting_stacks")
+	actions := f.list("actions")
+	names := f.list("players")
+	seats := f.list("seats")
+	h.Table.Size = f.int("seat_count")
+	h.Table.Name = f.string("table")
+	h.HandID = f.int("hand")
+	h.Client = f.string("venue")
+	year, month, day := f.int("year"), f.int("month"), f.int("day")
+	clock, zone := f.string("time"), f.string("time_zone")
+	winnings := f.amounts("winnings")
+	rake := f.amount("rake")
+	f.take("finishing_stacks")
+	hero := f.int("_hero")
+	pots := f.amounts("_pots")
+	outNames := f.list("_sitting_out_players")
+	outSeats := f.list("_sitting_out_seats")
+	outStacks := f.amounts("_sitting_out_stacks")
+	outStatuses := f.list("_sitting_out_statuses")
+	dead := f.list("_dead_blinds")
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	if year > 0 {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			loc = time.UTC
+		}
+		t, err := time.ParseInLocation(timeLayout, clock, loc)
+		if clock != "" && err != nil {
+			return nil, fmt.Errorf("phh: invalid time %v", clock)
+		}
+		h.Date = poker.Date(time.Date(year, time.Month(month), day, t.Hour(), t.Minute(),
+			t.Second(), 0, loc))
+	}
+
+	if variant != noLimitHoldEm {
+		return nil, fmt.Errorf("phh: unsupported variant %v", variant)
+	}
+	h.Table.Game = poker.TexasHoldEmNoLimit
+
+	// Players
+	n := len(stacks)
+	if n < 2 {
+		return nil, fmt.Errorf("phh: expected at least 2 players, got %v", n)
+	}
+	positions := make([]poker.PlayerPosition, n)
+	for i := 0; i < n; i++ {
+		positions[i] = poker.PlayerPosition(i + 1)
+		if i < len(seats) {
+			seat, ok := seats[i].(int64)
+			if !ok || seat < 1 {
+				return nil, fmt.Errorf("phh: invalid seat %v", seats[i])
+			}
+			positions[i] = poker.PlayerPosition(seat)
+		}
+		if int(positions[i]) > h.Table.Size {
+			h.Table.Size = int(positions[i])
+		}
+	}
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("p%v", i+1)
+		if i < len(names) {
+			if s, ok := names[i].(string); ok {
+				name = s
+			}
+		}
+		h.Sit(positions[i], poker.Player{Name: name, Stack: stacks[i]})
+	}
+	h.Button = positions[n-1]
+	for _, v := range dead {
+		i, ok := v.(int64)
+		if !ok || i < 1 || int(i) > n {
+			return nil, fmt.Errorf("phh: invalid dead blind %v", v)
+		}
+		positions[i-1].Player(h).Status = poker.DeadBlind
+	}
+	for i := 0; i < len(outSeats); i++ {
+		seat, ok := outSeats[i].(int64)
+		if !ok || seat < 1 || i >= len(outNames) || i >= len(outStacks) {
+			return nil, fmt.Errorf("phh: invalid sitting out seat %v", outSeats[i])
+		}
+		name, _ := outNames[i].(string)
+		p := poker.Player{Name: name, Stack: outStacks[i], Status: poker.SittingOut}
+		if i < len(outStatuses) && outStatuses[i] == poker.MissedBlinds.String() {
+			p.Status = poker.MissedBlinds
+		}
+		h.Sit(poker.PlayerPosition(seat), p)
+		if int(seat) > h.Table.Size {
+			h.Table.Size = int(seat)
+		}
+	}
+
+	// Blinds. The larger blind is the big blind, and the button posts the
+	// small blind heads-up. A third blind is a straddle.
+	sb, bb, straddle := -1, -1, -1
+	for i := 0; i < len(blinds) && i < n; i++ {
+		switch {
+		case blinds[i] == 0:
+		case bb < 0:
+			bb = i
+		case sb < 0 && blinds[i] >= blinds[bb]:
+			sb, bb = bb, i
+		case sb < 0:
+			sb = i
+		case straddle < 0:
+			straddle = i
+		default:
+			return nil, fmt.Errorf("phh: expected at most one straddle")
+		}
+	}
+	if bb >= 0 {
+		h.BigBlind = positions[bb]
+		h.Table.Stakes.BigBlind = blinds[bb]
+	}
+	if sb >= 0 {
+		h.SmallBlind = positions[sb]
+		h.Table.Stakes.SmallBlind = blinds[sb]
+		if n == 2 {
+			h.Button = h.SmallBlind
+		}
+	}
+
+	if straddle >= 0 {
+		h.Table.Stakes.Straddle = blinds[straddle]
+		if h.Straddler() != positions[straddle] {
+			return nil, fmt.Errorf("phh: straddle must be left of the big blind")
+		}
+	}
+
+	// Antes must be the same for every player, unless the big blind posts
+	// the ante alone.
+	var bbAnte poker.Amount
+	if bb >= 0 && bb < len(antes) {
+		bbAnte = antes[bb]
+	}
+	for i := 0; i < len(antes); i++ {
+		if antes[i] != 0 && i != bb {
+			bbAnte = 0
+		}
+	}
+	if bbAnte > 0 {
+		h.Table.Stakes.BigBlindAnte = bbAnte
+		antes = nil
+	}
+	for i := 0; i < len(antes); i++ {
+		if antes[i] != antes[0] || len(antes) != n {
+			return nil, fmt.Errorf("phh: antes must be the same for every player")
+		}
+		h.Table.Stakes.Ante = antes[0]
+	}
+
+	if err := unmarshalActions(h, actions, positions, hero); err != nil {
+		return nil, err
+	}
+
+	if len(winnings) > 0 {
+		won := make(map[poker.PlayerPosition]poker.Amount)
+		for i := 0; i < len(winnings) && i < n; i++ {
+			won[positions[i]] = winnings[i]
+		}
+		unmarshalPots(h, won, rake, pots)
+	}
+
+	keep(h, doc)
+	return h, nil
+}
+
+// unmarshalActions converts PHH action strings.
+func unmarshalActions(h *poker.Hand, actions []interface{},
+	positions []poker.PlayerPosition, hero int) error {
+
+	dealt := make(map[poker.PlayerPosition][]card.Card)
+	h.Rounds = []poker.Round{{}}
+	s := poker.NewTableState(h)
+
+	player := func(str string) (poker.PlayerPosition, error) {
+		i, err := strconv.Atoi(strings.TrimPrefix(str, "p"))
+		if !strings.HasPrefix(str, "p") || err != nil || i < 1 || i > len(positions) {
+			return 0, fmt.Errorf("phh: invalid player %v", str)
+		}
+		return positions[i-1], nil
+	}
+
+	for _, v := range actions {
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("phh: invalid action %v", v)
+		}
+		if i := strings.Index(str, "#"); i >= 0 {
+			str = str[:i]
+		}
+		words := strings.Fields(str)
+		if len(words) < 2 {
+			return fmt.Errorf("phh: invalid action %q", str)
+		}
+
+		arg := ""
+		if len(words) > 2 {
+			arg = words[2]
+		}
+		if words[0] == dealer {
+			if len(words) > 3 {
+				arg = words[3]
+			}
+			cards, err := parseCards(arg)
+			if err != nil {
+				return err
+			}
+			switch words[1] {
+			case dealHole:
+				pos, err := player(words[2])
+				if err != nil {
+					return err
+				}
+				if cards != nil {
+					dealt[pos] = cards
+				}
+			case dealBoard:
+				board := h.Rounds[len(h.Rounds)-1].Cards
+				board = append(append([]card.Card(nil), board...), cards...)
+				h.Rounds = append(h.Rounds, poker.Round{Cards: board})
+				s.NextRound()
+			default:
+				return fmt.Errorf("phh: unsupported action %q", str)
+			}
+			continue
+		}
+
+		pos, err := player(words[0])
+		if err != nil {
+			return err
+		}
+
+		var action poker.Action
+		switch words[1] {
+		case fold:
+			action = poker.NewFoldAction()
+		case checkOrCall:
+			if toCall := s.ToCall(pos); toCall > 0 {
+				action = poker.NewCallAction(toCall)
+			} else {
+				action = poker.NewCheckAction()
+			}
+		case completeBetTo:
+			v, err := poker.ParseAmount(arg)
+			if err != nil {
+				return fmt.Errorf("phh: invalid amount %q", str)
+			}
+			if s.CurrentBet == 0 {
+				action = poker.NewBetAction(v)
+			} else {
+				action = poker.NewRaiseAction(v)
+			}
+		case showOrMuck:
+			cards, err := parseCards(arg)
+			if err != nil {
+				return err
+			}
+			r := result(h)
+			if cards != nil {
+				r.ShowDowns = append(r.ShowDowns, poker.PlayerCards{Position: pos, Cards: cards})
+			} else {
+				r.Mucked = append(r.Mucked, poker.PlayerCards{Position: pos, Cards: dealt[pos]})
+			}
+			continue
+		default:
+			return fmt.Errorf("phh: unsupported action %q", str)
+		}
+
+		pa := poker.PlayerAction{Position: pos, Action: action}
+		s.Apply(pa)
+		r := &h.Rounds[len(h.Rounds)-1]
+		r.Actions = append(r.Actions, pa)
+	}
+
+	// The hero is the first player whose cards are known, unless recorded.
+	if hero > 0 && hero <= len(positions) {
+		pos := positions[hero-1]
+		h.ThisPlayer = &poker.PlayerCards{Position: pos, Cards: dealt[pos]}
+	}
+	for i := 0; i < len(positions) && h.ThisPlayer == nil; i++ {
+		if cards, ok := dealt[positions[i]]; ok {
+			h.ThisPlayer = &poker.PlayerCards{Position: positions[i], Cards: cards}
+		}
+	}
+
+	// Record the pot after each round.
+	s = poker.NewTableState(h)
+	for r := 0; r < len(h.Rounds); r++ {
+		if r > 0 {
+			s.NextRound()
+		}
+		for _, pa := range h.Rounds[r].Actions {
+			s.Apply(pa)
+		}
+		_, uncalled := s.Uncalled()
+		h.Rounds[r].Pot = s.Pot - uncalled
+	}
+
+	return nil
+}
+
+// unmarshalPots divides the players' winnings among the pots. Side pots are
+// awarded first, as their winners must also be eligible for the main pot. The
+// rake is taken from the main pot, unless the pot sizes are given.
+func unmarshalPots(h *poker.Hand, won map[poker.PlayerPosition]poker.Amount,
+	rake poker.Amount, amounts []poker.Amount) {
+
+	pots, err := poker.SidePots(h)
+	if err != nil || len(pots) == 0 {
+		return
+	}
+
+	r := result(h)
+	r.Rake = rake
+	for i := 0; i < len(pots); i++ {
+		switch {
+		case len(amounts) == len(pots):
+			pots[i].Amount = amounts[i]
+		case i == 0:
+			pots[i].Amount -= rake
+		}
+	}
+
+	for i := len(pots) - 1; i >= 0; i-- {
+		left := pots[i].Amount
+		for _, pos := range pots[i].Eligible {
+			share := won[pos]
+			if share > left {
+				share = left
+			}
+			if share <= 0 {
+				continue
+			}
+			pots[i].Winners = append(pots[i].Winners, poker.Share{Position: pos, Amount: share})
+			won[pos] -= share
+			left -= share
+		}
+	}
+
+	// Pots nobody won were not contested, e.g. everybody folded.
+	for len(pots) > 1 && len(pots[len(pots)-1].Winners) == 0 {
+		pots = pots[:len(pots)-1]
+	}
+	r.Pots = pots
+}
+
+// result returns the result of a hand, creating it if needed.
+func result(h *poker.Hand) *poker.Result {
+	if h.Result == nil {
+		h.Result = &poker.Result{}
+	}
+	return h.Result
+}
+
+// parseCards parses cards written without spaces, e.g. 'AcAd'. It returns nil
+// if the cards are unknown, e.g. '????'.
+func parseCards(str string) ([]card.Card, error) {
+	str = strings.Replace(str, " ", "", -1)
+	if str == "" || strings.Trim(str, "?") == "" {
+		return nil, nil
+	}
+	var cards []card.Card
+	for i := 0; i+2 <= len(str); i += 2 {
+		if str[i:i+2] == unknownCard {
+			return nil, nil
+		}
+		c, err := card.ParseCard(str[i : i+2])
+		if err != nil {
+			return nil, fmt.Errorf("phh: %v", err)
+		}
+		cards = append(cards, c)
+	}
+	if len(str)%2 != 0 {
+		return nil, fmt.Errorf("phh: invalid cards %v", str)
+	}
+	return cards, nil
+}
+
+// formatCards formats cards without spaces, e.g. 'AcAd'.
+func formatCards(cards []card.Card) string {
+	return strings.Replace(card.FormatCards(cards), " ", "", -1)
+}
+
+// keep saves the remaining unknown fields of a document in the hand.
+func keep(h *poker.Hand, doc *document) {
+	if len(doc.raw) == 0 {
+		return
+	}
+	if h.Extensions == nil {
+		h.Extensions = make(map[string]json.RawMessage)
+	}
+	raw, _ := json.Marshal(doc.raw)
+	h.Extensions[extension] = raw
+}
+
+// Marshal converts a hand to a PHH hand.
+func Marshal(h *poker.Hand) ([]byte, error) {
+	return MarshalIn(h, poker.Native)
+}
+
+// MarshalIn converts a hand to a PHH hand with amounts in a unit. Hands in big
+// blinds are for comparing hands across stakes, and are read back in big
+// blinds rather than at their stakes.
+func MarshalIn(h *poker.Hand, u poker.Unit) ([]byte, error) {
+
+	if h.Table.Game == nil || h.Table.Game.Game() != poker.TexasHoldEmNoLimit {
+		return nil, fmt.Errorf("phh: unsupported game %v", h.Table.Game)
+	}
+
+	// Players dealt in start left of the button.
+	var positions []poker.PlayerPosition
+	size := h.TableSize()
+	pos := h.Button
+	for i := 0; i < size; i++ {
+		pos = poker.NextPlayerPosition(pos, size)
+		if p := pos.Player(h); p != nil && p.IsDealtIn() {
+			positions = append(positions, pos)
+		}
+	}
+	index := make(map[poker.PlayerPosition]int)
+	for i, pos := range positions {
+		index[pos] = i + 1
+	}
+
+	var names []string
+	var seats []int
+	var antes, blinds, stacks, finishing, winnings []float64
+	var won map[poker.PlayerPosition]poker.Amount
+	if h.Result != nil {
+		won = h.Result.Winnings()
+	}
+	s := finalState(h)
+	for _, pos := range positions {
+		p := pos.Player(h)
+		names = append(names, p.Name)
+		seats = append(seats, int(pos))
+		ante := h.Table.Stakes.Ante
+		if pos == h.BigBlind {
+			ante += h.Table.Stakes.BigBlindAnte
+		}
+		antes = append(antes, amount(h, u, ante))
+		switch pos {
+		case h.SmallBlind:
+			blinds = append(blinds, amount(h, u, h.Table.Stakes.SmallBlind))
+		case h.BigBlind:
+			blinds = append(blinds, amount(h, u, h.Table.Stakes.BigBlind))
+		case h.Straddler():
+			blinds = append(blinds, amount(h, u, h.Table.Stakes.Straddle))
+		default:
+			blinds = append(blinds, 0)
+		}
+		stacks = append(stacks, amount(h, u, p.Stack))
+		finishing = append(finishing, amount(h, u, s.Stacks[pos]+won[pos]))
+		winnings = append(winnings, amount(h, u, won[pos]))
+	}
+
+	w := &tomlWriter{}
+	w.set("variant", noLimitHoldEm)
+	w.set("antes", antes)
+	w.set("blinds_or_straddles", blinds)
+	w.set("min_bet", amount(h, u, h.Table.Stakes.BigBlind))
+	w.set("starting_stacks", stacks)
+	w.set("actions", marshalActions(h, u, index))
+	w.set("players", names)
+	w.set("seats", seats)
+	w.set("seat_count", h.Table.Size)
+	w.set("table", h.Table.Name)
+	w.set("hand", h.HandID)
+	w.set("venue", h.Client)
+	if t := time.Time(h.Date); !t.IsZero() {
+		w.set("year", t.Year())
+		w.set("month", int(t.Month()))
+		w.set("day", t.Day())
+		w.setRaw("time", t.Format(timeLayout))
+		w.set("time_zone", t.Location().String())
+	}
+	if h.Result != nil {
+		w.set("finishing_stacks", finishing)
+		w.set("winnings", winnings)
+		w.set("rake", amount(h, u, h.Result.Rake))
+		if len(h.Result.Pots) > 1 {
+			var pots []float64
+			for _, pot := range h.Result.Pots {
+				pots = append(pots, amount(h, u, pot.Amount))
+			}
+			w.set("_pots", pots)
+		}
+	}
+	if h.ThisPlayer != nil && index[h.ThisPlayer.Position] > 0 {
+		w.set("_hero", index[h.ThisPlayer.Position])
+	}
+	var dead []int
+	for i, pos := range positions {
+		if pos.Player(h).Status == poker.DeadBlind {
+			dead = append(dead, i+1)
+		}
+	}
+	if len(dead) > 0 {
+		w.set("_dead_blinds", dead)
+	}
+	var outNames, outStatuses []string
+	var outSeats []int
+	var outStacks []float64
+	for _, pos := range h.Occupied() {
+		if p := pos.Player(h); !p.IsDealtIn() {
+			outNames = append(outNames, p.Name)
+			outSeats = append(outSeats, int(pos))
+			outStacks = append(outStacks, amount(h, u, p.Stack))
+			outStatuses = append(outStatuses, p.Status.String())
+		}
+	}
+	if len(outSeats) > 0 {
+		w.set("_sitting_out_players", outNames)
+		w.set("_sitting_out_seats", outSeats)
+		w.set("_sitting_out_stacks", outStacks)
+		w.set("_sitting_out_statuses", outStatuses)
+	}
+
+	// Unknown fields
+	var raw map[string]string
+	if json.Unmarshal(h.Extensions[extension], &raw) == nil {
+		for _, key := range sortedKeys(raw) {
+			w.setRaw(key, raw[key])
+		}
+	}
+
+	return []byte(w.b.String()), nil
+}
+
+// Write writes a hand as PHH, followed by a blank line which separates it from
+// the next hand.
+func Write(w io.Writer, h *poker.Hand) error {
+	data, err := Marshal(h)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// finalState returns the table state after the last action, with uncalled bets
+// returned.
+func finalState(h *poker.Hand) *poker.TableState {
+	s := poker.NewTableState(h)
+	for r := 0; r < len(h.Rounds); r++ {
+		if r > 0 {
+			s.NextRound()
+		}
+		for _, pa := range h.Rounds[r].Actions {
+			s.Apply(pa)
+		}
+	}
+	s.NextRound()
+	return s
+}
+
+// marshalActions converts the betting rounds and showdown to PHH action
+// strings.
+func marshalActions(h *poker.Hand, u poker.Unit,
+	index map[poker.PlayerPosition]int) []string {
+
+	// Hole cards are known for the hero and for players who show or muck
+	// them.
+	known := make(map[poker.PlayerPosition][]card.Card)
+	if h.ThisPlayer != nil {
+		known[h.ThisPlayer.Position] = h.ThisPlayer.Cards
+	}
+	if h.Result != nil {
+		for _, pc := range append(append([]poker.PlayerCards(nil),
+			h.Result.ShowDowns...), h.Result.Mucked...) {
+			if len(pc.Cards) > 0 {
+				known[pc.Position] = pc.Cards
+			}
+		}
+	}
+
+	positions := make([]poker.PlayerPosition, len(index))
+	for pos, i := range index {
+		positions[i-1] = pos
+	}
+
+	var actions []string
+	for i, pos := range positions {
+		cards := strings.Repeat(unknownCard, 2)
+		if len(known[pos]) > 0 {
+			cards = formatCards(known[pos])
+		}
+		actions = append(actions, fmt.Sprintf("d dh p%v %v", i+1, cards))
+	}
+
+	s := poker.NewTableState(h)
+	var prev []card.Card
+	for r := 0; r < len(h.Rounds); r++ {
+		if r > 0 {
+			s.NextRound()
+			cards := h.Rounds[r].Cards
+			if len(cards) > len(prev) {
+				actions = append(actions, fmt.Sprintf("d db %v", formatCards(cards[len(prev):])))
+				prev = cards
+			}
+		}
+
+		for _, pa := range h.Rounds[r].Actions {
+			s.Apply(pa)
+			p := fmt.Sprintf("p%v", index[pa.Position])
+			switch pa.Action.Type() {
+			case poker.Fold:
+				actions = append(actions, p+" "+fold)
+			case poker.Check, poker.Call:
+				actions = append(actions, p+" "+checkOrCall)
+			case poker.Bet, poker.Raise:
+				to := amount(h, u, s.Contributions[pa.Position])
+				actions = append(actions, fmt.Sprintf("%v %v %v", p, completeBetTo,
+					strconv.FormatFloat(to, 'f', -1, 64)))
+			}
+		}
+	}
+
+	if h.Result != nil {
+		for _, sd := range h.Result.ShowDowns {
+			actions = append(actions, fmt.Sprintf("p%v %v %v", index[sd.Position],
+				showOrMuck, formatCards(sd.Cards)))
+		}
+		for _, m := range h.Result.Mucked {
+			actions = append(actions, fmt.Sprintf("p%v %v", index[m.Position], showOrMuck))
+		}
+	}
+
+	return actions
+}
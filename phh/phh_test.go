@@ -0,0 +1,294 @@
+package phh
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	poker "github.com/whomever000/poker-common"
+)
+
+const testHistory = `PokerStars Hand #1001: Hold'em No Limit ($0.05/$0.10 USD) - 2016/03/04 20:15:42 UTC
+Table 'Alcyone' 6-max Seat #4 is the button
+Seat 1: dave ($3 in chips)
+Seat 2: erin ($10 in chips)
+Seat 4: frank ($6.50 in chips)
+Seat 6: grace ($10 in chips)
+grace: posts small blind $0.05
+dave: posts big blind $0.10
+*** HOLE CARDS ***
+Dealt to erin [Qh Qd]
+erin: raises $0.20 to $0.30
+frank: calls $0.30
+grace: folds
+dave: calls $0.20
+*** FLOP *** [2c 7d Kh]
+dave: bets $2.70 and is all-in
+erin: raises $4 to $6.70
+frank: calls $6.20 and is all-in
+Uncalled bet ($0.50) returned to erin
+*** TURN *** [2c 7d Kh] [3s]
+*** RIVER *** [2c 7d Kh 3s] [9c]
+*** SHOW DOWN ***
+erin: shows [Qh Qd] (a pair of Queens)
+frank: shows [Kd Js] (a pair of Kings)
+dave: mucks hand
+frank collected $6.80 from side pot-1
+frank collected $8.70 from main pot
+*** SUMMARY ***
+Total pot $16.05 Main pot $8.70. Side pot-1 $6.80. | Rake $0.55
+Board [2c 7d Kh 3s 9c]
+Seat 1: dave (big blind) mucked [Ac 4c]
+Seat 2: erin showed [Qh Qd] and lost with a pair of Queens
+Seat 4: frank (button) showed [Kd Js] and won ($15.50) with a pair of Kings
+Seat 6: grace (small blind) folded before Flop
+`
+
+const testStraddleHistory = `PokerStars Hand #1002: Hold'em No Limit ($0.05/$0.10/$0.20 USD) - 2016/03/04 20:15:42 UTC
+Table 'Alcyone' 4-max Seat #1 is the button
+Seat 1: dave ($10 in chips)
+Seat 2: erin ($10 in chips)
+Seat 3: frank ($10 in chips)
+Seat 4: grace ($10 in chips)
+erin: posts small blind $0.05
+frank: posts big blind $0.10
+grace: posts straddle $0.20
+*** HOLE CARDS ***
+dave: raises $0.20 to $0.40
+erin: folds
+frank: folds
+grace: calls $0.20
+*** FLOP *** [2c 7d Kh]
+grace: checks
+dave: bets $0.50
+grace: folds
+Uncalled bet ($0.50) returned to dave
+dave collected $0.95 from pot
+*** SUMMARY ***
+Total pot $0.95 | Rake $0
+Board [2c 7d Kh]
+Seat 1: dave (button) collected ($0.95)
+Seat 2: erin (small blind) folded before Flop
+Seat 3: frank (big blind) folded before Flop
+Seat 4: grace folded on the Flop
+`
+
+const testSittingOutHistory = `PokerStars Hand #1003: Hold'em No Limit ($0.05/$0.10 USD) - 2016/03/04 20:18:03 UTC
+Table 'Alcyone' 6-max Seat #1 is the button
+Seat 1: dave ($10 in chips)
+Seat 2: erin ($10 in chips)
+Seat 3: frank ($10 in chips) is sitting out
+Seat 4: grace ($10 in chips)
+Seat 5: heidi ($10 in chips)
+erin: posts small blind $0.05
+grace: posts big blind $0.10
+heidi: posts small & big blinds $0.15
+*** HOLE CARDS ***
+heidi: raises $0.20 to $0.30
+dave: folds
+erin: folds
+grace: folds
+Uncalled bet ($0.20) returned to heidi
+heidi collected $0.30 from pot
+*** SUMMARY ***
+Total pot $0.30 | Rake $0
+Seat 1: dave (button) folded before Flop (didn't bet)
+Seat 2: erin (small blind) folded before Flop
+Seat 4: grace (big blind) folded before Flop
+Seat 5: heidi collected ($0.30)
+`
+
+const testHighStakesHistory = `PokerStars Hand #1004: Hold'em No Limit ($5000/$10000 USD) - 2016/03/04 20:15:42 UTC
+Table 'Alcyone' 2-max Seat #1 is the button
+Seat 1: dave ($2000000 in chips)
+Seat 2: erin ($2000000 in chips)
+dave: posts small blind $5000
+erin: posts big blind $10000
+*** HOLE CARDS ***
+dave: raises $1000000 to $1010000
+erin: folds
+Uncalled bet ($1000000) returned to dave
+dave collected $20000 from pot
+*** SUMMARY ***
+Total pot $20000 | Rake $0
+Seat 1: dave (button) (small blind) collected ($20000)
+Seat 2: erin (big blind) folded before Flop
+`
+
+func TestRoundTrip(t *testing.T) {
+
+	// The forced bets include a straddle, and a dead blind of a player
+	// returning next to a player sitting out. Large amounts are written
+	// without exponents.
+	for i, history := range []string{testHistory, testStraddleHistory,
+		testSittingOutHistory, testHighStakesHistory} {
+		h, err := poker.ParseHand(history)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := Marshal(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h2, err := Parse(string(data))
+		if err != nil {
+			t.Fatalf("For history %v: %v\n%s", i, err, data)
+		}
+
+		if !reflect.DeepEqual(h, h2) {
+			t.Errorf("For history %v expected %+v, got %+v", i, h, h2)
+		}
+		if h.String() != h2.String() {
+			t.Errorf("For history %v expected\n%v\ngot\n%v", i, h, h2)
+		}
+	}
+}
+
+const testPHH = `variant = 'NT'
+ante_trimming_status = true
+antes = [0.5, 0.5, 0.5]
+blinds_or_straddles = [1, 2, 0]
+min_bet = 2
+starting_stacks = [200, 150, 300]
+actions = [
+  # Pre-flop
+  'd dh p1 ????',
+  'd dh p2 ????',
+  'd dh p3 AhKh',
+  'p3 cbr 6',
+  'p1 f',
+  'p2 cc',
+  # Flop
+  'd db Kd7c2s',
+  'p2 cc',
+  'p3 cbr 8',
+  'p2 cc',
+  'd db 9h',
+  'p2 cc',
+  'p3 cc',
+  'd db 3c',
+  'p2 cbr 20',
+  'p3 cc',
+  'p2 sm QcQd',
+  'p3 sm AhKh # top pair',
+]
+players = ['alice', 'bob', 'carol']
+event = "Friday Game"
+year = 2023
+month = 6
+day = 2
+time = 21:30:00
+time_zone = 'UTC'
+winnings = [0, 0, 70.5]
+`
+
+func TestParse(t *testing.T) {
+
+	h, err := Parse(testPHH)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := poker.Stakes{SmallBlind: 100, BigBlind: 200, Ante: 50}
+	if h.Table.Stakes != expected {
+		t.Errorf("Expected stakes %+v, got %+v", expected, h.Table.Stakes)
+	}
+	if h.Button != 3 || h.SmallBlind != 1 || h.BigBlind != 2 || h.Table.Size != 3 {
+		t.Errorf("Unexpected positions %v %v %v", h.Button, h.SmallBlind, h.BigBlind)
+	}
+	if h.ThisPlayer == nil || h.ThisPlayer.Position != 3 {
+		t.Errorf("Unexpected hero %+v", h.ThisPlayer)
+	}
+	if len(h.Rounds) != 4 || h.Rounds[3].Pot != 7050 {
+		t.Errorf("Unexpected rounds %+v", h.Rounds)
+	}
+	if w := h.Result.Winnings(); w[3] != 7050 || len(w) != 1 {
+		t.Errorf("Unexpected winnings %v", w)
+	}
+	if date := time.Time(h.Date); date.Hour() != 21 || date.Day() != 2 {
+		t.Errorf("Unexpected date %v", h.Date)
+	}
+	if v := h.Validate(); len(v) != 0 {
+		t.Errorf("Unexpected violations %v", v)
+	}
+
+	data, err := Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`ante_trimming_status = true`,
+		`event = "Friday Game"`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("Unknown field %v was lost:\n%s", field, data)
+		}
+	}
+
+	h2, err := Parse(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(h, h2) {
+		t.Errorf("Expected %+v, got %+v", h, h2)
+	}
+
+	// The big blind may post the ante for the table.
+	h, err = Parse(strings.Replace(testPHH, "antes = [0.5, 0.5, 0.5]", "antes = [0, 1.5, 0]", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = poker.Stakes{SmallBlind: 100, BigBlind: 200, BigBlindAnte: 150}
+	if h.Table.Stakes != expected {
+		t.Errorf("Expected stakes %+v, got %+v", expected, h.Table.Stakes)
+	}
+	if v := h.Validate(); len(v) != 0 {
+		t.Errorf("Unexpected violations %v", v)
+	}
+	data, err = Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h2, err = Parse(string(data)); err != nil || !reflect.DeepEqual(h, h2) {
+		t.Errorf("Expected %+v, got %+v (%v)", h, h2, err)
+	}
+}
+
+func TestReadAll(t *testing.T) {
+
+	text := "[1]\n" + testPHH + "\n[2]\n" + testPHH
+	hands, err := ReadAll(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hands) != 2 {
+		t.Errorf("Expected 2 hands, got %v", len(hands))
+	}
+}
+
+func TestMarshalIn(t *testing.T) {
+
+	h, err := poker.ParseHand(testHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := MarshalIn(h, poker.BigBlinds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The hand is read back at stakes of 0.5/1, in big blinds.
+	bb, err := Parse(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bb.Table.Stakes.SmallBlind != 50 || bb.Table.Stakes.BigBlind != 100 {
+		t.Errorf("Expected stakes of 0.5/1, got %v", bb.Table.Stakes)
+	}
+	if bb.Players[1].Stack != 3000 || bb.Players[4].Stack != 6500 || bb.Result.Rake != 550 {
+		t.Errorf("Expected amounts in big blinds:\n%s", data)
+	}
+	if v := bb.Validate(); len(v) != 0 {
+		t.Errorf("Unexpected violations %v", v)
+	}
+}
@@ -0,0 +1,310 @@
+package phh
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// document is a parsed TOML document. Only the subset of TOML used by PHH
+// is supported: strings, numbers, booleans and arrays of those, and table
+// headers separating the hands of a .phhs file.
+type document struct {
+
+	// values are the parsed values by key.
+	values map[string]interface{}
+
+	// raw are the unparsed values by key.
+	raw map[string]string
+
+	// keys are the keys in the order they appear.
+	keys []string
+}
+
+// rawValue is a value which is kept as written, e.g. a local time.
+type rawValue string
+
+// tomlParser parses TOML text.
+type tomlParser struct {
+	text string
+	pos  int
+	line int
+}
+
+// parseTOML parses a TOML text. Each table (e.g. '[1]') starts a new
+// document. Keys before the first table form the first document.
+func parseTOML(text string) ([]*document, error) {
+
+	p := &tomlParser{text: text, line: 1}
+	doc := newDocument()
+	var docs []*document
+
+	for {
+		p.skipSpace(true)
+		if p.pos >= len(p.text) {
+			break
+		}
+
+		if p.text[p.pos] == '[' {
+			end := strings.IndexByte(p.text[p.pos:], '\n')
+			if end < 0 {
+				end = len(p.text) - p.pos
+			}
+			if len(doc.keys) > 0 {
+				docs = append(docs, doc)
+			}
+			doc = newDocument()
+			p.pos += end
+			continue
+		}
+
+		key := p.key()
+		if key == "" {
+			return nil, p.fail("expected key")
+		}
+		p.skipSpace(false)
+		if !p.consume('=') {
+			return nil, p.fail("expected '=' after %v", key)
+		}
+		p.skipSpace(false)
+
+		start := p.pos
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := doc.values[key]; !ok {
+			doc.keys = append(doc.keys, key)
+		}
+		doc.values[key] = value
+		doc.raw[key] = strings.TrimSpace(p.text[start:p.pos])
+	}
+
+	if len(doc.keys) > 0 {
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// newDocument creates an empty document.
+func newDocument() *document {
+	return &document{
+		values: make(map[string]interface{}),
+		raw:    make(map[string]string),
+	}
+}
+
+// fail returns an error at the current line.
+func (p *tomlParser) fail(format string, args ...interface{}) error {
+	return fmt.Errorf("phh: line %v: %v", p.line, fmt.Sprintf(format, args...))
+}
+
+// consume consumes a character if it is next.
+func (p *tomlParser) consume(c byte) bool {
+	if p.pos < len(p.text) && p.text[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// skipSpace skips white space and comments, and newlines if requested.
+func (p *tomlParser) skipSpace(newlines bool) {
+	for p.pos < len(p.text) {
+		switch c := p.text[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.pos++
+			p.line++
+		case c == '#':
+			for p.pos < len(p.text) && p.text[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// key parses a bare key.
+func (p *tomlParser) key() string {
+	start := p.pos
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		if c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+			c >= '0' && c <= '9' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.text[start:p.pos]
+}
+
+// value parses a value.
+func (p *tomlParser) value() (interface{}, error) {
+
+	if p.pos >= len(p.text) {
+		return nil, p.fail("expected value")
+	}
+
+	switch c := p.text[p.pos]; {
+	case c == '"' || c == '\'':
+		return p.string(c)
+
+	case c == '[':
+		p.pos++
+		var values []interface{}
+		for {
+			p.skipSpace(true)
+			if p.consume(']') {
+				return values, nil
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+			p.skipSpace(true)
+			if p.consume(']') {
+				return values, nil
+			}
+			if !p.consume(',') {
+				return nil, p.fail("expected ',' or ']'")
+			}
+		}
+
+	default:
+		start := p.pos
+		for p.pos < len(p.text) && !strings.ContainsRune(" \t\r\n,]#", rune(p.text[p.pos])) {
+			p.pos++
+		}
+		word := strings.Replace(p.text[start:p.pos], "_", "", -1)
+		switch word {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		if i, err := strconv.ParseInt(word, 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(word, 64); err == nil {
+			return f, nil
+		}
+		if strings.ContainsAny(word, ":-") {
+			return rawValue(word), nil
+		}
+		return nil, p.fail("invalid value %v", word)
+	}
+}
+
+// string parses a basic ("...") or literal ('...') string.
+func (p *tomlParser) string(quote byte) (string, error) {
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\n':
+			return "", p.fail("unterminated string")
+		case c == '\\' && quote == '"' && p.pos < len(p.text):
+			e := p.text[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if p.pos+4 > len(p.text) {
+					return "", p.fail("invalid escape")
+				}
+				r, err := strconv.ParseUint(p.text[p.pos:p.pos+4], 16, 32)
+				if err != nil {
+					return "", p.fail("invalid escape")
+				}
+				b.WriteRune(rune(r))
+				p.pos += 4
+			default:
+				b.WriteByte(e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.fail("unterminated string")
+}
+
+// tomlWriter writes a TOML document.
+type tomlWriter struct {
+	b strings.Builder
+}
+
+// set writes a key and a value.
+func (w *tomlWriter) set(key string, value interface{}) {
+	fmt.Fprintf(&w.b, "%v = %v\n", key, formatValue(value))
+}
+
+// setRaw writes a key and a value which is written as is.
+func (w *tomlWriter) setRaw(key, raw string) {
+	fmt.Fprintf(&w.b, "%v = %v\n", key, raw)
+}
+
+// formatValue formats a value as TOML.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case rawValue:
+		return string(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []string:
+		strs := make([]string, len(v))
+		for i := 0; i < len(v); i++ {
+			strs[i] = strconv.Quote(v[i])
+		}
+		return formatArray(strs)
+	case []int:
+		strs := make([]string, len(v))
+		for i := 0; i < len(v); i++ {
+			strs[i] = strconv.Itoa(v[i])
+		}
+		return "[" + strings.Join(strs, ", ") + "]"
+	case []float64:
+		strs := make([]string, len(v))
+		for i := 0; i < len(v); i++ {
+			strs[i] = strconv.FormatFloat(v[i], 'f', -1, 64)
+		}
+		return "[" + strings.Join(strs, ", ") + "]"
+	}
+	return fmt.Sprint(value)
+}
+
+// formatArray formats a long array over multiple lines.
+func formatArray(items []string) string {
+	if len(items) == 0 {
+		return "[]"
+	}
+	return "[\n  " + strings.Join(items, ",\n  ") + ",\n]"
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
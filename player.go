@@ -2,6 +2,7 @@ package poker
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
 
 	"github.com/whomever000/poker-common/card"
@@ -15,6 +16,45 @@ type Player struct {
 
 	// Stack is the player's stack at hand start.
 	Stack Amount
+
+	// Status is whether the player is dealt in.
+	Status Status
+}
+
+// Status is the status of a seated player at the start of a hand.
+type Status int
+
+// Statuses
+const (
+	// Active players are dealt in.
+	Active Status = iota
+
+	// SittingOut players are not dealt in.
+	SittingOut
+
+	// MissedBlinds players are not dealt in, and sat out when the blinds
+	// passed them.
+	MissedBlinds
+
+	// DeadBlind players return after missing the blinds, and are dealt in
+	// after posting the big blind and a dead small blind.
+	DeadBlind
+)
+
+// statusNames are the names of the statuses.
+var statusNames = []string{"active", "sitting out", "missed blinds", "dead blind"}
+
+// String returns the name of the status.
+func (s Status) String() string {
+	if s < 0 || int(s) >= len(statusNames) {
+		return fmt.Sprintf("Status(%d)", int(s))
+	}
+	return statusNames[s]
+}
+
+// IsDealtIn returns whether the player is dealt in.
+func (p *Player) IsDealtIn() bool {
+	return p.Status == Active || p.Status == DeadBlind
 }
 
 // UnmarshalJSON parses a player from JSON.
@@ -38,16 +78,10 @@ func (p *Player) UnmarshalJSON(b []byte) error {
 // PlayerPositions are 1-indexed, and a value of 0 means 'no player'.
 type PlayerPosition int
 
-// Player returns the player given his position.
+// Player returns the player given his position, or nil if the seat is empty.
 // h is the hand in question.
 func (p PlayerPosition) Player(h *Hand) *Player {
-
-	// Perform boundary check.
-	if p < 1 || int(p) > len(h.Players) {
-		return nil
-	}
-
-	return &h.Players[p-1]
+	return h.Players[p]
 }
 
 // NextPlayerPosition returns the next player position at the table.
@@ -0,0 +1,275 @@
+package poker
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Position is a named position at the table, relative to the button.
+type Position int
+
+// Positions, in the order they act preflop after the blinds
+const (
+	// NoPosition is the position of an empty seat.
+	NoPosition Position = iota
+
+	// UTG is under the gun, the first to act preflop.
+	UTG
+
+	// UTG1 and UTG2 follow UTG at tables of 8 or more players.
+	UTG1
+	UTG2
+
+	// MP is the middle position.
+	MP
+
+	// LJ is the lojack, left of the hijack.
+	LJ
+
+	// HJ is the hijack, left of the cutoff.
+	HJ
+
+	// CO is the cutoff, right of the button.
+	CO
+
+	// BTN is the button. Heads-up, the button posts the small blind.
+	BTN
+
+	// SB is the small blind.
+	SB
+
+	// BB is the big blind.
+	BB
+)
+
+// positionNames are the names of the positions.
+var positionNames = []string{"", "UTG", "UTG+1", "UTG+2", "MP", "LJ", "HJ", "CO", "BTN",
+	"SB", "BB"}
+
+// middlePositions are the positions between the big blind and the button by
+// their number, i.e. middlePositions[n] are the positions of n players.
+var middlePositions = [][]Position{
+	{},
+	{CO},
+	{HJ, CO},
+	{UTG, HJ, CO},
+	{UTG, LJ, HJ, CO},
+	{UTG, UTG1, LJ, HJ, CO},
+	{UTG, UTG1, MP, LJ, HJ, CO},
+	{UTG, UTG1, UTG2, MP, LJ, HJ, CO},
+}
+
+// MaxPositions is the largest number of players whose positions are named.
+const MaxPositions = 10
+
+// String returns the name of the position, e.g. 'UTG+1'.
+func (p Position) String() string {
+	if p < 0 || int(p) >= len(positionNames) {
+		return fmt.Sprintf("Position(%d)", int(p))
+	}
+	return positionNames[p]
+}
+
+// IsBlind returns whether the position is one of the blinds. Heads-up, the
+// button also posts the small blind.
+func (p Position) IsBlind() bool {
+	return p == SB || p == BB
+}
+
+// ParsePosition parses the name of a position, e.g. 'BTN' or 'utg+1'.
+func ParsePosition(str string) (Position, error) {
+	for i := 1; i < len(positionNames); i++ {
+		if strings.EqualFold(str, positionNames[i]) {
+			return Position(i), nil
+		}
+	}
+	return NoPosition, fmt.Errorf("unknown position %q", str)
+}
+
+// MarshalJSON marshals the name of the position.
+func (p Position) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON parses a position from JSON.
+func (p *Position) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	var err error
+	*p, err = ParsePosition(str)
+	return err
+}
+
+// Positions returns the named positions of the occupied seats at a table of a
+// size. The blinds are the first two occupied seats after the button, skipping
+// empty seats, and the last occupied seat up to the button has the button.
+// Heads-up, the button posts the small blind and the other player the big
+// blind. It returns nil for more than MaxPositions players. Hand.Positions
+// names the positions of a hand from its recorded blinds instead, which need
+// not follow the button.
+func Positions(button PlayerPosition, size int, occupied []PlayerPosition) map[PlayerPosition]Position {
+
+	isOccupied := make(map[PlayerPosition]bool)
+	for _, pos := range occupied {
+		isOccupied[pos] = true
+	}
+
+	// Seats in the order they are dealt, ending with the button.
+	var seats []PlayerPosition
+	pos := button
+	if pos < 1 || int(pos) > size {
+		pos = PlayerPosition(size)
+	}
+	for i := 0; i < size; i++ {
+		pos = NextPlayerPosition(pos, size)
+		if isOccupied[pos] {
+			seats = append(seats, pos)
+		}
+	}
+
+	n := len(seats)
+	if n > MaxPositions {
+		return nil
+	}
+	positions := make(map[PlayerPosition]Position)
+	switch n {
+	case 0:
+	case 1:
+		positions[seats[0]] = BTN
+	case 2:
+		positions[seats[1]], positions[seats[0]] = BTN, BB
+	default:
+		positions[seats[0]], positions[seats[1]], positions[seats[n-1]] = SB, BB, BTN
+		for i, p := range middlePositions[n-3] {
+			positions[seats[i+2]] = p
+		}
+	}
+	return positions
+}
+
+// NextOccupied returns the next occupied seat after a seat, or 0 if no seat is
+// occupied.
+func (h *Hand) NextOccupied(pos PlayerPosition) PlayerPosition {
+	return h.nextSeat(pos, 1, h.seated)
+}
+
+// NextActive returns the next seat after a seat whose player is dealt in, or 0
+// if nobody is dealt in.
+func (h *Hand) NextActive(pos PlayerPosition) PlayerPosition {
+	return h.nextSeat(pos, 1, h.dealtIn)
+}
+
+// PreviousActive returns the previous seat before a seat whose player is dealt
+// in, or 0 if nobody is dealt in.
+func (h *Hand) PreviousActive(pos PlayerPosition) PlayerPosition {
+	return h.nextSeat(pos, -1, h.dealtIn)
+}
+
+// nextSeat returns the next seat in a direction which satisfies a condition,
+// or 0 if none does.
+func (h *Hand) nextSeat(pos PlayerPosition, dir int, ok func(PlayerPosition) bool) PlayerPosition {
+	size := h.TableSize()
+	for i := 0; i < size; i++ {
+		if dir > 0 {
+			pos = NextPlayerPosition(pos, size)
+		} else {
+			pos = PreviousPlayerPosition(pos, size)
+		}
+		if ok(pos) {
+			return pos
+		}
+	}
+	return 0
+}
+
+// Seats returns the seats whose players are dealt in, in seat order.
+func (h *Hand) Seats() []PlayerPosition {
+	var seats []PlayerPosition
+	for _, pos := range h.Occupied() {
+		if h.dealtIn(pos) {
+			seats = append(seats, pos)
+		}
+	}
+	return seats
+}
+
+// Occupied returns the seats which have a player, in seat order.
+func (h *Hand) Occupied() []PlayerPosition {
+	var seats []PlayerPosition
+	for pos := range h.Players {
+		if h.seated(pos) {
+			seats = append(seats, pos)
+		}
+	}
+	sort.Slice(seats, func(i, j int) bool { return seats[i] < seats[j] })
+	return seats
+}
+
+// TableSize returns the size of the table, or the highest occupied seat if it
+// is larger.
+func (h *Hand) TableSize() int {
+	size := h.Table.Size
+	for pos := range h.Players {
+		if h.seated(pos) && int(pos) > size {
+			size = int(pos)
+		}
+	}
+	return size
+}
+
+// Sit seats a player, replacing any player in the seat.
+func (h *Hand) Sit(pos PlayerPosition, p Player) {
+	if h.Players == nil {
+		h.Players = make(map[PlayerPosition]*Player)
+	}
+	h.Players[pos] = &p
+}
+
+// Positions returns the named positions of the players dealt in. The blinds
+// are the recorded blinds, which need not follow the button, e.g. when the
+// small blind is dead. The other players are named by the order they act in
+// from the big blind, and the last of them has the button. Hands without a
+// big blind are named from the button, as by Positions. It returns nil if
+// there are more players than positions.
+func (h *Hand) Positions() map[PlayerPosition]Position {
+
+	size := h.TableSize()
+	if !h.dealtIn(h.BigBlind) {
+		return Positions(h.Button, size, h.Seats())
+	}
+	seats := h.Seats()
+	if len(seats) > MaxPositions {
+		return nil
+	}
+
+	// Heads-up, the button posts the small blind.
+	positions := map[PlayerPosition]Position{h.BigBlind: BB}
+	if len(seats) == 2 {
+		positions[h.NextActive(h.BigBlind)] = BTN
+		return positions
+	}
+
+	var others []PlayerPosition
+	for pos := h.NextActive(h.BigBlind); pos != h.BigBlind; pos = h.NextActive(pos) {
+		if pos == h.SmallBlind {
+			positions[pos] = SB
+		} else {
+			others = append(others, pos)
+		}
+	}
+	if len(others) == 0 {
+		return positions
+	}
+	if len(others) > len(middlePositions) {
+		return nil
+	}
+	positions[others[len(others)-1]] = BTN
+	for i, p := range middlePositions[len(others)-1] {
+		positions[others[i]] = p
+	}
+	return positions
+}
@@ -0,0 +1,209 @@
+package poker
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// Positions() /////////////////////////////////////////////////////////////////
+
+func TestPositions(t *testing.T) {
+
+	type testPair struct {
+		button   PlayerPosition
+		size     int
+		occupied []PlayerPosition
+		output   map[PlayerPosition]Position
+	}
+	tests := []testPair{
+		// Heads-up, the button posts the small blind.
+		{1, 2, []PlayerPosition{1, 2}, map[PlayerPosition]Position{1: BTN, 2: BB}},
+		{4, 6, []PlayerPosition{1, 4}, map[PlayerPosition]Position{4: BTN, 1: BB}},
+		{3, 3, []PlayerPosition{1, 2, 3}, map[PlayerPosition]Position{3: BTN, 1: SB, 2: BB}},
+		{2, 4, []PlayerPosition{1, 2, 3, 4}, map[PlayerPosition]Position{
+			2: BTN, 3: SB, 4: BB, 1: CO}},
+		// Empty seats are skipped.
+		{4, 6, []PlayerPosition{1, 2, 4, 6}, map[PlayerPosition]Position{
+			4: BTN, 6: SB, 1: BB, 2: CO}},
+		{6, 6, []PlayerPosition{1, 2, 3, 4, 5, 6}, map[PlayerPosition]Position{
+			6: BTN, 1: SB, 2: BB, 3: UTG, 4: HJ, 5: CO}},
+		{1, 9, []PlayerPosition{1, 2, 3, 4, 5, 6, 7, 8, 9}, map[PlayerPosition]Position{
+			1: BTN, 2: SB, 3: BB, 4: UTG, 5: UTG1, 6: MP, 7: LJ, 8: HJ, 9: CO}},
+		{10, 10, []PlayerPosition{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, map[PlayerPosition]Position{
+			10: BTN, 1: SB, 2: BB, 3: UTG, 4: UTG1, 5: UTG2, 6: MP, 7: LJ, 8: HJ, 9: CO}},
+		// The button's seat is empty.
+		{3, 6, []PlayerPosition{1, 2, 4, 5}, map[PlayerPosition]Position{
+			2: BTN, 4: SB, 5: BB, 1: CO}},
+		{1, 6, nil, map[PlayerPosition]Position{}},
+	}
+
+	for _, test := range tests {
+		positions := Positions(test.button, test.size, test.occupied)
+		if !reflect.DeepEqual(positions, test.output) {
+			t.Errorf("For button %v and seats %v expected %v, got %v", test.button,
+				test.occupied, test.output, positions)
+		}
+	}
+
+	var seats []PlayerPosition
+	for i := 1; i <= 11; i++ {
+		seats = append(seats, PlayerPosition(i))
+	}
+	if positions := Positions(1, 11, seats); positions != nil {
+		t.Errorf("Expected no positions for 11 players, got %v", positions)
+	}
+}
+
+func TestHandPositions(t *testing.T) {
+
+	type testPair struct {
+		seats  string
+		blinds Blinds
+		output map[PlayerPosition]Position
+	}
+	tests := []testPair{
+		// An empty seat between the button and the small blind.
+		{"AAA.AA", Blinds{3, 5, 6}, map[PlayerPosition]Position{
+			5: SB, 6: BB, 1: HJ, 2: CO, 3: BTN}},
+		// An empty seat between the blinds.
+		{"AA.A.A", Blinds{1, 2, 4}, map[PlayerPosition]Position{
+			2: SB, 4: BB, 6: CO, 1: BTN}},
+		// The small blind is dead.
+		{"AA.AAA", Blinds{2, 0, 4}, map[PlayerPosition]Position{
+			4: BB, 5: UTG, 6: HJ, 1: CO, 2: BTN}},
+		// The button is dead, so the player right of it acts last.
+		{"AA.AA", Blinds{3, 4, 5}, map[PlayerPosition]Position{
+			4: SB, 5: BB, 1: CO, 2: BTN}},
+		// Heads-up, the button posts the small blind.
+		{"A..A", Blinds{4, 4, 1}, map[PlayerPosition]Position{4: BTN, 1: BB}},
+		{"ASA", Blinds{3, 3, 1}, map[PlayerPosition]Position{3: BTN, 1: BB}},
+		// Without blinds, positions are named from the button.
+		{"AAA", Blinds{1, 0, 0}, map[PlayerPosition]Position{1: BTN, 2: SB, 3: BB}},
+	}
+
+	for _, test := range tests {
+		h := &Hand{Table: Table{Size: len(test.seats)}, Players: testSeats(test.seats),
+			Button: test.blinds.Button, SmallBlind: test.blinds.SmallBlind,
+			BigBlind: test.blinds.BigBlind}
+		if positions := h.Positions(); !reflect.DeepEqual(positions, test.output) {
+			t.Errorf("For %v with %v expected %v, got %v", test.seats, test.blinds,
+				test.output, positions)
+		}
+	}
+
+	h, err := ParseHand(testHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[PlayerPosition]Position{4: BTN, 6: SB, 1: BB, 2: CO}
+	if positions := h.Positions(); !reflect.DeepEqual(positions, expected) {
+		t.Errorf("Expected %v, got %v", expected, positions)
+	}
+	if pos := h.NextOccupied(4); pos != 6 {
+		t.Errorf("Expected 6, got %v", pos)
+	}
+	if pos := h.NextOccupied(6); pos != 1 {
+		t.Errorf("Expected 1, got %v", pos)
+	}
+}
+
+// Hand.NextActive() //////////////////////////////////////////////////////////
+
+func TestNextActive(t *testing.T) {
+
+	h, err := ParseHand(testSittingOutHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Players[3].Status != SittingOut || h.Players[5].Status != DeadBlind {
+		t.Errorf("Unexpected statuses %v and %v", h.Players[3].Status, h.Players[5].Status)
+	}
+
+	type testPair struct {
+		pos      PlayerPosition
+		next     PlayerPosition
+		previous PlayerPosition
+	}
+	tests := []testPair{
+		{1, 2, 5},
+		{2, 4, 1},
+		{3, 4, 2},
+		{5, 1, 4},
+		{6, 1, 5},
+	}
+
+	for _, test := range tests {
+		if pos := h.NextActive(test.pos); pos != test.next {
+			t.Errorf("For %v expected next %v, got %v", test.pos, test.next, pos)
+		}
+		if pos := h.PreviousActive(test.pos); pos != test.previous {
+			t.Errorf("For %v expected previous %v, got %v", test.pos, test.previous, pos)
+		}
+	}
+	if pos := h.NextOccupied(2); pos != 3 {
+		t.Errorf("Expected 3, got %v", pos)
+	}
+
+	expected := map[PlayerPosition]Position{1: BTN, 2: SB, 4: BB, 5: CO}
+	if positions := h.Positions(); !reflect.DeepEqual(positions, expected) {
+		t.Errorf("Expected %v, got %v", expected, positions)
+	}
+}
+
+// Hand.Occupied() /////////////////////////////////////////////////////////////
+
+func TestOccupied(t *testing.T) {
+
+	// A player without a name or chips is seated all the same.
+	h := &Hand{Table: Table{Size: 9}}
+	h.Sit(7, Player{Name: "alice", Stack: 100})
+	h.Sit(2, Player{})
+	h.Sit(4, Player{Name: "bob", Stack: 100, Status: SittingOut})
+
+	if seats := h.Occupied(); !reflect.DeepEqual(seats, []PlayerPosition{2, 4, 7}) {
+		t.Errorf("Expected seats 2, 4 and 7, got %v", seats)
+	}
+	if seats := h.Seats(); !reflect.DeepEqual(seats, []PlayerPosition{2, 7}) {
+		t.Errorf("Expected seats 2 and 7, got %v", seats)
+	}
+	if pos := h.NextActive(7); pos != 2 {
+		t.Errorf("Expected 2, got %v", pos)
+	}
+	if size := h.TableSize(); size != 9 {
+		t.Errorf("Expected 9, got %v", size)
+	}
+	h.Sit(10, Player{Name: "carol", Stack: 100})
+	if size := h.TableSize(); size != 10 {
+		t.Errorf("Expected 10, got %v", size)
+	}
+}
+
+// ParsePosition() /////////////////////////////////////////////////////////////
+
+func TestParsePosition(t *testing.T) {
+
+	for p := UTG; p <= BB; p++ {
+		parsed, err := ParsePosition(p.String())
+		if err != nil || parsed != p {
+			t.Errorf("For %v expected %v, got %v (%v)", p, int(p), parsed, err)
+		}
+		data, err := json.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var again Position
+		if err := json.Unmarshal(data, &again); err != nil || again != p {
+			t.Errorf("For %s expected %v, got %v (%v)", data, p, again, err)
+		}
+	}
+	if p, err := ParsePosition("utg+1"); err != nil || p != UTG1 {
+		t.Errorf("Expected UTG+1, got %v (%v)", p, err)
+	}
+	if _, err := ParsePosition("EP"); err == nil {
+		t.Errorf("Expected error")
+	}
+	if !SB.IsBlind() || BTN.IsBlind() {
+		t.Errorf("Expected only the blinds to be blinds")
+	}
+}
@@ -0,0 +1,216 @@
+package poker
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/whomever000/poker-common/card"
+)
+
+// Pot is the main pot or a side pot.
+type Pot struct {
+
+	// Amount is the size of the pot.
+	Amount Amount
+
+	// Eligible are the players who may win the pot, in seat order.
+	Eligible []PlayerPosition
+
+	// Winners are the players who won the pot, and their shares.
+	Winners []Share
+}
+
+// Share is a player's share of a pot.
+type Share struct {
+
+	// Position is the winner's position.
+	Position PlayerPosition
+
+	// Amount is the amount won.
+	Amount Amount
+}
+
+// PotName returns the name of the i'th pot of a hand, i.e. 'main pot' or
+// 'side pot-i'.
+func PotName(i int) string {
+	if i == 0 {
+		return "main pot"
+	}
+	return fmt.Sprintf("side pot-%v", i)
+}
+
+// Split splits the pot evenly between the winners. Odd chips go to the winners
+// in the order they are given, which should be clockwise from the button.
+func (p *Pot) Split(winners ...PlayerPosition) {
+	p.Winners = nil
+	if len(winners) == 0 {
+		return
+	}
+
+	share := p.Amount / Amount(len(winners))
+	odd := p.Amount % Amount(len(winners))
+	for i := 0; i < len(winners); i++ {
+		amount := share
+		if Amount(i) < odd {
+			amount++
+		}
+		p.Winners = append(p.Winners, Share{winners[i], amount})
+	}
+}
+
+// IsEligible returns whether a player may win the pot.
+func (p *Pot) IsEligible(pos PlayerPosition) bool {
+	for i := 0; i < len(p.Eligible); i++ {
+		if p.Eligible[i] == pos {
+			return true
+		}
+	}
+	return false
+}
+
+// SidePots derives the main pot and side pots of a hand from its actions and
+// the players' stacks. Uncalled bets are returned to the player who made them.
+// The pots have no winners.
+func SidePots(h *Hand) ([]Pot, error) {
+
+	r := Replay(h)
+	for r.Next() {
+	}
+	if r.Err() != nil {
+		return nil, r.Err()
+	}
+
+	return r.state.Pots(), nil
+}
+
+// Pots returns the main pot and side pots in the current state, excluding any
+// uncalled bet. Dead blinds, i.e. the big blind ante and dead small blinds, are
+// in the main pot. The pots have no winners.
+func (s *TableState) Pots() []Pot {
+
+	invested := copyAmounts(s.Invested)
+	if pos, amount := s.Uncalled(); amount > 0 {
+		invested[pos] -= amount
+	}
+	var dead Amount
+	for pos, amount := range s.dead {
+		invested[pos] -= amount
+		dead += amount
+	}
+
+	// Each distinct amount invested by a player in the hand caps a pot.
+	var levels []Amount
+	for _, pos := range s.InHand {
+		levels = append(levels, invested[pos])
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+
+	var pots []Pot
+	var prev Amount
+	for _, level := range levels {
+		if level == prev {
+			continue
+		}
+
+		var pot Pot
+		for _, amount := range invested {
+			pot.Amount += minAmount(amount, level) - minAmount(amount, prev)
+		}
+		for _, pos := range s.InHand {
+			if invested[pos] >= level {
+				pot.Eligible = append(pot.Eligible, pos)
+			}
+		}
+		prev = level
+
+		// A pot with a single eligible player holds chips which the other
+		// players could not match, e.g. those put in by players who folded
+		// after a short player went all in. Only that player may win them.
+		pots = append(pots, pot)
+	}
+
+	// Chips put in beyond the largest level by folded players.
+	var over Amount
+	for _, amount := range invested {
+		if amount > prev {
+			over += amount - prev
+		}
+	}
+	if over > 0 && len(pots) > 0 {
+		pots[len(pots)-1].Amount += over
+	}
+	if dead > 0 && len(pots) > 0 {
+		pots[0].Amount += dead
+	}
+
+	return pots
+}
+
+// Settle awards the pots of a finished hand and sets its result. If more than
+// one player is left in the hand, their hole cards decide the winners and are
+// recorded as shown. No rake is taken.
+func (h *Hand) Settle(holeCards map[PlayerPosition][]card.Card) error {
+
+	r := Replay(h)
+	for r.Next() {
+	}
+	if r.Err() != nil {
+		return r.Err()
+	}
+	s := r.state
+
+	result := &Result{Pots: s.Pots()}
+	strengths := make(map[PlayerPosition]card.Strength)
+	if len(s.InHand) > 1 {
+		board := h.board()
+		for _, pos := range s.clockwise(s.InHand) {
+			cards := holeCards[pos]
+			if len(cards) == 0 || len(cards)+len(board) < 5 {
+				return fmt.Errorf("seat %v has no cards for the showdown", pos)
+			}
+			strengths[pos] = card.Evaluate(append(append([]card.Card(nil), cards...),
+				board...)...)
+			result.ShowDowns = append(result.ShowDowns, PlayerCards{pos, cards})
+		}
+	}
+
+	for i := 0; i < len(result.Pots); i++ {
+		pot := &result.Pots[i]
+		var winners []PlayerPosition
+		for _, pos := range s.clockwise(pot.Eligible) {
+			switch {
+			case len(winners) == 0 || strengths[pos] == strengths[winners[0]]:
+				winners = append(winners, pos)
+			case strengths[pos] > strengths[winners[0]]:
+				winners = []PlayerPosition{pos}
+			}
+		}
+		pot.Split(winners...)
+	}
+
+	h.Result = result
+	return nil
+}
+
+// clockwise returns positions in clockwise order, starting left of the button.
+func (s *TableState) clockwise(positions []PlayerPosition) []PlayerPosition {
+	sorted := append([]PlayerPosition(nil), positions...)
+	if s.tableSize < 1 {
+		return sorted
+	}
+	distance := func(pos PlayerPosition) int {
+		return (int(pos-s.button) - 1 + s.tableSize) % s.tableSize
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return distance(sorted[i]) < distance(sorted[j])
+	})
+	return sorted
+}
+
+// minAmount returns the smaller of two amounts.
+func minAmount(a, b Amount) Amount {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,153 @@
+package poker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/whomever000/poker-common/card"
+)
+
+// SidePots() //////////////////////////////////////////////////////////////////
+
+func TestSidePots(t *testing.T) {
+
+	h := testHand()
+	pots, err := SidePots(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Pot{{201, []PlayerPosition{1, 3}, nil}}
+	if !reflect.DeepEqual(pots, expected) {
+		t.Errorf("Expected %v, got %v", expected, pots)
+	}
+
+	// Seat 2 is all in for less than seat 3, who is all in for less than
+	// seat 1. Seat 1's last raise is partly uncalled.
+	h.Players = map[PlayerPosition]*Player{1: {Name: "alice", Stack: 200},
+		2: {Name: "bob", Stack: 20}, 3: {Name: "carol", Stack: 100}}
+	h.Rounds = []Round{{nil, 0, []PlayerAction{
+		{1, NewRaiseAction(150)},
+		{2, NewCallAction(-1)},
+		{3, NewCallAction(98)},
+	}}}
+
+	pots, err = SidePots(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []Pot{
+		{60, []PlayerPosition{1, 2, 3}, nil},
+		{160, []PlayerPosition{1, 3}, nil},
+	}
+	if !reflect.DeepEqual(pots, expected) {
+		t.Errorf("Expected %v, got %v", expected, pots)
+	}
+}
+
+func TestSidePotsSingleEligible(t *testing.T) {
+
+	// Seat 3 is all in for less than the raise, and seat 1 folds to a bet of
+	// seat 2 on the flop. Seat 3 cannot win the chips seat 1 put in beyond
+	// seat 3's stack.
+	h := testHand()
+	h.Players = map[PlayerPosition]*Player{1: {Name: "alice", Stack: 1000},
+		2: {Name: "bob", Stack: 1000}, 3: {Name: "carol", Stack: 100}}
+	h.Rounds = []Round{
+		{nil, 0, []PlayerAction{
+			{1, NewRaiseAction(500)},
+			{2, NewCallAction(499)},
+			{3, NewCallAction(-1)},
+		}},
+		{[]card.Card{card.CardKs, card.Card2h, card.Card3s}, 0, []PlayerAction{
+			{2, NewBetAction(200)},
+			{1, NewFoldAction()},
+		}},
+	}
+	h.Result = nil
+
+	pots, err := SidePots(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Pot{
+		{300, []PlayerPosition{2, 3}, nil},
+		{800, []PlayerPosition{2}, nil},
+	}
+	if !reflect.DeepEqual(pots, expected) {
+		t.Errorf("Expected %v, got %v", expected, pots)
+	}
+
+	cards := map[PlayerPosition][]card.Card{
+		2: {card.Card7c, card.Card8d},
+		3: {card.CardAh, card.CardAd},
+	}
+	if err := h.Settle(cards); err != nil {
+		t.Fatal(err)
+	}
+	if w := h.Result.Winnings(); w[3] != 300 || w[2] != 800 {
+		t.Errorf("Expected 300 for seat 3 and 800 for seat 2, got %v", w)
+	}
+}
+
+func TestSidePotsBigBlindAnte(t *testing.T) {
+
+	// The big blind ante is dead money in the main pot, which a player all
+	// in for less than the big blind put in may win.
+	h := testHand()
+	h.Table.Stakes.BigBlindAnte = 3
+	h.Players = map[PlayerPosition]*Player{1: {Name: "alice", Stack: 50},
+		2: {Name: "bob", Stack: 200}, 3: {Name: "carol", Stack: 200}}
+	h.Rounds = []Round{{nil, 0, []PlayerAction{
+		{1, NewRaiseAction(-1)},
+		{2, NewFoldAction()},
+		{3, NewCallAction(48)},
+	}}}
+	h.Result = nil
+
+	pots, err := SidePots(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Pot{{104, []PlayerPosition{1, 3}, nil}}
+	if !reflect.DeepEqual(pots, expected) {
+		t.Errorf("Expected %v, got %v", expected, pots)
+	}
+}
+
+// Split() /////////////////////////////////////////////////////////////////////
+
+func TestPotSplit(t *testing.T) {
+
+	pot := Pot{Amount: 7}
+	pot.Split(3, 1)
+
+	expected := []Share{{3, 4}, {1, 3}}
+	if !reflect.DeepEqual(pot.Winners, expected) {
+		t.Errorf("Expected %v, got %v", expected, pot.Winners)
+	}
+}
+
+// Settle() ////////////////////////////////////////////////////////////////////
+
+func TestSettle(t *testing.T) {
+
+	h := testHand()
+	expected := h.Result
+	h.Result = nil
+
+	cards := make(map[PlayerPosition][]card.Card)
+	for _, sd := range expected.ShowDowns {
+		cards[sd.Position] = sd.Cards
+	}
+	if err := h.Settle(cards); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(h.Result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, h.Result)
+	}
+
+	// Without cards there is no showdown.
+	if err := h.Settle(nil); err == nil {
+		t.Errorf("Expected error for missing cards")
+	}
+}
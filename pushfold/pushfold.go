@@ -0,0 +1,404 @@
+// Package pushfold computes Nash equilibrium ranges for short stacks, who
+// either fold or go all-in preflop. Players facing an all-in either fold or
+// call, and once one player has called, the others fold.
+//
+// Equities come from equity.Matrix, so card removal between the players is
+// ignored. The objective is either chips or, with tournament payouts, the ICM
+// equity of the players at the table.
+package pushfold
+
+import (
+	"fmt"
+
+	poker "github.com/whomever000/poker-common"
+	"github.com/whomever000/poker-common/equity"
+	"github.com/whomever000/poker-common/icm"
+)
+
+// Iterations is the number of rounds of fictitious play Solve plays, in which
+// every player's range moves towards the best response to the others.
+const Iterations = 300
+
+// Spot is a push/fold situation at the start of a hand.
+type Spot struct {
+
+	// Table is the table, whose stakes are the blinds and ante.
+	Table poker.Table
+
+	// Stacks are the stacks by seat before the blinds and antes, i.e.
+	// Stacks[0] is in seat 1. Empty seats and players sitting out have no
+	// chips.
+	Stacks []poker.Amount
+
+	// Button is the button's seat.
+	Button poker.PlayerPosition
+
+	// Payouts are the tournament payouts for an ICM objective, or nil for a
+	// chip objective.
+	Payouts poker.Payouts
+}
+
+// NewSpot creates the spot at the start of a hand, with the payouts of its
+// tournament, if any.
+func NewSpot(h *poker.Hand) *Spot {
+	s := &Spot{Table: h.Table, Button: h.Button}
+	s.Stacks = make([]poker.Amount, h.TableSize())
+	for _, pos := range h.Seats() {
+		s.Stacks[pos-1] = pos.Player(h).Stack
+	}
+	if h.Tournament != nil {
+		s.Payouts = h.Tournament.Payouts
+	}
+	return s
+}
+
+// HeadsUp creates a heads-up spot in which both players have the same stack,
+// with a chip objective.
+func HeadsUp(stakes poker.Stakes, stack poker.Amount) *Spot {
+	return &Spot{
+		Table:  poker.Table{Stakes: stakes, Size: 2},
+		Stacks: []poker.Amount{stack, stack},
+		Button: 1,
+	}
+}
+
+// Solution are the push/fold ranges of a spot.
+type Solution struct {
+
+	// Push are the ranges each position goes all-in with when everyone before
+	// it has folded.
+	Push map[poker.PlayerPosition]equity.Range
+
+	// Call are the ranges each position calls an all-in with, by the position
+	// which went all-in.
+	Call map[poker.PlayerPosition]map[poker.PlayerPosition]equity.Range
+}
+
+// solver holds the state of Solve. Players are indexed in the order they act,
+// and the big blind acts last.
+type solver struct {
+	positions []poker.PlayerPosition
+
+	// steal are the values of a player winning the blinds and antes, and win
+	// of a player winning an all-in against another.
+	steal [][]float64
+	win   [][][]float64
+
+	push [][]float64
+	call [][][]float64
+}
+
+// Solve computes the push/fold ranges of a spot.
+func Solve(spot *Spot) (*Solution, error) {
+
+	s, err := newSolver(spot)
+	if err != nil {
+		return nil, err
+	}
+
+	for k := 1; k <= Iterations; k++ {
+		push, call := s.bestResponses()
+		step := 1 / float64(k+1)
+		for i := 0; i < len(s.push); i++ {
+			for h := 0; h < equity.StartingHands; h++ {
+				s.push[i][h] += (push[i][h] - s.push[i][h]) * step
+				for j := i + 1; j < len(s.positions); j++ {
+					s.call[i][j][h] += (call[i][j][h] - s.call[i][j][h]) * step
+				}
+			}
+		}
+	}
+
+	// The ranges are the best responses to the average strategies.
+	push, call := s.bestResponses()
+	sol := &Solution{
+		Push: make(map[poker.PlayerPosition]equity.Range),
+		Call: make(map[poker.PlayerPosition]map[poker.PlayerPosition]equity.Range),
+	}
+	for i := 0; i < len(s.positions); i++ {
+		sol.Call[s.positions[i]] = make(map[poker.PlayerPosition]equity.Range)
+	}
+	for i := 0; i < len(push); i++ {
+		sol.Push[s.positions[i]] = toRange(push[i])
+		for j := i + 1; j < len(s.positions); j++ {
+			sol.Call[s.positions[j]][s.positions[i]] = toRange(call[i][j])
+		}
+	}
+	return sol, nil
+}
+
+// newSolver finds the players of a spot and the values of each outcome.
+func newSolver(spot *Spot) (*solver, error) {
+
+	size := spot.Table.Size
+	if len(spot.Stacks) > size {
+		size = len(spot.Stacks)
+	}
+	stack := func(pos poker.PlayerPosition) poker.Amount {
+		if int(pos) > len(spot.Stacks) {
+			return 0
+		}
+		return spot.Stacks[pos-1]
+	}
+
+	// Players act from the left of the big blind, and the big blind acts last.
+	// Heads-up, the button is the small blind.
+	var seats []poker.PlayerPosition
+	pos := spot.Button
+	for i := 0; i < size; i++ {
+		pos = poker.NextPlayerPosition(pos, size)
+		if stack(pos) > 0 {
+			seats = append(seats, pos)
+		}
+	}
+	n := len(seats)
+	if n < 2 {
+		return nil, fmt.Errorf("pushfold: expected at least 2 players, got %v", n)
+	}
+	s := &solver{positions: make([]poker.PlayerPosition, n)}
+	if n == 2 {
+		s.positions[0], s.positions[1] = seats[1], seats[0]
+	} else {
+		copy(s.positions, seats[2:])
+		copy(s.positions[n-2:], seats[:2])
+	}
+
+	// Stakes are posted before anyone acts.
+	stakes := spot.Table.Stakes
+	stacks := make([]poker.Amount, n)
+	antes := make([]poker.Amount, n)
+	blinds := make([]poker.Amount, n)
+	var dead poker.Amount
+	for i, pos := range s.positions {
+		stacks[i] = stack(pos)
+		antes[i] = min(stakes.Ante, stacks[i])
+		dead += antes[i]
+	}
+
+	// The big blind ante is dead money posted by the big blind.
+	bbAnte := min(stakes.BigBlindAnte, stacks[n-1]-antes[n-1])
+	antes[n-1] += bbAnte
+	dead += bbAnte
+
+	blinds[n-2] = min(stakes.SmallBlind, stacks[n-2]-antes[n-2])
+	blinds[n-1] = min(stakes.BigBlind, stacks[n-1]-antes[n-1])
+	for i := 0; i < n; i++ {
+		if stacks[i]-antes[i]-blinds[i] <= 0 {
+			return nil, fmt.Errorf("pushfold: seat %v is all-in before acting", s.positions[i])
+		}
+	}
+
+	value := func(final []poker.Amount) []float64 {
+		if spot.Payouts != nil {
+			return icm.Equity(final, spot.Payouts)
+		}
+		v := make([]float64, n)
+		for i := 0; i < n; i++ {
+			v[i] = float64(final[i])
+		}
+		return v
+	}
+	posted := func() []poker.Amount {
+		final := make([]poker.Amount, n)
+		for i := 0; i < n; i++ {
+			final[i] = stacks[i] - antes[i] - blinds[i]
+		}
+		return final
+	}
+
+	s.steal = make([][]float64, n)
+	s.win = make([][][]float64, n)
+	for i := 0; i < n; i++ {
+		final := posted()
+		final[i] += dead + blinds[i]
+		for j := 0; j < n; j++ {
+			if j != i {
+				final[i] += blinds[j]
+			}
+		}
+		s.steal[i] = value(final)
+
+		s.win[i] = make([][]float64, n)
+		for j := 0; j < n; j++ {
+			if j == i {
+				continue
+			}
+			// Both players put in the smaller of their stacks, and the
+			// winner also wins the blinds and antes of the others.
+			final := posted()
+			live := min(stacks[i]-antes[i], stacks[j]-antes[j])
+			final[i] = stacks[i] - antes[i] + live + dead
+			final[j] = stacks[j] - antes[j] - live
+			for k := 0; k < n; k++ {
+				if k != i && k != j {
+					final[i] += blinds[k]
+				}
+			}
+			s.win[i][j] = value(final)
+		}
+	}
+
+	// Every range starts with half of every hand.
+	s.push = make([][]float64, n-1)
+	s.call = make([][][]float64, n-1)
+	for i := 0; i < n-1; i++ {
+		s.push[i] = half()
+		s.call[i] = make([][]float64, n)
+		for j := i + 1; j < n; j++ {
+			s.call[i][j] = half()
+		}
+	}
+	return s, nil
+}
+
+// toRange converts the weights of each starting hand to a range.
+func toRange(weights []float64) equity.Range {
+	var r equity.Range
+	copy(r[:], weights)
+	return r
+}
+
+// half returns a range with half of every hand.
+func half() []float64 {
+	r := make([]float64, equity.StartingHands)
+	for h := 0; h < len(r); h++ {
+		r[h] = 0.5
+	}
+	return r
+}
+
+// combos are the number of combinations of each starting hand.
+var combos = func() []float64 {
+	c := make([]float64, equity.StartingHands)
+	for h := 0; h < len(c); h++ {
+		c[h] = float64(equity.StartingHand(h).NumCombos())
+	}
+	return c
+}()
+
+// fraction returns the fraction of all hole cards in a range.
+func fraction(r []float64) float64 {
+	var f float64
+	for h := 0; h < len(r); h++ {
+		f += combos[h] * r[h]
+	}
+	return f / 1326
+}
+
+// against returns the equity of each starting hand against a range.
+func against(r []float64) []float64 {
+	m := equity.Matrix()
+	e := make([]float64, equity.StartingHands)
+	var total float64
+	for y := 0; y < len(r); y++ {
+		total += combos[y] * r[y]
+	}
+	if total == 0 {
+		return e
+	}
+	for h := 0; h < len(e); h++ {
+		var sum float64
+		for y := 0; y < len(r); y++ {
+			if r[y] > 0 {
+				sum += combos[y] * r[y] * m[h][y]
+			}
+		}
+		e[h] = sum / total
+	}
+	return e
+}
+
+// rangeEquity returns the equity of a range, given the equity of each of its
+// hands.
+func rangeEquity(r, e []float64) float64 {
+	var sum, total float64
+	for h := 0; h < len(r); h++ {
+		sum += combos[h] * r[h] * e[h]
+		total += combos[h] * r[h]
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// bestResponses returns the best push and call ranges against the current
+// ranges.
+func (s *solver) bestResponses() ([][]float64, [][][]float64) {
+
+	n := len(s.positions)
+	push := make([][]float64, n-1)
+	call := make([][][]float64, n-1)
+
+	// fold is the value of everyone folding to a player, which is a walk for
+	// the big blind.
+	fold := make([][]float64, n)
+	fold[n-1] = s.steal[n-1]
+
+	for i := n - 2; i >= 0; i-- {
+
+		// Each player after the all-in calls with their call range, unless
+		// someone called before.
+		pushEq := against(s.push[i])
+		callEq := make([][]float64, n)
+		called := make([]float64, n)
+		for j := i + 1; j < n; j++ {
+			callEq[j] = against(s.call[i][j])
+			called[j] = fraction(s.call[i][j])
+		}
+
+		// after[j] is the value once the players before j have folded to
+		// the all-in.
+		after := make([][]float64, n+1)
+		after[n] = s.steal[i]
+		for j := n - 1; j > i; j-- {
+			e := rangeEquity(s.push[i], callEq[j])
+			after[j] = mix(called[j], mix(e, s.win[i][j], s.win[j][i]), after[j+1])
+		}
+
+		push[i] = make([]float64, equity.StartingHands)
+		call[i] = make([][]float64, n)
+		for j := i + 1; j < n; j++ {
+			call[i][j] = make([]float64, equity.StartingHands)
+		}
+		for h := 0; h < equity.StartingHands; h++ {
+			ev := s.steal[i][i]
+			for j := n - 1; j > i; j-- {
+				e := callEq[j][h]
+				showdown := e*s.win[i][j][i] + (1-e)*s.win[j][i][i]
+				ev = called[j]*showdown + (1-called[j])*ev
+			}
+			if ev > fold[i+1][i] {
+				push[i][h] = 1
+			}
+
+			for j := i + 1; j < n; j++ {
+				e := pushEq[h]
+				if e*s.win[j][i][j]+(1-e)*s.win[i][j][j] > after[j+1][j] {
+					call[i][j][h] = 1
+				}
+			}
+		}
+
+		fold[i] = mix(fraction(s.push[i]), after[i+1], fold[i+1])
+	}
+	return push, call
+}
+
+// mix returns the values of one outcome with a probability, and of another
+// otherwise.
+func mix(p float64, a, b []float64) []float64 {
+	v := make([]float64, len(a))
+	for i := 0; i < len(a); i++ {
+		v[i] = p*a[i] + (1-p)*b[i]
+	}
+	return v
+}
+
+// min returns the smaller of two amounts.
+func min(a, b poker.Amount) poker.Amount {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,152 @@
+package pushfold
+
+import (
+	"testing"
+
+	poker "github.com/whomever000/poker-common"
+	"github.com/whomever000/poker-common/equity"
+)
+
+// hand parses a starting hand, or panics.
+func hand(str string) equity.StartingHand {
+	h, err := equity.ParseStartingHand(str)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// Solve() /////////////////////////////////////////////////////////////////////
+
+func TestHeadsUp(t *testing.T) {
+
+	stakes := poker.Stakes{SmallBlind: 50, BigBlind: 100}
+
+	type testPair struct {
+		stack            poker.Amount
+		ante             poker.Amount
+		minPush, maxPush float64
+		minCall, maxCall float64
+	}
+	tests := []testPair{
+		{500, 0, 0.65, 0.80, 0.50, 0.70},
+		{1000, 0, 0.52, 0.62, 0.33, 0.42},
+		{2000, 0, 0.35, 0.45, 0.17, 0.25},
+		{1000, 10, 0.55, 0.70, 0.36, 0.50},
+	}
+
+	for _, test := range tests {
+		stakes.Ante = test.ante
+		sol, err := Solve(HeadsUp(stakes, test.stack))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The button is the small blind and goes first.
+		push, call := sol.Push[1], sol.Call[2][1]
+		if len(sol.Push) != 1 || len(sol.Call[1]) != 0 {
+			t.Fatalf("For %v unexpected solution %+v", test.stack, sol)
+		}
+		if f := push.Fraction(); f < test.minPush || f > test.maxPush {
+			t.Errorf("For %v/%v expected to push %v-%v, got %v: %v", test.stack, test.ante,
+				test.minPush, test.maxPush, f, push)
+		}
+		if f := call.Fraction(); f < test.minCall || f > test.maxCall {
+			t.Errorf("For %v/%v expected to call %v-%v, got %v: %v", test.stack, test.ante,
+				test.minCall, test.maxCall, f, call)
+		}
+		if push[hand("AA")] != 1 || call[hand("AA")] != 1 || call[hand("72o")] != 0 {
+			t.Errorf("For %v/%v unexpected ranges %v and %v", test.stack, test.ante, push, call)
+		}
+	}
+}
+
+func TestBigBlindAnte(t *testing.T) {
+
+	// The big blind ante adds dead money to steal, so more hands are pushed.
+	stakes := poker.Stakes{SmallBlind: 50, BigBlind: 100}
+	without, err := Solve(HeadsUp(stakes, 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stakes.BigBlindAnte = 100
+	with, err := Solve(HeadsUp(stakes, 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, g := with.Push[1].Fraction(), without.Push[1].Fraction(); f <= g {
+		t.Errorf("Expected to push more than %v with a big blind ante, got %v", g, f)
+	}
+}
+
+func TestSolveICM(t *testing.T) {
+
+	// Three players on the bubble of a sit and go which pays two.
+	spot := &Spot{
+		Table:  poker.Table{Stakes: poker.Stakes{SmallBlind: 100, BigBlind: 200, Ante: 25}, Size: 6},
+		Stacks: []poker.Amount{0, 2500, 0, 4000, 3500, 0},
+		Button: 2,
+	}
+	chips, err := Solve(spot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spot.Payouts = poker.Payouts{6500, 3500}
+	icm, err := Solve(spot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The button goes first, the small blind is in seat 4 and the big blind
+	// in seat 5.
+	for _, sol := range []*Solution{chips, icm} {
+		if len(sol.Push) != 2 || len(sol.Call[5]) != 2 || len(sol.Call[4]) != 1 {
+			t.Fatalf("Unexpected solution %+v", sol)
+		}
+	}
+
+	// Calling is riskier on the bubble.
+	for _, pusher := range []poker.PlayerPosition{2, 4} {
+		c, i := chips.Call[5][pusher].Fraction(), icm.Call[5][pusher].Fraction()
+		if i >= c {
+			t.Errorf("Against %v expected to call less than %v with ICM, got %v", pusher, c, i)
+		}
+	}
+}
+
+func TestSolveInvalid(t *testing.T) {
+
+	stakes := poker.Stakes{SmallBlind: 50, BigBlind: 100}
+	if _, err := Solve(HeadsUp(stakes, 100)); err == nil {
+		t.Errorf("Expected error for a player all-in from the blinds")
+	}
+	spot := &Spot{Table: poker.Table{Stakes: stakes, Size: 2}, Stacks: []poker.Amount{1000}}
+	if _, err := Solve(spot); err == nil {
+		t.Errorf("Expected error for a single player")
+	}
+}
+
+// NewSpot() ///////////////////////////////////////////////////////////////////
+
+func TestNewSpot(t *testing.T) {
+
+	h := &poker.Hand{
+		Table:  poker.Table{Stakes: poker.Stakes{SmallBlind: 50, BigBlind: 100}, Size: 3},
+		Button: 3,
+		Players: map[poker.PlayerPosition]*poker.Player{1: {Name: "a", Stack: 800},
+			2: {Name: "b", Stack: 1200}, 3: {Name: "c", Stack: 1000}},
+		Tournament: &poker.Tournament{Payouts: poker.Payouts{1000}},
+	}
+	spot := NewSpot(h)
+	if len(spot.Stacks) != 3 || spot.Stacks[1] != 1200 || spot.Button != 3 ||
+		len(spot.Payouts) != 1 {
+		t.Errorf("Unexpected spot %+v", spot)
+	}
+	sol, err := Solve(spot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sol.Push[3]; !ok || len(sol.Call[2]) != 2 {
+		t.Errorf("Unexpected solution %+v", sol)
+	}
+}
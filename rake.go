@@ -0,0 +1,130 @@
+package poker
+
+import (
+	"fmt"
+	"math"
+)
+
+// RakeCap is the most rake taken from a hand dealt to a number of players.
+type RakeCap struct {
+
+	// Players is the least number of players dealt in for the cap to apply.
+	Players int
+
+	// Cap is the most rake taken.
+	Cap Amount
+}
+
+// RakeStructure is how much rake is taken from the pots of a hand.
+type RakeStructure struct {
+
+	// Percent is the percentage of the pot taken, rounded down to the cent.
+	Percent float64
+
+	// Caps are the caps by number of players, in increasing order of players.
+	// The cap of the most players not exceeding those dealt in applies. Hands
+	// with fewer players than the first cap are not capped.
+	Caps []RakeCap
+
+	// NoFlopNoDrop is whether hands which end before the flop are not raked.
+	NoFlopNoDrop bool
+}
+
+// Cap returns the most rake taken from a hand dealt to a number of players,
+// or 0 if it is not capped.
+func (r *RakeStructure) Cap(players int) Amount {
+	var cap Amount
+	for i := 0; i < len(r.Caps); i++ {
+		if r.Caps[i].Players <= players {
+			cap = r.Caps[i].Cap
+		}
+	}
+	return cap
+}
+
+// Rake returns the rake taken from a total pot of a hand dealt to a number of
+// players, which may have ended before the flop.
+func (r *RakeStructure) Rake(pot Amount, players int, flop bool) Amount {
+	if r.NoFlopNoDrop && !flop {
+		return 0
+	}
+	rake := Amount(math.Floor(float64(pot)*r.Percent/100 + 1e-9))
+	if cap := r.Cap(players); cap > 0 && rake > cap {
+		rake = cap
+	}
+	return rake
+}
+
+// HandRake returns the rake the structure takes from a finished hand, whose
+// total pot includes any rake and jackpot it records.
+func (r *RakeStructure) HandRake(h *Hand) (Amount, error) {
+	if h.Result == nil {
+		return 0, fmt.Errorf("hand %v has no result", h.HandID)
+	}
+	pot := h.Result.Rake + h.Result.Jackpot
+	for i := 0; i < len(h.Result.Pots); i++ {
+		pot += h.Result.Pots[i].Amount
+	}
+	return r.Rake(pot, len(h.Seats()), len(h.Rounds) > 1), nil
+}
+
+// Apply takes rake from the pots of a settled hand without rake, e.g. a
+// simulated hand. The rake is taken from the main pot first, and the pots are
+// split again between their winners.
+func (r *RakeStructure) Apply(h *Hand) error {
+
+	rake, err := r.HandRake(h)
+	if err != nil {
+		return err
+	}
+	h.Result.Rake += rake
+
+	for i := 0; i < len(h.Result.Pots) && rake > 0; i++ {
+		pot := &h.Result.Pots[i]
+		take := minAmount(rake, pot.Amount)
+		pot.Amount -= take
+		rake -= take
+
+		winners := make([]PlayerPosition, len(pot.Winners))
+		for j := 0; j < len(pot.Winners); j++ {
+			winners[j] = pot.Winners[j].Position
+		}
+		pot.Split(winners...)
+	}
+	return nil
+}
+
+// RakeLevel is the rake structure of stakes up to a big blind.
+type RakeLevel struct {
+
+	// BigBlind is the highest big blind the structure applies to.
+	BigBlind Amount
+
+	// Structure is the rake structure.
+	Structure RakeStructure
+}
+
+// RakeSchedule are the rake structures of a site by stakes.
+type RakeSchedule struct {
+
+	// Site is the site, as in the client of its hands.
+	Site string
+
+	// Levels are the structures in increasing order of big blind. Stakes
+	// above the last level use its structure.
+	Levels []RakeLevel
+}
+
+// Structure returns the rake structure of stakes, or nil if the schedule has
+// no levels.
+func (s *RakeSchedule) Structure(stakes Stakes) *RakeStructure {
+	if len(s.Levels) == 0 {
+		return nil
+	}
+	for i := 0; i < len(s.Levels); i++ {
+		if stakes.BigBlind <= s.Levels[i].BigBlind {
+			return &s.Levels[i].Structure
+		}
+	}
+	return &s.Levels[len(s.Levels)-1].Structure
+}
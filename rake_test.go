@@ -0,0 +1,120 @@
+package poker
+
+import (
+	"strings"
+	"testing"
+)
+
+// testRake is 5% capped at $0.50 heads-up and $1 with three or more players.
+var testRake = RakeStructure{
+	Percent:      5,
+	Caps:         []RakeCap{{2, 50}, {3, 100}},
+	NoFlopNoDrop: true,
+}
+
+// RakeStructure.Rake() ////////////////////////////////////////////////////////
+
+func TestRake(t *testing.T) {
+
+	type testPair struct {
+		pot     Amount
+		players int
+		flop    bool
+		rake    Amount
+	}
+	tests := []testPair{
+		{100, 6, true, 5},
+		{119, 6, true, 5},
+		{1000, 2, true, 50},
+		{1000, 3, true, 50},
+		{5000, 6, true, 100},
+		{5000, 6, false, 0},
+	}
+
+	for _, test := range tests {
+		if rake := testRake.Rake(test.pot, test.players, test.flop); rake != test.rake {
+			t.Errorf("For %v with %v players expected %v, got %v", test.pot, test.players,
+				test.rake, rake)
+		}
+	}
+
+	uncapped := RakeStructure{Percent: 4.5}
+	if rake := uncapped.Rake(100000, 1, false); rake != 4500 {
+		t.Errorf("Expected 4500, got %v", int(rake))
+	}
+}
+
+// RakeStructure.Apply() ///////////////////////////////////////////////////////
+
+func TestRakeApply(t *testing.T) {
+
+	h, err := ParseHand(testHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rake, err := testRake.HandRake(h)
+	if err != nil || rake != 80 {
+		t.Errorf("Expected 80, got %v (%v)", int(rake), err)
+	}
+
+	h.Result.Pots[0].Amount += h.Result.Rake
+	h.Result.Pots[0].Split(h.Result.Pots[0].Winners[0].Position)
+	h.Result.Rake = 0
+	if err := testRake.Apply(h); err != nil {
+		t.Fatal(err)
+	}
+	if h.Result.Rake != 80 || h.Result.Pots[0].Amount != 870+55-80 ||
+		h.Result.Pots[0].Winners[0].Amount != 845 {
+		t.Errorf("Unexpected result %+v", h.Result)
+	}
+	if v := h.Validate(); len(v) != 0 {
+		t.Errorf("Unexpected violations %v", v)
+	}
+}
+
+// RakeSchedule.Structure() ////////////////////////////////////////////////////
+
+func TestRakeSchedule(t *testing.T) {
+
+	s := RakeSchedule{Site: "PokerStars", Levels: []RakeLevel{
+		{2, RakeStructure{Percent: 3.5}},
+		{10, RakeStructure{Percent: 4.5}},
+		{100, RakeStructure{Percent: 5}},
+	}}
+
+	type testPair struct {
+		bigBlind Amount
+		percent  float64
+	}
+	tests := []testPair{{2, 3.5}, {5, 4.5}, {10, 4.5}, {50, 5}, {1000, 5}}
+	for _, test := range tests {
+		r := s.Structure(Stakes{BigBlind: test.bigBlind})
+		if r == nil || r.Percent != test.percent {
+			t.Errorf("For %v expected %v%%, got %+v", test.bigBlind, test.percent, r)
+		}
+	}
+
+	if r := (&RakeSchedule{}).Structure(Stakes{BigBlind: 2}); r != nil {
+		t.Errorf("Expected no structure, got %+v", r)
+	}
+}
+
+// Result.Jackpot //////////////////////////////////////////////////////////////
+
+func TestJackpot(t *testing.T) {
+
+	history := strings.Replace(testHistory, "Total pot $16.05 Main pot $8.70.",
+		"Total pot $16.07 Main pot $8.70.", 1)
+	history = strings.Replace(history, "| Rake $0.55\n", "| Rake $0.55 | Jackpot $0.02\n", 1)
+
+	h, err := ParseHand(history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Result.Rake != 55 || h.Result.Jackpot != 2 {
+		t.Errorf("Unexpected result %+v", h.Result)
+	}
+	if h.String() != history {
+		t.Errorf("Expected\n%v\ngot\n%v", history, h.String())
+	}
+}
@@ -0,0 +1,528 @@
+package poker
+
+import "fmt"
+
+// TableState is the state of the table at a point during a hand.
+type TableState struct {
+
+	// Round is the index of the current betting round (0 is preflop).
+	Round int
+
+	// Stacks are the players' remaining stacks.
+	Stacks map[PlayerPosition]Amount
+
+	// Contributions are the amounts put in by each player in the current
+	// betting round.
+	Contributions map[PlayerPosition]Amount
+
+	// Invested are the amounts put in by each player during the whole hand.
+	Invested map[PlayerPosition]Amount
+
+	// Pot is the total amount put in by all players, including the current
+	// betting round.
+	Pot Amount
+
+	// InHand are the players who have not folded, in seat order.
+	InHand []PlayerPosition
+
+	// ToAct is the player to act, or 0 if the betting round is complete.
+	ToAct PlayerPosition
+
+	// CurrentBet is the largest contribution in the current betting round.
+	CurrentBet Amount
+
+	// MinRaise is the minimum size of a raise on top of CurrentBet.
+	MinRaise Amount
+
+	tableSize int
+	button    PlayerPosition
+	bigBlind  Amount
+	acted     map[PlayerPosition]bool
+
+	// dead are the blinds posted as dead money, which belongs to the main
+	// pot rather than to a player's share of the pots.
+	dead map[PlayerPosition]Amount
+}
+
+// NewTableState creates the state of a hand before any action, i.e. after the
+// antes and blinds have been posted.
+func NewTableState(h *Hand) *TableState {
+
+	s := &TableState{
+		Stacks:        make(map[PlayerPosition]Amount),
+		Contributions: make(map[PlayerPosition]Amount),
+		Invested:      make(map[PlayerPosition]Amount),
+		MinRaise:      h.Table.Stakes.BigBlind,
+		tableSize:     h.TableSize(),
+		button:        h.Button,
+		bigBlind:      h.Table.Stakes.BigBlind,
+		acted:         make(map[PlayerPosition]bool),
+		dead:          make(map[PlayerPosition]Amount),
+	}
+	for _, pos := range h.Seats() {
+		s.Stacks[pos] = pos.Player(h).Stack
+		s.InHand = append(s.InHand, pos)
+	}
+
+	// Antes are dead money, which does not count towards the bet. The big
+	// blind ante is posted by the big blind alone.
+	if ante := h.Table.Stakes.Ante; ante > 0 {
+		for _, pos := range s.InHand {
+			s.put(pos, ante)
+			delete(s.Contributions, pos)
+		}
+	}
+	if ante := h.Table.Stakes.BigBlindAnte; ante > 0 {
+		s.dead[h.BigBlind] += s.put(h.BigBlind, ante)
+		delete(s.Contributions, h.BigBlind)
+	}
+
+	s.put(h.SmallBlind, h.Table.Stakes.SmallBlind)
+	s.put(h.BigBlind, h.Table.Stakes.BigBlind)
+
+	// Players returning after missing the blinds post a dead small blind and
+	// a live big blind.
+	for _, pos := range s.InHand {
+		if pos.Player(h).Status == DeadBlind && pos != h.SmallBlind && pos != h.BigBlind {
+			s.dead[pos] += s.put(pos, h.Table.Stakes.SmallBlind)
+			delete(s.Contributions, pos)
+			s.put(pos, h.Table.Stakes.BigBlind)
+		}
+	}
+	s.CurrentBet = s.Contributions[h.BigBlind]
+	if s.Contributions[h.SmallBlind] > s.CurrentBet {
+		s.CurrentBet = s.Contributions[h.SmallBlind]
+	}
+
+	// The straddle is a blind posted by the player left of the big blind, who
+	// acts last before the flop. Raises are at least the straddle.
+	last := h.BigBlind
+	if straddler := h.Straddler(); straddler != 0 {
+		s.put(straddler, h.Table.Stakes.Straddle)
+		if s.Contributions[straddler] > s.CurrentBet {
+			s.CurrentBet = s.Contributions[straddler]
+			s.MinRaise = s.CurrentBet
+		}
+		last = straddler
+	}
+	if last == 0 {
+		last = h.Button
+	}
+	s.ToAct = s.next(last)
+
+	return s
+}
+
+// put moves an amount from a player's stack into the pot.
+func (s *TableState) put(pos PlayerPosition, amount Amount) Amount {
+	if _, ok := s.Stacks[pos]; !ok {
+		return 0
+	}
+	if amount > s.Stacks[pos] {
+		amount = s.Stacks[pos]
+	}
+	s.Stacks[pos] -= amount
+	s.Contributions[pos] += amount
+	s.Invested[pos] += amount
+	s.Pot += amount
+	return amount
+}
+
+// IsInHand returns whether a player has not folded.
+func (s *TableState) IsInHand(pos PlayerPosition) bool {
+	for i := 0; i < len(s.InHand); i++ {
+		if s.InHand[i] == pos {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAllIn returns whether a player in the hand has no chips left.
+func (s *TableState) IsAllIn(pos PlayerPosition) bool {
+	return s.IsInHand(pos) && s.Stacks[pos] == 0
+}
+
+// ToCall returns the amount a player must put in to call.
+func (s *TableState) ToCall(pos PlayerPosition) Amount {
+	toCall := s.CurrentBet - s.Contributions[pos]
+	if toCall > s.Stacks[pos] {
+		toCall = s.Stacks[pos]
+	}
+	return toCall
+}
+
+// canAct returns whether a player is able to act in the betting round.
+func (s *TableState) canAct(pos PlayerPosition) bool {
+	return s.IsInHand(pos) && s.Stacks[pos] > 0
+}
+
+// needsToAct returns whether a player must still act in the betting round.
+func (s *TableState) needsToAct(pos PlayerPosition) bool {
+	if !s.canAct(pos) {
+		return false
+	}
+	if !s.acted[pos] {
+		// A player with nobody left to play against does not act, unless
+		// facing a bet.
+		others := 0
+		for _, p := range s.InHand {
+			if p != pos && s.canAct(p) {
+				others++
+			}
+		}
+		return others > 0 || s.Contributions[pos] < s.CurrentBet
+	}
+	return s.Contributions[pos] < s.CurrentBet
+}
+
+// next returns the next player after pos who must act, or 0 if none.
+func (s *TableState) next(pos PlayerPosition) PlayerPosition {
+	if s.tableSize < 1 {
+		return 0
+	}
+	if pos < 1 || int(pos) > s.tableSize {
+		pos = PlayerPosition(s.tableSize)
+	}
+	p := pos
+	for i := 0; i < s.tableSize; i++ {
+		p = NextPlayerPosition(p, s.tableSize)
+		if s.needsToAct(p) {
+			return p
+		}
+	}
+	return 0
+}
+
+// Apply applies a player action to the state. Illegal actions are applied as
+// well as possible and reported by the returned error.
+func (s *TableState) Apply(pa PlayerAction) error {
+
+	var err error
+	pos := pa.Position
+
+	if _, ok := s.Stacks[pos]; !ok {
+		return fmt.Errorf("seat %v is not occupied", pos)
+	}
+	if !s.IsInHand(pos) {
+		return fmt.Errorf("seat %v acts after folding", pos)
+	}
+	if pos != s.ToAct {
+		if s.ToAct == 0 {
+			err = fmt.Errorf("seat %v acts after the betting round is complete", pos)
+		} else {
+			err = fmt.Errorf("seat %v acts out of turn, expected seat %v", pos, s.ToAct)
+		}
+	}
+	setErr := func(e error) {
+		if err == nil {
+			err = e
+		}
+	}
+
+	switch a := pa.Action.(type) {
+
+	case *foldAction:
+		for i := 0; i < len(s.InHand); i++ {
+			if s.InHand[i] == pos {
+				s.InHand = append(s.InHand[:i:i], s.InHand[i+1:]...)
+				break
+			}
+		}
+
+	case *checkAction:
+		if s.Contributions[pos] < s.CurrentBet {
+			setErr(fmt.Errorf("seat %v checks facing a bet of %v", pos, s.CurrentBet))
+		}
+
+	case *callAction:
+		toCall := s.ToCall(pos)
+		amount := a.amount
+		if amount < 0 {
+			amount = toCall
+		}
+		if toCall == 0 {
+			setErr(fmt.Errorf("seat %v calls without facing a bet", pos))
+		} else if amount != toCall {
+			setErr(fmt.Errorf("seat %v calls %v, expected %v", pos, amount, toCall))
+		}
+		s.put(pos, amount)
+
+	case *betAction, *raiseAction:
+		to := pa.Action.Amount()
+		if to < 0 {
+			to = s.Contributions[pos] + s.Stacks[pos]
+		}
+
+		_, isBet := a.(*betAction)
+		if isBet && s.CurrentBet > 0 && s.Round > 0 {
+			setErr(fmt.Errorf("seat %v bets facing a bet of %v", pos, s.CurrentBet))
+		}
+		if !isBet && s.CurrentBet == 0 {
+			setErr(fmt.Errorf("seat %v raises without facing a bet", pos))
+		}
+
+		allIn := to >= s.Contributions[pos]+s.Stacks[pos]
+		if to <= s.CurrentBet {
+			setErr(fmt.Errorf("seat %v raises to %v, not above %v", pos, to, s.CurrentBet))
+		} else if to-s.CurrentBet < s.MinRaise && !allIn {
+			setErr(fmt.Errorf("seat %v raises to %v, minimum is %v", pos, to,
+				s.CurrentBet+s.MinRaise))
+		}
+		if to > s.Contributions[pos]+s.Stacks[pos] {
+			setErr(fmt.Errorf("seat %v raises to %v with a stack of %v", pos, to,
+				s.Stacks[pos]))
+		}
+
+		s.put(pos, to-s.Contributions[pos])
+		to = s.Contributions[pos]
+		if to > s.CurrentBet {
+			if to-s.CurrentBet >= s.MinRaise {
+				s.MinRaise = to - s.CurrentBet
+
+				// A full raise reopens the action.
+				s.acted = make(map[PlayerPosition]bool)
+			}
+			s.CurrentBet = to
+		}
+
+	default:
+		setErr(fmt.Errorf("seat %v: unknown action %v", pos, pa.Action))
+	}
+
+	s.acted[pos] = true
+	s.ToAct = s.next(pos)
+
+	return err
+}
+
+// LegalActions are the actions a player may take.
+type LegalActions struct {
+
+	// Types are the legal action types.
+	Types []ActionType
+
+	// ToCall is the amount to call.
+	ToCall Amount
+
+	// MinTo and MaxTo are the smallest and largest amounts a player may bet
+	// or raise to in the betting round, if betting or raising is legal.
+	MinTo, MaxTo Amount
+}
+
+// Allows returns whether an action type is legal.
+func (l LegalActions) Allows(t ActionType) bool {
+	for _, legal := range l.Types {
+		if legal == t {
+			return true
+		}
+	}
+	return false
+}
+
+// LegalActions returns the actions a player may take. A player who is not to
+// act may take no action.
+func (s *TableState) LegalActions(pos PlayerPosition) LegalActions {
+
+	var l LegalActions
+	if pos == 0 || pos != s.ToAct {
+		return l
+	}
+
+	l.ToCall = s.ToCall(pos)
+	if l.ToCall > 0 {
+		l.Types = append(l.Types, Fold, Call)
+	} else {
+		l.Types = append(l.Types, Check)
+	}
+
+	// Raising needs an opponent who can call, and is not reopened by a raise
+	// smaller than the minimum.
+	others := 0
+	for _, p := range s.InHand {
+		if p != pos && s.Stacks[p] > 0 {
+			others++
+		}
+	}
+	if others == 0 || s.Stacks[pos] <= l.ToCall || s.acted[pos] {
+		return l
+	}
+
+	if s.CurrentBet == 0 {
+		l.Types = append(l.Types, Bet)
+	} else {
+		l.Types = append(l.Types, Raise)
+	}
+	l.MaxTo = s.Contributions[pos] + s.Stacks[pos]
+	l.MinTo = minAmount(s.CurrentBet+s.MinRaise, l.MaxTo)
+	return l
+}
+
+// NextRound ends the current betting round. An uncalled bet is returned to
+// the player who made it.
+func (s *TableState) NextRound() {
+	if pos, amount := s.Uncalled(); amount > 0 {
+		s.Stacks[pos] += amount
+		s.Invested[pos] -= amount
+		s.Pot -= amount
+	}
+
+	s.Round++
+	s.Contributions = make(map[PlayerPosition]Amount)
+	s.CurrentBet = 0
+	s.MinRaise = s.bigBlind
+	s.acted = make(map[PlayerPosition]bool)
+	s.ToAct = s.next(s.button)
+}
+
+// EffectiveStack returns the effective stack of a player, i.e. the smaller of
+// the player's stack and the largest stack among the other players in the
+// hand.
+func (s *TableState) EffectiveStack(pos PlayerPosition) Amount {
+	var largest Amount
+	for _, p := range s.InHand {
+		if p != pos && s.Stacks[p] > largest {
+			largest = s.Stacks[p]
+		}
+	}
+	if s.Stacks[pos] < largest {
+		return s.Stacks[pos]
+	}
+	return largest
+}
+
+// EffectiveStacks returns the effective stack of every player in the hand.
+func (s *TableState) EffectiveStacks() map[PlayerPosition]Amount {
+	stacks := make(map[PlayerPosition]Amount)
+	for _, p := range s.InHand {
+		stacks[p] = s.EffectiveStack(p)
+	}
+	return stacks
+}
+
+// SPR returns a player's stack-to-pot ratio, i.e. the player's effective
+// stack divided by the pot.
+func (s *TableState) SPR(pos PlayerPosition) float64 {
+	if s.Pot == 0 {
+		return 0
+	}
+	return float64(s.EffectiveStack(pos)) / float64(s.Pot)
+}
+
+// Uncalled returns the part of the largest contribution in the current
+// betting round which nobody matched, and the player who made it.
+func (s *TableState) Uncalled() (PlayerPosition, Amount) {
+	var pos PlayerPosition
+	for p, c := range s.Contributions {
+		if pos == 0 || c > s.Contributions[pos] {
+			pos = p
+		}
+	}
+
+	var second Amount
+	for p, c := range s.Contributions {
+		if p != pos && c > second {
+			second = c
+		}
+	}
+	if pos == 0 || s.Contributions[pos] == second {
+		return 0, 0
+	}
+	return pos, s.Contributions[pos] - second
+}
+
+// Copy returns a deep copy of the state.
+func (s *TableState) Copy() *TableState {
+	c := *s
+	c.Stacks = copyAmounts(s.Stacks)
+	c.Contributions = copyAmounts(s.Contributions)
+	c.Invested = copyAmounts(s.Invested)
+	c.dead = copyAmounts(s.dead)
+	c.InHand = append([]PlayerPosition(nil), s.InHand...)
+	c.acted = make(map[PlayerPosition]bool)
+	for p, a := range s.acted {
+		c.acted[p] = a
+	}
+	return &c
+}
+
+func copyAmounts(m map[PlayerPosition]Amount) map[PlayerPosition]Amount {
+	c := make(map[PlayerPosition]Amount, len(m))
+	for p, a := range m {
+		c[p] = a
+	}
+	return c
+}
+
+// Replayer steps through the actions of a hand.
+type Replayer struct {
+	hand   *Hand
+	state  *TableState
+	round  int
+	action int
+	err    error
+}
+
+// Replay returns an iterator over the actions of a hand. Each call to Next
+// applies one action, after which State returns the resulting table state.
+//
+//	r := poker.Replay(h)
+//	for r.Next() {
+//		fmt.Println(r.Action(), r.State().Pot)
+//	}
+//	if err := r.Err(); err != nil {
+//		...
+//	}
+func Replay(h *Hand) *Replayer {
+	return &Replayer{hand: h, state: NewTableState(h), action: -1}
+}
+
+// Next applies the next action. It returns false when all actions have been
+// applied or an illegal action was found.
+func (r *Replayer) Next() bool {
+
+	if r.err != nil {
+		return false
+	}
+
+	r.action++
+	for r.round < len(r.hand.Rounds) &&
+		r.action >= len(r.hand.Rounds[r.round].Actions) {
+
+		if r.round+1 >= len(r.hand.Rounds) {
+			return false
+		}
+		r.round++
+		r.action = 0
+		r.state.NextRound()
+	}
+	if r.round >= len(r.hand.Rounds) {
+		return false
+	}
+
+	if err := r.state.Apply(r.Action()); err != nil {
+		r.err = fmt.Errorf("round %v, action %v: %v", r.round, r.action+1, err)
+		return false
+	}
+	return true
+}
+
+// Action returns the last applied action.
+func (r *Replayer) Action() PlayerAction {
+	return r.hand.Rounds[r.round].Actions[r.action]
+}
+
+// Round returns the index of the betting round of the last applied action.
+func (r *Replayer) Round() int {
+	return r.round
+}
+
+// State returns a copy of the table state after the last applied action.
+func (r *Replayer) State() *TableState {
+	return r.state.Copy()
+}
+
+// Err returns the illegal action which stopped the replay, if any.
+func (r *Replayer) Err() error {
+	return r.err
+}
@@ -0,0 +1,231 @@
+package poker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/whomever000/poker-common/card"
+)
+
+// testHand returns a three handed hand which goes to showdown.
+func testHand() *Hand {
+	return &Hand{
+		Client: "PokerStars",
+		HandID: 42,
+		Table: Table{
+			Name:   "Test",
+			Stakes: Stakes{SmallBlind: 1, BigBlind: 2},
+			Size:   3,
+			Game:   TexasHoldEmNoLimit,
+		},
+		Button:     1,
+		SmallBlind: 2,
+		BigBlind:   3,
+		Players: map[PlayerPosition]*Player{
+			1: {Name: "alice", Stack: 200},
+			2: {Name: "bob", Stack: 200},
+			3: {Name: "carol", Stack: 100},
+		},
+		Rounds: []Round{
+			{nil, 13, []PlayerAction{
+				{1, NewRaiseAction(6)},
+				{2, NewFoldAction()},
+				{3, NewCallAction(4)},
+			}},
+			{[]card.Card{card.CardAh, card.CardKd, card.Card2c}, 33, []PlayerAction{
+				{3, NewCheckAction()},
+				{1, NewBetAction(10)},
+				{3, NewCallAction(10)},
+			}},
+			{[]card.Card{card.CardAh, card.CardKd, card.Card2c, card.Card7s}, 33,
+				[]PlayerAction{
+					{3, NewCheckAction()},
+					{1, NewCheckAction()},
+				}},
+			{[]card.Card{card.CardAh, card.CardKd, card.Card2c, card.Card7s,
+				card.Card9h}, 201, []PlayerAction{
+				{3, NewBetAction(-1)},
+				{1, NewCallAction(84)},
+			}},
+		},
+		Result: &Result{
+			Pots: []Pot{{201, []PlayerPosition{1, 3}, []Share{{1, 201}}}},
+			ShowDowns: []PlayerCards{
+				{3, []card.Card{card.CardQc, card.CardJc}},
+				{1, []card.Card{card.CardAs, card.Card9c}},
+			},
+		},
+	}
+}
+
+// Replay() ////////////////////////////////////////////////////////////////////
+
+type testPairReplay struct {
+	pot        Amount
+	toAct      PlayerPosition
+	currentBet Amount
+	inHand     int
+}
+
+var testsReplay = []testPairReplay{
+	{9, 2, 6, 3},
+	{9, 3, 6, 2},
+	{13, 0, 6, 2},
+	{13, 1, 0, 2},
+	{23, 3, 10, 2},
+	{33, 0, 10, 2},
+	{33, 1, 0, 2},
+	{33, 0, 0, 2},
+	{117, 1, 84, 2},
+	{201, 0, 84, 2},
+}
+
+func TestReplay(t *testing.T) {
+
+	tests := testsReplay
+	h := testHand()
+
+	state := NewTableState(h)
+	if state.Pot != 3 || state.ToAct != 1 || state.CurrentBet != 2 {
+		t.Errorf("Unexpected initial state %+v", state)
+	}
+
+	r := Replay(h)
+	i := 0
+	for ; r.Next(); i++ {
+		s := r.State()
+		if i >= len(tests) {
+			t.Fatalf("Unexpected action %v", r.Action())
+		}
+		if s.Pot != tests[i].pot || s.ToAct != tests[i].toAct ||
+			s.CurrentBet != tests[i].currentBet || len(s.InHand) != tests[i].inHand {
+			t.Errorf("For action %v expected %+v, got pot=%v toAct=%v bet=%v in=%v",
+				i, tests[i], s.Pot, s.ToAct, s.CurrentBet, s.InHand)
+		}
+		if i == 3 {
+			if s.EffectiveStack(1) != 94 || s.SPR(1) != 94.0/13.0 {
+				t.Errorf("Unexpected effective stack %v", s.EffectiveStacks())
+			}
+		}
+	}
+	if r.Err() != nil {
+		t.Errorf("Unexpected error %v", r.Err())
+	}
+	if i != len(tests) {
+		t.Errorf("Expected %v actions, got %v", len(tests), i)
+	}
+
+	s := r.State()
+	if s.Stacks[1] != 100 || s.Stacks[2] != 199 || s.Stacks[3] != 0 {
+		t.Errorf("Unexpected stacks %v", s.Stacks)
+	}
+}
+
+func TestReplayIllegal(t *testing.T) {
+
+	h := testHand()
+	h.Rounds[0].Actions[2] = PlayerAction{2, NewCallAction(4)}
+
+	r := Replay(h)
+	for r.Next() {
+	}
+	if r.Err() == nil {
+		t.Errorf("Expected error for action after folding")
+	}
+}
+
+// TableState.NextRound() //////////////////////////////////////////////////////
+
+func TestNextRound(t *testing.T) {
+
+	// The raise is uncalled beyond the big blind, and returned.
+	s := NewTableState(testHand())
+	for _, pa := range []PlayerAction{
+		{1, NewRaiseAction(6)},
+		{2, NewFoldAction()},
+		{3, NewFoldAction()},
+	} {
+		if err := s.Apply(pa); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.NextRound()
+	if s.Pot != 5 || s.Stacks[1] != 198 || s.Invested[1] != 2 {
+		t.Errorf("Unexpected state %+v", s)
+	}
+}
+
+// LegalActions() //////////////////////////////////////////////////////////////
+
+func TestLegalActions(t *testing.T) {
+
+	h := testHand()
+
+	l := NewTableState(h).LegalActions(1)
+	expected := LegalActions{[]ActionType{Fold, Call, Raise}, 2, 4, 200}
+	if !reflect.DeepEqual(l, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, l)
+	}
+
+	// Facing an all-in, there is nobody left to raise.
+	r := Replay(h)
+	for i := 0; i < 9; i++ {
+		r.Next()
+	}
+	l = r.State().LegalActions(1)
+	expected = LegalActions{[]ActionType{Fold, Call}, 84, 0, 0}
+	if !reflect.DeepEqual(l, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, l)
+	}
+	if l.Allows(Raise) || r.State().LegalActions(3).Types != nil {
+		t.Errorf("Unexpected legal actions %+v", l)
+	}
+}
+
+// NewTableState() /////////////////////////////////////////////////////////////
+
+func TestForcedBets(t *testing.T) {
+
+	h := testHand()
+	h.Sit(4, Player{Name: "dave", Stack: 200})
+	h.Table.Size = 4
+	h.Button, h.SmallBlind, h.BigBlind = 4, 1, 2
+	h.Table.Stakes.Straddle = 4
+
+	// The straddle in seat 3 acts last, and raises are at least the straddle.
+	s := NewTableState(h)
+	if s.Pot != 7 || s.CurrentBet != 4 || s.MinRaise != 4 || s.ToAct != 4 {
+		t.Errorf("Unexpected state %+v", s)
+	}
+	expected := LegalActions{[]ActionType{Fold, Call, Raise}, 4, 8, 200}
+	if l := s.LegalActions(4); !reflect.DeepEqual(l, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, l)
+	}
+	for _, pa := range []PlayerAction{
+		{4, NewCallAction(4)},
+		{1, NewFoldAction()},
+		{2, NewCallAction(2)},
+	} {
+		if err := s.Apply(pa); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.ToAct != 3 {
+		t.Errorf("Expected the straddle to have the option, got %v", s.ToAct)
+	}
+
+	// Heads-up, nobody straddles.
+	delete(h.Players, 3)
+	delete(h.Players, 4)
+	if s := NewTableState(h); s.Pot != 3 || s.ToAct != 1 {
+		t.Errorf("Unexpected state %+v", s)
+	}
+
+	// The big blind ante is dead money posted by the big blind.
+	h = testHand()
+	h.Table.Stakes.BigBlindAnte = 2
+	s = NewTableState(h)
+	if s.Pot != 5 || s.Invested[3] != 4 || s.Contributions[3] != 2 || s.CurrentBet != 2 {
+		t.Errorf("Unexpected state %+v", s)
+	}
+}
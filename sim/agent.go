@@ -0,0 +1,75 @@
+// Package sim simulates hands between agents, e.g. bots, without a poker
+// client.
+package sim
+
+import (
+	"math/rand"
+
+	poker "github.com/whomever000/poker-common"
+	"github.com/whomever000/poker-common/card"
+)
+
+// View is what an agent sees when it is to act.
+type View struct {
+
+	// Table is the table the hand is played at.
+	Table poker.Table
+
+	// Cards are the agent's position and hole cards.
+	Cards poker.PlayerCards
+
+	// Board are the board cards dealt so far.
+	Board []card.Card
+
+	// History are the betting rounds so far. The last round is the current
+	// one.
+	History []poker.Round
+
+	// State is the state of the table.
+	State *poker.TableState
+
+	// Legal are the actions the agent may take.
+	Legal poker.LegalActions
+}
+
+// Agent decides on actions.
+type Agent interface {
+
+	// Act returns the agent's action. Bet and raise amounts are the total
+	// for the betting round, and an amount of -1 is all-in.
+	Act(v *View) poker.Action
+}
+
+// AgentFunc is a function which is an agent.
+type AgentFunc func(v *View) poker.Action
+
+// Act calls the function.
+func (f AgentFunc) Act(v *View) poker.Action {
+	return f(v)
+}
+
+// Caller is an agent which always checks or calls.
+var Caller Agent = AgentFunc(func(v *View) poker.Action {
+	if v.Legal.Allows(poker.Call) {
+		return poker.NewCallAction(v.Legal.ToCall)
+	}
+	return poker.NewCheckAction()
+})
+
+// NewRandomAgent creates an agent which takes random legal actions, with bet
+// and raise amounts between the minimum and all-in.
+func NewRandomAgent(seed int64) Agent {
+	rng := rand.New(rand.NewSource(seed))
+	return AgentFunc(func(v *View) poker.Action {
+		t := v.Legal.Types[rng.Intn(len(v.Legal.Types))]
+		switch t {
+		case poker.Call:
+			return poker.NewCallAction(v.Legal.ToCall)
+		case poker.Bet, poker.Raise:
+			to := v.Legal.MinTo + poker.Amount(rng.Int63n(int64(v.Legal.MaxTo-v.Legal.MinTo)+1))
+			return poker.NewAction(t, to)
+		default:
+			return poker.NewAction(t, 0)
+		}
+	})
+}
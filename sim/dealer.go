@@ -0,0 +1,280 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	poker "github.com/whomever000/poker-common"
+	"github.com/whomever000/poker-common/card"
+)
+
+// Player is a player seated at the dealer's table.
+type Player struct {
+
+	// Name is the player's name.
+	Name string
+
+	// Agent decides on the player's actions.
+	Agent Agent
+
+	// Stack is the player's stack, which is updated after every hand.
+	Stack poker.Amount
+}
+
+// Dealer deals hands between agents. Players who run out of chips sit out.
+type Dealer struct {
+
+	// Table is the table, including its stakes, which may change between
+	// hands.
+	Table poker.Table
+
+	// Seats are the players by seat, i.e. Seats[0] is in seat 1. Empty seats
+	// are nil.
+	Seats []*Player
+
+	// Button is the button of the last hand.
+	Button poker.PlayerPosition
+
+	// ButtonRule is how the button and the blinds move between hands.
+	ButtonRule poker.ButtonRule
+
+	// Tournament makes the session a tournament, or is nil for a cash game.
+	// Each hand records the tournament with its current level.
+	Tournament *poker.Tournament
+
+	// Schedule is the blind schedule, which sets the stakes before each hand.
+	// It is ignored if empty.
+	Schedule poker.Schedule
+
+	// HandTime is the time a hand is assumed to take, for schedules with
+	// levels of limited duration.
+	HandTime time.Duration
+
+	// Start is the date of the first hand. Later hands are dated HandTime
+	// apart, so that a repeated session records identical hands.
+	Start time.Time
+
+	// Rake is the rake taken from each hand, or nil for none.
+	Rake *poker.RakeStructure
+
+	// Busted are the players who ran out of chips, in the order they did.
+	Busted []*Player
+
+	deck   *card.Deck
+	handID int
+	blinds poker.Blinds
+}
+
+// NewDealer creates a dealer which seats players in seats 1, 2, ... The deck
+// is shuffled by a seeded random source, so that a session can be repeated.
+func NewDealer(table poker.Table, seed int64, players ...*Player) *Dealer {
+	if table.Size < len(players) {
+		table.Size = len(players)
+	}
+	if table.Game == nil {
+		table.Game = poker.TexasHoldEmNoLimit
+	}
+	seats := make([]*Player, table.Size)
+	copy(seats, players)
+	return &Dealer{Table: table, Seats: seats, deck: card.NewDeck(seed)}
+}
+
+// active returns whether a seat has a player with chips.
+func (d *Dealer) active(pos poker.PlayerPosition) bool {
+	return pos >= 1 && int(pos) <= len(d.Seats) && d.Seats[pos-1] != nil &&
+		d.Seats[pos-1].Stack > 0
+}
+
+// Session plays a number of hands. It stops early when fewer than two
+// players have chips.
+func (d *Dealer) Session(hands int) ([]*poker.Hand, error) {
+	var played []*poker.Hand
+	for i := 0; i < hands; i++ {
+		active := 0
+		for pos := 1; pos <= len(d.Seats); pos++ {
+			if d.active(poker.PlayerPosition(pos)) {
+				active++
+			}
+		}
+		if active < 2 {
+			break
+		}
+
+		h, err := d.Play()
+		if err != nil {
+			return played, err
+		}
+		played = append(played, h)
+	}
+	return played, nil
+}
+
+// Play moves the button and plays a single hand. The hand records the cards
+// shown at showdown, but no player's hole cards otherwise.
+func (d *Dealer) Play() (*poker.Hand, error) {
+
+	// Levels advance with the hands played so far.
+	level := 0
+	if len(d.Schedule) > 0 {
+		level = d.Schedule.Level(time.Duration(d.handID)*d.HandTime, d.handID)
+		d.Table.Stakes = d.Schedule[level].Stakes
+	}
+
+	d.handID++
+	h := &poker.Hand{
+		Client: "Simulation",
+		Table:  d.Table,
+		HandID: d.handID,
+		Date:   poker.Date(d.Start.Add(time.Duration(d.handID-1) * d.HandTime)),
+	}
+
+	if d.Tournament != nil {
+		t := *d.Tournament
+		t.Level = level + 1
+		h.Tournament = &t
+	}
+
+	d.deck.Shuffle()
+	holeCards := make(map[poker.PlayerPosition][]card.Card)
+	for i := 0; i < len(d.Seats); i++ {
+		pos := poker.PlayerPosition(i + 1)
+		if d.active(pos) {
+			h.Sit(pos, poker.Player{Name: d.Seats[i].Name, Stack: d.Seats[i].Stack})
+			holeCards[pos] = d.deck.Deal(2)
+		}
+	}
+
+	prev := d.blinds
+	prev.Button = d.Button
+	if err := h.AssignBlinds(d.ButtonRule, prev); err != nil {
+		return nil, fmt.Errorf("sim: %v", err)
+	}
+	d.blinds = h.Blinds()
+	d.Button = h.Button
+
+	s := poker.NewTableState(h)
+	_, uncalled := s.Uncalled()
+	h.Rounds = []poker.Round{{Pot: s.Pot - uncalled}}
+	streets := []int{0, 3, 1, 1}
+	for {
+		round := &h.Rounds[len(h.Rounds)-1]
+
+		if s.ToAct == 0 {
+			if len(s.InHand) == 1 || len(h.Rounds) == len(streets) {
+				break
+			}
+			board := append(append([]card.Card(nil), round.Cards...),
+				d.deck.Deal(streets[len(h.Rounds)])...)
+			s.NextRound()
+			h.Rounds = append(h.Rounds, poker.Round{Cards: board, Pot: s.Pot})
+			continue
+		}
+
+		pos := s.ToAct
+		v := &View{
+			Table:   h.Table,
+			Cards:   poker.PlayerCards{Position: pos, Cards: holeCards[pos]},
+			Board:   round.Cards,
+			History: copyRounds(h.Rounds),
+			State:   s.Copy(),
+			Legal:   s.LegalActions(pos),
+		}
+		pa := poker.PlayerAction{Position: pos, Action: d.Seats[pos-1].Agent.Act(v)}
+		if pa.Action == nil {
+			return nil, fmt.Errorf("sim: hand %v: seat %v has no action", h.HandID, pos)
+		}
+		if err := s.Apply(pa); err != nil {
+			return nil, fmt.Errorf("sim: hand %v: %v", h.HandID, err)
+		}
+		round.Actions = append(round.Actions, pa)
+		_, uncalled := s.Uncalled()
+		round.Pot = s.Pot - uncalled
+	}
+
+	if err := h.Settle(holeCards); err != nil {
+		return nil, fmt.Errorf("sim: hand %v: %v", h.HandID, err)
+	}
+	if d.Rake != nil {
+		if err := d.Rake.Apply(h); err != nil {
+			return nil, fmt.Errorf("sim: hand %v: %v", h.HandID, err)
+		}
+	}
+
+	// Uncalled bets are returned before the winnings are added.
+	s.NextRound()
+	winnings := h.Result.Winnings()
+	for pos, stack := range s.Stacks {
+		d.Seats[pos-1].Stack = stack + winnings[pos]
+	}
+	var busted []*Player
+	for i := 0; i < len(d.Seats); i++ {
+		if p := h.Players[poker.PlayerPosition(i+1)]; p != nil && p.Stack > 0 &&
+			d.Seats[i].Stack == 0 {
+			busted = append(busted, d.Seats[i])
+		}
+	}
+
+	// Of the players busted in the same hand, the one who started with more
+	// chips finishes higher.
+	sort.SliceStable(busted, func(i, j int) bool {
+		return d.seat(busted[i]).Player(h).Stack < d.seat(busted[j]).Player(h).Stack
+	})
+	d.Busted = append(d.Busted, busted...)
+
+	return h, nil
+}
+
+// seat returns the seat of a player, or 0.
+func (d *Dealer) seat(p *Player) poker.PlayerPosition {
+	for i := 0; i < len(d.Seats); i++ {
+		if d.Seats[i] == p {
+			return poker.PlayerPosition(i + 1)
+		}
+	}
+	return 0
+}
+
+// Place returns the finishing place of a player, starting at 1, or 0 if the
+// player is still playing for a place.
+func (d *Dealer) Place(p *Player) int {
+	players := 0
+	left := 0
+	for _, seated := range d.Seats {
+		if seated != nil {
+			players++
+			if seated.Stack > 0 {
+				left++
+			}
+		}
+	}
+	for i := 0; i < len(d.Busted); i++ {
+		if d.Busted[i] == p {
+			return players - i
+		}
+	}
+	if left == 1 && p.Stack > 0 {
+		return 1
+	}
+	return 0
+}
+
+// Prize returns the prize won by a player in a tournament, according to its
+// payouts.
+func (d *Dealer) Prize(p *Player) poker.Amount {
+	if d.Tournament == nil {
+		return 0
+	}
+	return d.Tournament.Payouts.Prize(d.Place(p))
+}
+
+// copyRounds returns a copy of betting rounds, which an agent may modify.
+func copyRounds(rounds []poker.Round) []poker.Round {
+	c := make([]poker.Round, len(rounds))
+	for i := 0; i < len(rounds); i++ {
+		c[i] = rounds[i]
+		c[i].Cards = append([]card.Card(nil), rounds[i].Cards...)
+		c[i].Actions = append([]poker.PlayerAction(nil), rounds[i].Actions...)
+	}
+	return c
+}
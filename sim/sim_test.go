@@ -0,0 +1,196 @@
+package sim
+
+import (
+	"testing"
+	"time"
+
+	poker "github.com/whomever000/poker-common"
+)
+
+// testDealer creates a dealer for three agents at a six seat table.
+func testDealer(seed int64) *Dealer {
+	table := poker.Table{
+		Name:   "Sim",
+		Stakes: poker.Stakes{SmallBlind: 1, BigBlind: 2},
+		Size:   6,
+	}
+	d := NewDealer(table, seed,
+		&Player{"caller", Caller, 200},
+		nil,
+		&Player{"random1", NewRandomAgent(1), 200},
+		&Player{"random2", NewRandomAgent(2), 200},
+	)
+	d.Start = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	d.HandTime = time.Minute
+	return d
+}
+
+// Session() ///////////////////////////////////////////////////////////////////
+
+func TestSession(t *testing.T) {
+
+	d := testDealer(42)
+	hands, err := d.Session(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hands) == 0 {
+		t.Fatal("Expected hands")
+	}
+
+	for i, h := range hands {
+		if v := h.Validate(); len(v) != 0 {
+			t.Errorf("For hand %v unexpected violations %v\n%v", i, v, h)
+		}
+		if h.Players[2] != nil {
+			t.Errorf("For hand %v expected seat 2 to be empty", i)
+		}
+	}
+
+	// Chips are neither created nor lost.
+	var total poker.Amount
+	for _, p := range d.Seats {
+		if p != nil {
+			total += p.Stack
+		}
+	}
+	if total != 600 {
+		t.Errorf("Expected 600 chips, got %v", total)
+	}
+
+	// The same seeds play the same hands, dated alike.
+	again, err := testDealer(42).Session(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != len(hands) {
+		t.Fatalf("Expected %v hands, got %v", len(hands), len(again))
+	}
+	for i := 0; i < len(hands); i++ {
+		if hands[i].String() != again[i].String() {
+			t.Errorf("For hand %v expected\n%v\ngot\n%v", i, hands[i], again[i])
+		}
+	}
+	if date := time.Time(hands[1].Date); !date.Equal(d.Start.Add(time.Minute)) {
+		t.Errorf("Expected second hand a minute after the start, got %v", date)
+	}
+}
+
+func TestSessionDeadButton(t *testing.T) {
+
+	d := testDealer(7)
+	d.ButtonRule = poker.DeadButton
+	hands, err := d.Session(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, h := range hands {
+		if v := h.Validate(); len(v) != 0 {
+			t.Errorf("For hand %v unexpected violations %v\n%v", i, v, h)
+		}
+		if i == 0 {
+			continue
+		}
+		if v := h.ValidateBlinds(poker.DeadButton, hands[i-1].Blinds()); len(v) != 0 {
+			t.Errorf("For hand %v unexpected violations %v", i, v)
+		}
+		if h.BigBlind != h.NextActive(hands[i-1].BigBlind) {
+			t.Errorf("For hand %v expected the big blind to move one seat", i)
+		}
+	}
+}
+
+func TestSessionRake(t *testing.T) {
+
+	d := testDealer(42)
+	d.Rake = &poker.RakeStructure{Percent: 5, Caps: []poker.RakeCap{{Players: 2, Cap: 3}}}
+	hands, err := d.Session(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Chips are either in the stacks or raked.
+	var total, rake poker.Amount
+	for i, h := range hands {
+		if v := h.Validate(); len(v) != 0 {
+			t.Errorf("For hand %v unexpected violations %v\n%v", i, v, h)
+		}
+		rake += h.Result.Rake
+	}
+	for _, p := range d.Seats {
+		if p != nil {
+			total += p.Stack
+		}
+	}
+	if rake == 0 || total+rake != 600 {
+		t.Errorf("Expected 600 chips, got %v and %v rake", total, rake)
+	}
+}
+
+func TestSessionTournament(t *testing.T) {
+
+	d := testDealer(7)
+	d.Tournament = &poker.Tournament{ID: 1, BuyIn: 1000, Payouts: poker.Payouts{2000, 1000}}
+	d.Schedule = poker.Schedule{
+		{Stakes: poker.Stakes{SmallBlind: 1, BigBlind: 2}, Hands: 5},
+		{Stakes: poker.Stakes{SmallBlind: 5, BigBlind: 10, Ante: 1}, Duration: time.Minute},
+		{Stakes: poker.Stakes{SmallBlind: 25, BigBlind: 50, Ante: 5}},
+	}
+	d.HandTime = 10 * time.Second
+
+	hands, err := d.Session(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, h := range hands {
+		level := 3
+		if i < 5 {
+			level = 1
+		} else if i < 6 {
+			level = 2
+		}
+		if h.Tournament == nil || h.Tournament.Level != level ||
+			h.Table.Stakes != d.Schedule[level-1].Stakes {
+			t.Errorf("For hand %v expected level %v, got %+v", i, level, h.Tournament)
+		}
+		if v := h.Validate(); len(v) != 0 {
+			t.Errorf("For hand %v unexpected violations %v\n%v", i, v, h)
+		}
+	}
+
+	// The session plays until one player has all the chips.
+	if len(d.Busted) != 2 {
+		t.Fatalf("Expected 2 busted players, got %v", len(d.Busted))
+	}
+	var total poker.Amount
+	for _, p := range d.Seats {
+		if p == nil {
+			continue
+		}
+		total += d.Prize(p)
+		if d.Place(p) == 1 && p.Stack != 600 {
+			t.Errorf("Expected the winner to have 600 chips, got %v", p.Stack)
+		}
+	}
+	if d.Place(d.Busted[0]) != 3 || d.Place(d.Busted[1]) != 2 || total != 3000 {
+		t.Errorf("Unexpected places of %v, prizes %v", d.Busted, total)
+	}
+}
+
+// Play() //////////////////////////////////////////////////////////////////////
+
+func TestPlayIllegal(t *testing.T) {
+
+	d := testDealer(1)
+	d.Seats[0].Agent = AgentFunc(func(v *View) poker.Action {
+		return poker.NewRaiseAction(1)
+	})
+	d.Seats[2].Agent = Caller
+	d.Seats[3].Agent = Caller
+
+	if _, err := d.Play(); err == nil {
+		t.Errorf("Expected error for illegal raise")
+	}
+}
@@ -0,0 +1,181 @@
+package poker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PotType is the kind of a pot by the raises before the flop.
+type PotType int
+
+// Pot types
+const (
+	// Walk is a hand in which everyone folded to the big blind.
+	Walk PotType = iota
+
+	// Limped is a pot nobody raised before the flop.
+	Limped
+
+	// SingleRaised is a pot raised once before the flop.
+	SingleRaised
+
+	// ThreeBet is a pot re-raised once before the flop.
+	ThreeBet
+
+	// FourBet is a pot re-raised twice or more before the flop.
+	FourBet
+)
+
+// potTypeNames are the names of the pot types.
+var potTypeNames = []string{"walk", "limped", "SRP", "3bet", "4bet"}
+
+// String returns the name of the pot type, e.g. 'SRP' or '3bet'.
+func (t PotType) String() string {
+	if t < 0 || int(t) >= len(potTypeNames) {
+		return fmt.Sprintf("PotType(%d)", int(t))
+	}
+	return potTypeNames[t]
+}
+
+// ParsePotType parses the name of a pot type.
+func ParsePotType(str string) (PotType, error) {
+	for i := 0; i < len(potTypeNames); i++ {
+		if strings.EqualFold(str, potTypeNames[i]) {
+			return PotType(i), nil
+		}
+	}
+	return Walk, fmt.Errorf("unknown pot type %q", str)
+}
+
+// SpotRole is the role of a player who saw the flop.
+type SpotRole struct {
+
+	// Aggressor is whether the player made the last raise before the flop.
+	// Other players called.
+	Aggressor bool
+
+	// InPosition is whether the player acts last after the flop.
+	InPosition bool
+}
+
+// Spot classifies a hand by the action before the flop.
+type Spot struct {
+
+	// Type is the pot type.
+	Type PotType
+
+	// Limpers are the players who called the big blind before anyone raised,
+	// including a small blind who completed.
+	Limpers []PlayerPosition
+
+	// Opener is the player who raised first, or 0.
+	Opener PlayerPosition
+
+	// ThreeBettor is the player who re-raised the opener, or 0.
+	ThreeBettor PlayerPosition
+
+	// FourBettor is the player who re-raised the three-bettor, or 0.
+	FourBettor PlayerPosition
+
+	// Callers are the players who called the last raise, or the limpers of a
+	// limped pot.
+	Callers []PlayerPosition
+
+	// Isolated is whether the opener raised after limpers.
+	Isolated bool
+
+	// Squeeze is whether the three-bet followed a call of the open.
+	Squeeze bool
+
+	// Players are the players who saw the flop, or who were left when the
+	// hand ended before it, in seat order.
+	Players []PlayerPosition
+
+	// Roles are the roles of the players.
+	Roles map[PlayerPosition]SpotRole
+}
+
+// HeadsUp returns whether two players saw the flop.
+func (s *Spot) HeadsUp() bool {
+	return len(s.Players) == 2
+}
+
+// Multiway returns whether more than two players saw the flop.
+func (s *Spot) Multiway() bool {
+	return len(s.Players) > 2
+}
+
+// Spot classifies the hand by the action before the flop.
+func (h *Hand) Spot() *Spot {
+
+	s := &Spot{Roles: make(map[PlayerPosition]SpotRole)}
+	folded := make(map[PlayerPosition]bool)
+	var raises int
+	var aggressor PlayerPosition
+	var called bool
+	if len(h.Rounds) > 0 {
+		for _, pa := range h.Rounds[0].Actions {
+			switch pa.Action.Type() {
+			case Fold:
+				folded[pa.Position] = true
+			case Call:
+				if raises == 0 {
+					s.Limpers = append(s.Limpers, pa.Position)
+				} else {
+					s.Callers = append(s.Callers, pa.Position)
+					called = true
+				}
+			case Bet, Raise:
+				raises++
+				switch raises {
+				case 1:
+					s.Opener = pa.Position
+					s.Isolated = len(s.Limpers) > 0
+				case 2:
+					s.ThreeBettor = pa.Position
+					s.Squeeze = called
+				case 3:
+					s.FourBettor = pa.Position
+				}
+				aggressor = pa.Position
+				s.Callers = nil
+			}
+		}
+	}
+
+	for _, pos := range h.Seats() {
+		if !folded[pos] {
+			s.Players = append(s.Players, pos)
+		}
+	}
+	switch {
+	case raises == 0 && len(s.Players) < 2:
+		s.Type = Walk
+	case raises == 0:
+		s.Type = Limped
+		s.Callers = s.Limpers
+	case raises == 1:
+		s.Type = SingleRaised
+	case raises == 2:
+		s.Type = ThreeBet
+	default:
+		s.Type = FourBet
+	}
+
+	// Players act after the flop in seat order from the button, so the player
+	// farthest from it is in position.
+	size := h.TableSize()
+	var last PlayerPosition
+	distance := -1
+	for _, pos := range s.Players {
+		d := (int(pos) - int(h.Button) - 1 + size) % size
+		if d > distance {
+			last, distance = pos, d
+		}
+	}
+	for _, pos := range s.Players {
+		s.Roles[pos] = SpotRole{Aggressor: pos == aggressor, InPosition: pos == last &&
+			len(s.Players) > 1}
+	}
+	return s
+}
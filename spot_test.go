@@ -0,0 +1,132 @@
+package poker
+
+import (
+	"reflect"
+	"testing"
+)
+
+// spotHand creates a six player hand with the button in seat 6 and the given
+// actions before the flop.
+func spotHand(actions ...PlayerAction) *Hand {
+	h := &Hand{
+		Table:      Table{Size: 6, Stakes: Stakes{SmallBlind: 5, BigBlind: 10}},
+		Button:     6,
+		SmallBlind: 1,
+		BigBlind:   2,
+		Rounds:     []Round{{Actions: actions}},
+	}
+	for i := 0; i < 6; i++ {
+		h.Sit(PlayerPosition(i+1), Player{Name: string(rune('a' + i)), Stack: 1000})
+	}
+	return h
+}
+
+// Hand.Spot() /////////////////////////////////////////////////////////////////
+
+func TestSpot(t *testing.T) {
+
+	fold := func(pos PlayerPosition) PlayerAction {
+		return PlayerAction{pos, NewFoldAction()}
+	}
+	check := func(pos PlayerPosition) PlayerAction {
+		return PlayerAction{pos, NewCheckAction()}
+	}
+	call := func(pos PlayerPosition) PlayerAction {
+		return PlayerAction{pos, NewCallAction(10)}
+	}
+	raise := func(pos PlayerPosition, to Amount) PlayerAction {
+		return PlayerAction{pos, NewRaiseAction(to)}
+	}
+
+	type testPair struct {
+		actions []PlayerAction
+		output  Spot
+	}
+	tests := []testPair{
+		{[]PlayerAction{fold(3), fold(4), fold(5), fold(6), fold(1)}, Spot{
+			Type:    Walk,
+			Players: []PlayerPosition{2},
+			Roles:   map[PlayerPosition]SpotRole{2: {}},
+		}},
+		{[]PlayerAction{call(3), fold(4), fold(5), fold(6), call(1), check(2)}, Spot{
+			Type:    Limped,
+			Limpers: []PlayerPosition{3, 1},
+			Callers: []PlayerPosition{3, 1},
+			Players: []PlayerPosition{1, 2, 3},
+			Roles: map[PlayerPosition]SpotRole{1: {}, 2: {},
+				3: {InPosition: true}},
+		}},
+		{[]PlayerAction{fold(3), raise(4, 30), fold(5), call(6), fold(1), call(2)}, Spot{
+			Type:    SingleRaised,
+			Opener:  4,
+			Callers: []PlayerPosition{6, 2},
+			Players: []PlayerPosition{2, 4, 6},
+			Roles: map[PlayerPosition]SpotRole{2: {}, 4: {Aggressor: true},
+				6: {InPosition: true}},
+		}},
+		// The opener isolates a limper.
+		{[]PlayerAction{call(3), raise(4, 40), fold(5), fold(6), fold(1), fold(2),
+			call(3)}, Spot{
+			Type:     SingleRaised,
+			Limpers:  []PlayerPosition{3},
+			Opener:   4,
+			Callers:  []PlayerPosition{3},
+			Isolated: true,
+			Players:  []PlayerPosition{3, 4},
+			Roles: map[PlayerPosition]SpotRole{3: {},
+				4: {Aggressor: true, InPosition: true}},
+		}},
+		// The three-bet squeezes the opener and a caller.
+		{[]PlayerAction{raise(3, 30), call(4), raise(5, 120), fold(6), fold(1), fold(2),
+			fold(3), fold(4)}, Spot{
+			Type:        ThreeBet,
+			Opener:      3,
+			ThreeBettor: 5,
+			Squeeze:     true,
+			Players:     []PlayerPosition{5},
+			Roles:       map[PlayerPosition]SpotRole{5: {Aggressor: true}},
+		}},
+		{[]PlayerAction{raise(3, 30), raise(4, 90), fold(5), fold(6), fold(1), fold(2),
+			raise(3, 250), call(4)}, Spot{
+			Type:        FourBet,
+			Opener:      3,
+			ThreeBettor: 4,
+			FourBettor:  3,
+			Callers:     []PlayerPosition{4},
+			Players:     []PlayerPosition{3, 4},
+			Roles: map[PlayerPosition]SpotRole{3: {Aggressor: true},
+				4: {InPosition: true}},
+		}},
+	}
+
+	for i, test := range tests {
+		s := spotHand(test.actions...).Spot()
+		if !reflect.DeepEqual(*s, test.output) {
+			t.Errorf("For test %v expected %+v, got %+v", i, test.output, *s)
+		}
+	}
+
+	if s := spotHand(tests[2].actions...).Spot(); !s.Multiway() || s.HeadsUp() {
+		t.Errorf("Expected a multiway pot")
+	}
+	if s := spotHand(tests[5].actions...).Spot(); s.Multiway() || !s.HeadsUp() {
+		t.Errorf("Expected a heads-up pot")
+	}
+}
+
+// ParsePotType() //////////////////////////////////////////////////////////////
+
+func TestParsePotType(t *testing.T) {
+
+	for pt := Walk; pt <= FourBet; pt++ {
+		if parsed, err := ParsePotType(pt.String()); err != nil || parsed != pt {
+			t.Errorf("For %v expected %v, got %v (%v)", pt, int(pt), parsed, err)
+		}
+	}
+	if pt, err := ParsePotType("srp"); err != nil || pt != SingleRaised {
+		t.Errorf("Expected SRP, got %v (%v)", pt, err)
+	}
+	if _, err := ParsePotType("5bet"); err == nil {
+		t.Errorf("Expected error")
+	}
+}
@@ -0,0 +1,374 @@
+// Package stats accumulates the statistics of players shown by heads-up
+// displays, such as VPIP, PFR and aggression, from their hands.
+//
+// Each statistic counts the opportunities a player had to do something and the
+// times they did it, per player and per position. Accumulators of different
+// hands can be merged, so that the statistics of many hands can be accumulated
+// in parallel.
+package stats
+
+import (
+	poker "github.com/whomever000/poker-common"
+)
+
+// Streets, as the index of the betting round of a hand
+const (
+	Preflop = iota
+	Flop
+	Turn
+	River
+
+	// NumStreets is the number of streets with statistics.
+	NumStreets
+)
+
+// Counter counts the opportunities a player had to do something, and the times
+// they did.
+type Counter struct {
+
+	// Opportunities is the number of times the player could do it.
+	Opportunities int
+
+	// Count is the number of times the player did it.
+	Count int
+}
+
+// Percent returns how often the player did it when they could, in percent, or
+// 0 if they never could.
+func (c Counter) Percent() float64 {
+	if c.Opportunities == 0 {
+		return 0
+	}
+	return 100 * float64(c.Count) / float64(c.Opportunities)
+}
+
+// Add adds the counts of another counter.
+func (c *Counter) Add(o Counter) {
+	c.Opportunities += o.Opportunities
+	c.Count += o.Count
+}
+
+// Aggression counts the actions of a player.
+type Aggression struct {
+	Bets   int
+	Raises int
+	Calls  int
+	Checks int
+	Folds  int
+}
+
+// Factor returns the aggression factor, i.e. bets and raises per call, or 0 if
+// the player never called.
+func (a Aggression) Factor() float64 {
+	if a.Calls == 0 {
+		return 0
+	}
+	return float64(a.Bets+a.Raises) / float64(a.Calls)
+}
+
+// Add adds the counts of another aggression.
+func (a *Aggression) Add(o Aggression) {
+	a.Bets += o.Bets
+	a.Raises += o.Raises
+	a.Calls += o.Calls
+	a.Checks += o.Checks
+	a.Folds += o.Folds
+}
+
+// Street are the statistics of a player on a street.
+type Street struct {
+
+	// Saw is the number of hands in which the player saw the street.
+	Saw int
+
+	// Aggression counts the player's actions on the street.
+	Aggression
+
+	// CBet counts the continuation bets, i.e. bets by the last aggressor of
+	// the previous street when nobody bet before them. There are no
+	// continuation bets before the flop.
+	CBet Counter
+}
+
+// Add adds the statistics of another street.
+func (s *Street) Add(o *Street) {
+	s.Saw += o.Saw
+	s.Aggression.Add(o.Aggression)
+	s.CBet.Add(o.CBet)
+}
+
+// Counters are the statistics of a player's hands.
+type Counters struct {
+
+	// Hands is the number of hands the player was dealt in.
+	Hands int
+
+	// VPIP counts the hands in which the player voluntarily put money in the
+	// pot before the flop, i.e. called or raised.
+	VPIP Counter
+
+	// PFR counts the hands in which the player raised before the flop.
+	PFR Counter
+
+	// ThreeBet counts the re-raises of a single raise before the flop.
+	ThreeBet Counter
+
+	// WTSD counts the hands in which the player went to showdown after seeing
+	// the flop.
+	WTSD Counter
+
+	// WSD counts the hands the player won money in after going to showdown,
+	// also known as W$SD.
+	WSD Counter
+
+	// Streets are the statistics by street.
+	Streets [NumStreets]Street
+
+	// Won is the amount won less the amount put in the pot, in the hands with
+	// a result. It is only meaningful for hands in a single currency.
+	Won poker.Amount
+
+	// WonBigBlinds is the amount won less the amount put in the pot, in big
+	// blinds of the stakes of each hand.
+	WonBigBlinds float64
+}
+
+// Winnings returns the amount won less the amount put in the pot in a unit, in
+// units of the currency rather than cents for native amounts.
+func (c *Counters) Winnings(u poker.Unit) float64 {
+	if u == poker.BigBlinds {
+		return c.WonBigBlinds
+	}
+	return float64(c.Won) / 100
+}
+
+// WinRate returns the winnings per 100 hands in a unit, e.g. in bb/100, or 0
+// if there are no hands.
+func (c *Counters) WinRate(u poker.Unit) float64 {
+	if c.Hands == 0 {
+		return 0
+	}
+	return 100 * c.Winnings(u) / float64(c.Hands)
+}
+
+// AggressionFactor returns the aggression factor after the flop.
+func (c *Counters) AggressionFactor() float64 {
+	var a Aggression
+	for i := Flop; i < NumStreets; i++ {
+		a.Add(c.Streets[i].Aggression)
+	}
+	return a.Factor()
+}
+
+// Add adds the statistics of other hands.
+func (c *Counters) Add(o *Counters) {
+	c.Hands += o.Hands
+	c.VPIP.Add(o.VPIP)
+	c.PFR.Add(o.PFR)
+	c.ThreeBet.Add(o.ThreeBet)
+	c.WTSD.Add(o.WTSD)
+	c.WSD.Add(o.WSD)
+	for i := 0; i < NumStreets; i++ {
+		c.Streets[i].Add(&o.Streets[i])
+	}
+	c.Won += o.Won
+	c.WonBigBlinds += o.WonBigBlinds
+}
+
+// Stats are the statistics of a player, in total and by position.
+type Stats struct {
+	Counters
+
+	// Positions are the statistics by position. Hands with too many players
+	// to name positions are counted as NoPosition.
+	Positions map[poker.Position]*Counters
+}
+
+// newStats creates empty statistics.
+func newStats() *Stats {
+	return &Stats{Positions: make(map[poker.Position]*Counters)}
+}
+
+// position returns the statistics of a position, which are created if needed.
+func (s *Stats) position(p poker.Position) *Counters {
+	c, ok := s.Positions[p]
+	if !ok {
+		c = &Counters{}
+		s.Positions[p] = c
+	}
+	return c
+}
+
+// Add adds the statistics of another player.
+func (s *Stats) Add(o *Stats) {
+	s.Counters.Add(&o.Counters)
+	for p, c := range o.Positions {
+		s.position(p).Add(c)
+	}
+}
+
+// Accumulator accumulates the statistics of players by name. An accumulator
+// is not safe for concurrent use; accumulate shards of hands in separate
+// accumulators and merge them instead.
+type Accumulator struct {
+
+	// Players are the statistics by player name.
+	Players map[string]*Stats
+}
+
+// NewAccumulator creates an empty accumulator.
+func NewAccumulator() *Accumulator {
+	return &Accumulator{Players: make(map[string]*Stats)}
+}
+
+// player returns the statistics of a player, which are created if needed.
+func (a *Accumulator) player(name string) *Stats {
+	s, ok := a.Players[name]
+	if !ok {
+		s = newStats()
+		a.Players[name] = s
+	}
+	return s
+}
+
+// Add adds the statistics of the players dealt in a hand.
+func (a *Accumulator) Add(h *poker.Hand) {
+	positions := h.Positions()
+	for pos, c := range HandCounters(h) {
+		s := a.player(pos.Player(h).Name)
+		s.Counters.Add(c)
+		s.position(positions[pos]).Add(c)
+	}
+}
+
+// Merge adds the statistics of another accumulator.
+func (a *Accumulator) Merge(o *Accumulator) {
+	for name, s := range o.Players {
+		a.player(name).Add(s)
+	}
+}
+
+// HandCounters returns the statistics of each player dealt in a hand. Going to
+// showdown and winnings are only counted for hands with a result.
+func HandCounters(h *poker.Hand) map[poker.PlayerPosition]*Counters {
+
+	seats := h.Seats()
+	counters := make(map[poker.PlayerPosition]*Counters)
+	for _, pos := range seats {
+		c := &Counters{Hands: 1}
+		c.VPIP.Opportunities = 1
+		c.PFR.Opportunities = 1
+		counters[pos] = c
+	}
+
+	folded := make(map[poker.PlayerPosition]bool)
+	var aggressor poker.PlayerPosition
+	for r := 0; r < len(h.Rounds) && r < NumStreets; r++ {
+		for _, pos := range seats {
+			if !folded[pos] {
+				counters[pos].Streets[r].Saw++
+			}
+		}
+
+		// Raises counts the bets and raises so far, and last is the player who
+		// made the last of them.
+		var raises int
+		var last poker.PlayerPosition
+		for _, pa := range h.Rounds[r].Actions {
+			c, ok := counters[pa.Position]
+			if !ok {
+				continue
+			}
+			s := &c.Streets[r]
+			t := pa.Action.Type()
+			aggressive := t == poker.Bet || t == poker.Raise
+
+			switch t {
+			case poker.Fold:
+				s.Folds++
+				folded[pa.Position] = true
+			case poker.Check:
+				s.Checks++
+			case poker.Call:
+				s.Calls++
+			case poker.Bet:
+				s.Bets++
+			case poker.Raise:
+				s.Raises++
+			}
+
+			if r == Preflop {
+				if t == poker.Call || aggressive {
+					c.VPIP.Count = 1
+				}
+				if aggressive {
+					c.PFR.Count = 1
+				}
+				if raises == 1 && c.ThreeBet.Opportunities == 0 {
+					c.ThreeBet.Opportunities = 1
+					if aggressive {
+						c.ThreeBet.Count = 1
+					}
+				}
+			} else if pa.Position == aggressor && raises == 0 && s.CBet.Opportunities == 0 {
+				s.CBet.Opportunities = 1
+				if aggressive {
+					s.CBet.Count = 1
+				}
+			}
+
+			if aggressive {
+				raises++
+				last = pa.Position
+			}
+		}
+		aggressor = last
+	}
+
+	if h.Result == nil {
+		return counters
+	}
+	var remaining int
+	for _, pos := range seats {
+		if !folded[pos] {
+			remaining++
+		}
+	}
+	winnings := h.Result.Winnings()
+	if s := finalState(h); s != nil {
+		for _, pos := range seats {
+			won := s.Stacks[pos] + winnings[pos] - pos.Player(h).Stack
+			counters[pos].Won = won
+			counters[pos].WonBigBlinds = h.BigBlinds(won)
+		}
+	}
+	for _, pos := range seats {
+		c := counters[pos]
+		if c.Streets[Flop].Saw == 0 {
+			continue
+		}
+		c.WTSD.Opportunities = 1
+		if remaining > 1 && !folded[pos] {
+			c.WTSD.Count = 1
+			c.WSD.Opportunities = 1
+			if winnings[pos] > 0 {
+				c.WSD.Count = 1
+			}
+		}
+	}
+	return counters
+}
+
+// finalState returns the table state after the last action of a hand, with
+// uncalled bets returned, or nil if the hand cannot be replayed.
+func finalState(h *poker.Hand) *poker.TableState {
+	r := poker.Replay(h)
+	for r.Next() {
+	}
+	if r.Err() != nil {
+		return nil
+	}
+	s := r.State()
+	s.NextRound()
+	return s
+}
@@ -0,0 +1,211 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	poker "github.com/whomever000/poker-common"
+)
+
+const testShowDownHistory = `PokerStars Hand #1001: Hold'em No Limit ($0.05/$0.10 USD) - 2016/03/04 20:15:42 UTC
+Table 'Alcyone' 6-max Seat #4 is the button
+Seat 1: dave ($3 in chips)
+Seat 2: erin ($10 in chips)
+Seat 4: frank ($6.50 in chips)
+Seat 6: grace ($10 in chips)
+grace: posts small blind $0.05
+dave: posts big blind $0.10
+*** HOLE CARDS ***
+Dealt to erin [Qh Qd]
+erin: raises $0.20 to $0.30
+frank: calls $0.30
+grace: folds
+dave: calls $0.20
+*** FLOP *** [2c 7d Kh]
+dave: bets $2.70 and is all-in
+erin: raises $4 to $6.70
+frank: calls $6.20 and is all-in
+Uncalled bet ($0.50) returned to erin
+*** TURN *** [2c 7d Kh] [3s]
+*** RIVER *** [2c 7d Kh 3s] [9c]
+*** SHOW DOWN ***
+erin: shows [Qh Qd] (a pair of Queens)
+frank: shows [Kd Js] (a pair of Kings)
+dave: mucks hand
+frank collected $6.80 from side pot-1
+frank collected $8.70 from main pot
+*** SUMMARY ***
+Total pot $16.05 Main pot $8.70. Side pot-1 $6.80. | Rake $0.55
+Board [2c 7d Kh 3s 9c]
+Seat 1: dave (big blind) mucked [Ac 4c]
+Seat 2: erin showed [Qh Qd] and lost with a pair of Queens
+Seat 4: frank (button) showed [Kd Js] and won ($15.50) with a pair of Kings
+Seat 6: grace (small blind) folded before Flop
+`
+
+const testCBetHistory = `PokerStars Hand #1002: Hold'em No Limit ($0.05/$0.10/$0.20 USD) - 2016/03/04 20:15:42 UTC
+Table 'Alcyone' 6-max Seat #1 is the button
+Seat 1: dave ($10 in chips)
+Seat 2: erin ($10 in chips)
+Seat 3: frank ($10 in chips)
+Seat 4: grace ($10 in chips)
+erin: posts small blind $0.05
+frank: posts big blind $0.10
+grace: posts straddle $0.20
+*** HOLE CARDS ***
+dave: raises $0.20 to $0.40
+erin: folds
+frank: folds
+grace: calls $0.20
+*** FLOP *** [2c 7d Kh]
+grace: checks
+dave: bets $0.50
+grace: folds
+Uncalled bet ($0.50) returned to dave
+dave collected $0.95 from pot
+*** SUMMARY ***
+Total pot $0.95 | Rake $0
+Board [2c 7d Kh]
+Seat 1: dave (button) collected ($0.95)
+Seat 2: erin (small blind) folded before Flop
+Seat 3: frank (big blind) folded before Flop
+Seat 4: grace folded on the Flop
+`
+
+// testHands parses the test histories.
+func testHands(t *testing.T) []*poker.Hand {
+	var hands []*poker.Hand
+	for _, history := range []string{testShowDownHistory, testCBetHistory} {
+		h, err := poker.ParseHand(history)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hands = append(hands, h)
+	}
+	return hands
+}
+
+// HandCounters() //////////////////////////////////////////////////////////////
+
+func TestHandCounters(t *testing.T) {
+
+	hands := testHands(t)
+
+	type testPair struct {
+		hand                            int
+		pos                             poker.PlayerPosition
+		vpip, pfr, threeBet, cbet       Counter
+		wtsd, wsd                       Counter
+		flopBets, flopRaises, flopCalls int
+	}
+	tests := []testPair{
+		{0, 1, Counter{1, 1}, Counter{1, 0}, Counter{1, 0}, Counter{}, Counter{1, 1},
+			Counter{1, 0}, 1, 0, 0},
+		{0, 2, Counter{1, 1}, Counter{1, 1}, Counter{}, Counter{}, Counter{1, 1},
+			Counter{1, 0}, 0, 1, 0},
+		{0, 4, Counter{1, 1}, Counter{1, 0}, Counter{1, 0}, Counter{}, Counter{1, 1},
+			Counter{1, 1}, 0, 0, 1},
+		{0, 6, Counter{1, 0}, Counter{1, 0}, Counter{1, 0}, Counter{}, Counter{},
+			Counter{}, 0, 0, 0},
+		{1, 1, Counter{1, 1}, Counter{1, 1}, Counter{}, Counter{1, 1}, Counter{1, 0},
+			Counter{}, 1, 0, 0},
+		{1, 4, Counter{1, 1}, Counter{1, 0}, Counter{1, 0}, Counter{}, Counter{1, 0},
+			Counter{}, 0, 0, 0},
+	}
+
+	for _, test := range tests {
+		c := HandCounters(hands[test.hand])[test.pos]
+		s := c.Streets[Flop]
+		if c.Hands != 1 || c.VPIP != test.vpip || c.PFR != test.pfr ||
+			c.ThreeBet != test.threeBet || s.CBet != test.cbet || c.WTSD != test.wtsd ||
+			c.WSD != test.wsd || s.Bets != test.flopBets || s.Raises != test.flopRaises ||
+			s.Calls != test.flopCalls {
+			t.Errorf("For hand %v seat %v unexpected counters %+v", test.hand, test.pos, c)
+		}
+	}
+}
+
+// Accumulator.Merge() /////////////////////////////////////////////////////////
+
+func TestAccumulator(t *testing.T) {
+
+	hands := testHands(t)
+
+	all := NewAccumulator()
+	for _, h := range hands {
+		all.Add(h)
+	}
+	dave := all.Players["dave"]
+	if dave.Hands != 2 || dave.VPIP.Percent() != 100 || dave.PFR.Percent() != 50 {
+		t.Errorf("Unexpected statistics %+v", dave.Counters)
+	}
+	if dave.Positions[poker.BB].Hands != 1 || dave.Positions[poker.BTN].Hands != 1 ||
+		dave.Positions[poker.BTN].Streets[Flop].CBet != (Counter{1, 1}) {
+		t.Errorf("Unexpected statistics by position %+v", dave.Positions)
+	}
+
+	// Shards merge to the same statistics.
+	merged := NewAccumulator()
+	for _, h := range hands {
+		shard := NewAccumulator()
+		shard.Add(h)
+		merged.Merge(shard)
+	}
+	if !reflect.DeepEqual(all, merged) {
+		t.Errorf("Expected %+v, got %+v", all.Players, merged.Players)
+	}
+}
+
+// Aggression.Factor() /////////////////////////////////////////////////////////
+
+func TestAggressionFactor(t *testing.T) {
+
+	var c Counters
+	c.Streets[Preflop].Aggression = Aggression{Raises: 4}
+	c.Streets[Flop].Aggression = Aggression{Bets: 1, Raises: 2, Calls: 1}
+	c.Streets[River].Aggression = Aggression{Calls: 1}
+	if af := c.AggressionFactor(); af != 1.5 {
+		t.Errorf("Expected 1.5, got %v", af)
+	}
+	if af := (Aggression{Bets: 3}).Factor(); af != 0 {
+		t.Errorf("Expected 0, got %v", af)
+	}
+}
+
+// Counters.WinRate() //////////////////////////////////////////////////////////
+
+func TestWinRate(t *testing.T) {
+
+	h := testHands(t)[0]
+	counters := HandCounters(h)
+	type testPair struct {
+		pos    poker.PlayerPosition
+		won    poker.Amount
+		native float64
+		bb     float64
+	}
+	tests := []testPair{
+		{1, -300, -3, -30},
+		{2, -650, -6.5, -65},
+		{4, 900, 9, 90},
+		{6, -5, -0.05, -0.5},
+	}
+
+	for _, test := range tests {
+		c := counters[test.pos]
+		if c.Won != test.won || c.Winnings(poker.Native) != test.native ||
+			c.Winnings(poker.BigBlinds) != test.bb {
+			t.Errorf("For seat %v expected %v and %vbb, got %v and %vbb", test.pos,
+				test.won, test.bb, c.Won, c.WonBigBlinds)
+		}
+	}
+
+	c := *counters[4]
+	c.Add(counters[6])
+	if rate := c.WinRate(poker.BigBlinds); rate != 4475 {
+		t.Errorf("Expected 4475bb/100, got %v", rate)
+	}
+	if rate := (&Counters{}).WinRate(poker.Native); rate != 0 {
+		t.Errorf("Expected 0, got %v", rate)
+	}
+}
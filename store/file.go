@@ -0,0 +1,442 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	poker "github.com/whomever000/poker-common"
+)
+
+// errClosed is returned when a closed store is used.
+var errClosed = errors.New("store: closed")
+
+// record is a stored hand, as a line of JSON in the data file. The hand is
+// stored as its PokerStars hand history, with what the history does not
+// record: the date to the nanosecond, the tournament and the players'
+// statuses.
+type record struct {
+	Client     string                                `json:"client"`
+	HandID     int                                   `json:"id"`
+	Date       time.Time                             `json:"date"`
+	Stakes     string                                `json:"stakes"`
+	Game       string                                `json:"game"`
+	Players    []string                              `json:"players"`
+	History    string                                `json:"history"`
+	Tournament *poker.Tournament                     `json:"tournament,omitempty"`
+	Statuses   map[poker.PlayerPosition]poker.Status `json:"statuses,omitempty"`
+	Extensions map[string]json.RawMessage            `json:"extensions,omitempty"`
+}
+
+// key identifies a hand.
+type key struct {
+	client string
+	id     int
+}
+
+// entry is the index entry of a stored hand.
+type entry struct {
+	offset  int64
+	length  int
+	key     key
+	date    time.Time
+	stakes  string
+	game    string
+	players []string
+}
+
+// FileStore is a HandStore backed by a single file, to which hands are
+// appended. The indexes are kept in memory and built when the store is
+// opened. A FileStore is safe for concurrent use.
+type FileStore struct {
+	mu      sync.RWMutex
+	file    *os.File
+	size    int64
+	entries []*entry
+	keys    map[key]*entry
+	clients map[string][]*entry
+	players map[string][]*entry
+	stakes  map[string][]*entry
+	games   map[string][]*entry
+
+	// sorted is whether entries are sorted by date.
+	sorted bool
+}
+
+// Open opens the store in a file, which is created if it does not exist. An
+// incomplete record at the end of the file, e.g. after a crash, is removed.
+func Open(path string) (*FileStore, error) {
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("store: %v", err)
+	}
+	s := &FileStore{
+		file:    f,
+		keys:    make(map[key]*entry),
+		clients: make(map[string][]*entry),
+		players: make(map[string][]*entry),
+		stakes:  make(map[string][]*entry),
+		games:   make(map[string][]*entry),
+		sorted:  true,
+	}
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("store: %v", err)
+		}
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("store: %v: record at offset %v: %v", path, s.size, err)
+		}
+		s.index(&rec, s.size, len(line))
+		s.size += int64(len(line))
+	}
+	if err := f.Truncate(s.size); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("store: %v", err)
+	}
+	return s, nil
+}
+
+// index adds a record at an offset to the indexes.
+func (s *FileStore) index(rec *record, offset int64, length int) {
+	e := &entry{
+		offset:  offset,
+		length:  length,
+		key:     key{rec.Client, rec.HandID},
+		date:    rec.Date,
+		stakes:  rec.Stakes,
+		game:    rec.Game,
+		players: rec.Players,
+	}
+	if n := len(s.entries); n > 0 && e.date.Before(s.entries[n-1].date) {
+		s.sorted = false
+	}
+	s.entries = append(s.entries, e)
+	s.keys[e.key] = e
+	s.clients[rec.Client] = append(s.clients[rec.Client], e)
+	s.stakes[rec.Stakes] = append(s.stakes[rec.Stakes], e)
+	s.games[rec.Game] = append(s.games[rec.Game], e)
+	for _, name := range rec.Players {
+		s.players[name] = append(s.players[name], e)
+	}
+}
+
+// newRecord creates the record of a hand.
+func newRecord(h *poker.Hand) *record {
+	rec := &record{
+		Client:     h.Client,
+		HandID:     h.HandID,
+		Date:       time.Time(h.Date).UTC(),
+		Stakes:     h.Table.Stakes.String(),
+		History:    h.String(),
+		Tournament: h.Tournament,
+		Extensions: h.Extensions,
+	}
+	if h.Table.Game != nil {
+		rec.Game = h.Table.Game.String()
+	}
+	for _, pos := range h.Seats() {
+		rec.Players = append(rec.Players, pos.Player(h).Name)
+	}
+	for _, pos := range h.Occupied() {
+		if status := pos.Player(h).Status; status != poker.Active {
+			if rec.Statuses == nil {
+				rec.Statuses = make(map[poker.PlayerPosition]poker.Status)
+			}
+			rec.Statuses[pos] = status
+		}
+	}
+	return rec
+}
+
+// decode parses the hand of a record from its JSON.
+func decode(data []byte) (*poker.Hand, error) {
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	h, err := poker.ParseHand(rec.History)
+	if err != nil {
+		return nil, fmt.Errorf("hand %v: %v", rec.HandID, err)
+	}
+	h.Client = rec.Client
+	h.Date = poker.Date(rec.Date)
+	h.Tournament = rec.Tournament
+	for pos, p := range h.Players {
+		p.Status = rec.Statuses[pos]
+	}
+	h.Extensions = rec.Extensions
+	return h, nil
+}
+
+// Put stores hands, and returns the number stored. Hands already stored with
+// the same client and hand ID, including earlier hands of the same call, are
+// skipped. If a hand cannot be read back, e.g. one of an unknown game, no
+// hand is stored.
+func (s *FileStore) Put(hands ...*poker.Hand) (int, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return 0, errClosed
+	}
+
+	var buf bytes.Buffer
+	var records []*record
+	var lengths []int
+	seen := make(map[key]bool)
+	for _, h := range hands {
+		k := key{h.Client, h.HandID}
+		if _, ok := s.keys[k]; ok || seen[k] {
+			continue
+		}
+		seen[k] = true
+		rec := newRecord(h)
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return 0, fmt.Errorf("store: hand %v: %v", h.HandID, err)
+		}
+		if _, err := decode(data); err != nil {
+			return 0, fmt.Errorf("store: hand %v cannot be read back: %v", h.HandID, err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+		records = append(records, rec)
+		lengths = append(lengths, len(data)+1)
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	if _, err := s.file.WriteAt(buf.Bytes(), s.size); err != nil {
+		s.file.Truncate(s.size)
+		return 0, fmt.Errorf("store: %v", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return 0, fmt.Errorf("store: %v", err)
+	}
+	for i, rec := range records {
+		s.index(rec, s.size, lengths[i])
+		s.size += int64(lengths[i])
+	}
+	return len(records), nil
+}
+
+// read reads the hand of an entry.
+func (s *FileStore) read(e *entry) (*poker.Hand, error) {
+	if s.file == nil {
+		return nil, errClosed
+	}
+	data := make([]byte, e.length)
+	if _, err := s.file.ReadAt(data, e.offset); err != nil {
+		return nil, fmt.Errorf("store: %v", err)
+	}
+	h, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("store: record at offset %v: %v", e.offset, err)
+	}
+	return h, nil
+}
+
+// Get returns the hand of a client with a hand ID, or nil if it is not
+// stored.
+func (s *FileStore) Get(client string, id int) (*poker.Hand, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.keys[key{client, id}]
+	if !ok {
+		return nil, nil
+	}
+	return s.read(e)
+}
+
+// Len returns the number of hands stored.
+func (s *FileStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.entries)
+}
+
+// Query returns a cursor over the hands matching a query, in order of date.
+// The hands are those stored when the query is made.
+func (s *FileStore) Query(q Query) (Cursor, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil, errClosed
+	}
+	if !s.sorted {
+		sort.SliceStable(s.entries, func(i, j int) bool {
+			return s.entries[i].date.Before(s.entries[j].date)
+		})
+		s.sorted = true
+	}
+
+	// The smallest index of the query's fields gives the candidates.
+	candidates := s.entries
+	if !q.From.IsZero() || !q.To.IsZero() {
+		from := sort.Search(len(s.entries), func(i int) bool {
+			return !s.entries[i].date.Before(q.From)
+		})
+		to := len(s.entries)
+		if !q.To.IsZero() {
+			to = sort.Search(len(s.entries), func(i int) bool {
+				return !s.entries[i].date.Before(q.To)
+			})
+		}
+		if to < from {
+			to = from
+		}
+		candidates = s.entries[from:to]
+	}
+	indexed := false
+	narrow := func(index map[string][]*entry, value string) {
+		if len(index[value]) < len(candidates) {
+			candidates, indexed = index[value], true
+		}
+	}
+	if q.HandID != 0 {
+		candidates, indexed = nil, true
+		if e, ok := s.keys[key{q.Client, q.HandID}]; ok {
+			candidates = []*entry{e}
+		} else if q.Client == "" {
+			for _, e := range s.entries {
+				if e.key.id == q.HandID {
+					candidates = append(candidates, e)
+				}
+			}
+		}
+	}
+	if q.Client != "" {
+		narrow(s.clients, q.Client)
+	}
+	if q.Player != "" {
+		narrow(s.players, q.Player)
+	}
+	if q.Stakes != nil {
+		narrow(s.stakes, q.Stakes.String())
+	}
+	if q.Game != nil {
+		narrow(s.games, q.Game.String())
+	}
+
+	var matches []*entry
+	for _, e := range candidates {
+		if matchesQuery(e, &q) {
+			matches = append(matches, e)
+		}
+	}
+	if indexed {
+		// Index lists are in the order hands were stored.
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].date.Before(matches[j].date)
+		})
+	}
+	if q.Offset > len(matches) {
+		q.Offset = len(matches)
+	}
+	matches = matches[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(matches) {
+		matches = matches[:q.Limit]
+	}
+	return &fileCursor{store: s, entries: matches}, nil
+}
+
+// matchesQuery returns whether an entry matches all fields of a query.
+func matchesQuery(e *entry, q *Query) bool {
+	if q.Client != "" && e.key.client != q.Client {
+		return false
+	}
+	if q.HandID != 0 && e.key.id != q.HandID {
+		return false
+	}
+	if !q.From.IsZero() && e.date.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !e.date.Before(q.To) {
+		return false
+	}
+	if q.Stakes != nil && e.stakes != q.Stakes.String() {
+		return false
+	}
+	if q.Game != nil && e.game != q.Game.String() {
+		return false
+	}
+	if q.Player != "" {
+		for _, name := range e.players {
+			if name == q.Player {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Close closes the store.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return errClosed
+	}
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return fmt.Errorf("store: %v", err)
+	}
+	return nil
+}
+
+// fileCursor is a cursor over the hands of a FileStore.
+type fileCursor struct {
+	store   *FileStore
+	entries []*entry
+	hand    *poker.Hand
+	err     error
+}
+
+// Next advances to the next hand.
+func (c *fileCursor) Next() bool {
+	if c.err != nil || len(c.entries) == 0 {
+		c.hand = nil
+		return false
+	}
+	c.store.mu.RLock()
+	c.hand, c.err = c.store.read(c.entries[0])
+	c.store.mu.RUnlock()
+	c.entries = c.entries[1:]
+	return c.err == nil
+}
+
+// Hand returns the current hand.
+func (c *fileCursor) Hand() *poker.Hand {
+	return c.hand
+}
+
+// Err returns the error which stopped the cursor, if any.
+func (c *fileCursor) Err() error {
+	return c.err
+}
+
+// Close releases the cursor.
+func (c *fileCursor) Close() error {
+	c.entries, c.hand = nil, nil
+	return nil
+}
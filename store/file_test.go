@@ -0,0 +1,203 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	poker "github.com/whomever000/poker-common"
+)
+
+const testHistory = `PokerStars Hand #1001: Hold'em No Limit ($0.05/$0.10 USD) - 2016/03/04 20:15:42 UTC
+Table 'Alcyone' 6-max Seat #4 is the button
+Seat 1: dave ($3 in chips)
+Seat 2: erin ($10 in chips)
+Seat 4: frank ($6.50 in chips)
+Seat 6: grace ($10 in chips)
+grace: posts small blind $0.05
+dave: posts big blind $0.10
+*** HOLE CARDS ***
+Dealt to erin [Qh Qd]
+erin: raises $0.20 to $0.30
+frank: calls $0.30
+grace: folds
+dave: calls $0.20
+*** FLOP *** [2c 7d Kh]
+dave: bets $2.70 and is all-in
+erin: raises $4 to $6.70
+frank: calls $6.20 and is all-in
+Uncalled bet ($0.50) returned to erin
+*** TURN *** [2c 7d Kh] [3s]
+*** RIVER *** [2c 7d Kh 3s] [9c]
+*** SHOW DOWN ***
+erin: shows [Qh Qd] (a pair of Queens)
+frank: shows [Kd Js] (a pair of Kings)
+dave: mucks hand
+frank collected $6.80 from side pot-1
+frank collected $8.70 from main pot
+*** SUMMARY ***
+Total pot $16.05 Main pot $8.70. Side pot-1 $6.80. | Rake $0.55
+Board [2c 7d Kh 3s 9c]
+Seat 1: dave (big blind) mucked [Ac 4c]
+Seat 2: erin showed [Qh Qd] and lost with a pair of Queens
+Seat 4: frank (button) showed [Kd Js] and won ($15.50) with a pair of Kings
+Seat 6: grace (small blind) folded before Flop
+`
+
+// testHands returns copies of the test hand with hand IDs 1, 2, ..., an hour
+// apart. From the third hand on, the stakes are higher and heidi replaces
+// grace.
+func testHands(t *testing.T, n int) []*poker.Hand {
+	var hands []*poker.Hand
+	for i := 0; i < n; i++ {
+		h, err := poker.ParseHand(testHistory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.HandID = i + 1
+		h.Date = poker.Date(time.Time(h.Date).Add(time.Duration(i) * time.Hour))
+		if i >= 2 {
+			h.Table.Stakes.SmallBlind, h.Table.Stakes.BigBlind = 10, 20
+			h.Players[6].Name = "heidi"
+		}
+		hands = append(hands, h)
+	}
+	return hands
+}
+
+// ids returns the hand IDs of the hands of a cursor.
+func ids(t *testing.T, c Cursor) []int {
+	defer c.Close()
+	var ids []int
+	for c.Next() {
+		ids = append(ids, c.Hand().HandID)
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+// FileStore ///////////////////////////////////////////////////////////////////
+
+func TestFileStore(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "hands.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hands := testHands(t, 4)
+
+	// Hands imported twice are stored once.
+	if n, err := s.Put(hands[3], hands[0], hands[1], hands[0]); err != nil || n != 3 {
+		t.Fatalf("Expected 3 hands stored, got %v (%v)", n, err)
+	}
+	if n, err := s.Put(hands...); err != nil || n != 1 {
+		t.Fatalf("Expected 1 hand stored, got %v (%v)", n, err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An incomplete record is removed when the store is opened.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"client":"PokerStars","id":5,`)
+	f.Close()
+
+	if s, err = Open(path); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if s.Len() != 4 {
+		t.Errorf("Expected 4 hands, got %v", s.Len())
+	}
+
+	h, err := s.Get("PokerStars", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(h, hands[1]) {
+		t.Errorf("Expected %+v, got %+v", hands[1], h)
+	}
+	if h, err := s.Get("Simulation", 2); h != nil || err != nil {
+		t.Errorf("Expected no hand, got %v (%v)", h, err)
+	}
+
+	date := time.Time(hands[0].Date)
+	stakes := hands[3].Table.Stakes
+	type testPair struct {
+		query  Query
+		output []int
+	}
+	tests := []testPair{
+		{Query{}, []int{1, 2, 3, 4}},
+		{Query{Client: "PokerStars", HandID: 3}, []int{3}},
+		{Query{HandID: 3}, []int{3}},
+		{Query{Client: "Simulation"}, nil},
+		{Query{Player: "grace"}, []int{1, 2}},
+		{Query{Player: "erin", Offset: 1, Limit: 2}, []int{2, 3}},
+		{Query{From: date.Add(time.Hour), To: date.Add(3 * time.Hour)}, []int{2, 3}},
+		{Query{Stakes: &stakes}, []int{3, 4}},
+		{Query{Stakes: &stakes, Player: "frank", From: date.Add(3 * time.Hour)}, []int{4}},
+		{Query{Game: poker.TexasHoldEmNoLimit, Limit: 1}, []int{1}},
+	}
+
+	for _, test := range tests {
+		c, err := s.Query(test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if output := ids(t, c); !reflect.DeepEqual(output, test.output) {
+			t.Errorf("For %+v expected %v, got %v", test.query, test.output, output)
+		}
+	}
+}
+
+func TestFileStoreLossless(t *testing.T) {
+
+	s, err := Open(filepath.Join(t.TempDir(), "hands.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// The date to the nanosecond, the tournament and the statuses are not in
+	// the hand history.
+	h := testHands(t, 1)[0]
+	h.Date = poker.Date(time.Time(h.Date).Add(time.Nanosecond).In(time.Local))
+	h.Tournament = &poker.Tournament{ID: 3003, BuyIn: 440, Bounty: 450, Fee: 110,
+		Level: 4, Payouts: poker.Payouts{5000, 3000}, Progressive: true,
+		Bounties: map[poker.PlayerPosition]poker.Amount{1: 450, 2: 675}}
+	h.Sit(3, poker.Player{Name: "ivan", Stack: 500, Status: poker.MissedBlinds})
+	if n, err := s.Put(h); err != nil || n != 1 {
+		t.Fatalf("Expected 1 hand stored, got %v (%v)", n, err)
+	}
+
+	got, err := s.Get("PokerStars", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !time.Time(got.Date).Equal(time.Time(h.Date)) {
+		t.Errorf("Expected %v, got %v", h.Date, got.Date)
+	}
+	got.Date = h.Date
+	if !reflect.DeepEqual(got, h) {
+		t.Errorf("Expected %+v, got %+v", h, got)
+	}
+
+	// A hand which cannot be read back is not stored.
+	unknown := testHands(t, 2)[1]
+	unknown.Table.Game = nil
+	if n, err := s.Put(unknown); err == nil || n != 0 {
+		t.Errorf("Expected error, got %v hands stored", n)
+	}
+	if s.Len() != 1 {
+		t.Errorf("Expected 1 hand, got %v", s.Len())
+	}
+}
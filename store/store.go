@@ -0,0 +1,80 @@
+// Package store stores hands in a database embedded in the program, indexed
+// by hand, site, player, date, stakes and game.
+//
+// A hand is identified by its client and hand ID, so that a hand imported more
+// than once is only stored once.
+package store
+
+import (
+	"time"
+
+	poker "github.com/whomever000/poker-common"
+)
+
+// HandStore stores hands and queries them.
+type HandStore interface {
+
+	// Put stores hands, and returns the number stored. Hands already stored
+	// with the same client and hand ID are skipped.
+	Put(hands ...*poker.Hand) (int, error)
+
+	// Get returns the hand of a client with a hand ID, or nil if it is not
+	// stored.
+	Get(client string, id int) (*poker.Hand, error)
+
+	// Query returns a cursor over the hands matching a query, in order of
+	// date.
+	Query(q Query) (Cursor, error)
+
+	// Len returns the number of hands stored.
+	Len() int
+
+	// Close closes the store.
+	Close() error
+}
+
+// Query selects hands. The zero value of each field matches all hands.
+type Query struct {
+
+	// Client is the site, as in the client of the hands.
+	Client string
+
+	// HandID is the hand ID.
+	HandID int
+
+	// Player is the name of a player dealt in.
+	Player string
+
+	// From and To limit the dates of the hands to [From, To).
+	From, To time.Time
+
+	// Stakes are the stakes.
+	Stakes *poker.Stakes
+
+	// Game is the game.
+	Game poker.Game
+
+	// Offset is the number of matching hands to skip.
+	Offset int
+
+	// Limit is the most hands returned, or 0 for all.
+	Limit int
+}
+
+// Cursor iterates over the hands of a query. Hands are read as the cursor
+// advances.
+type Cursor interface {
+
+	// Next advances to the next hand, and returns false when there are no
+	// more hands or an error occurred.
+	Next() bool
+
+	// Hand returns the current hand.
+	Hand() *poker.Hand
+
+	// Err returns the error which stopped the cursor, if any.
+	Err() error
+
+	// Close releases the cursor.
+	Close() error
+}
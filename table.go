@@ -3,6 +3,8 @@ package poker
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -30,29 +32,190 @@ type Stakes struct {
 
 	// BigBlind is the size of the big blind.
 	BigBlind Amount
+
+	// Ante is the ante posted by every player before the blinds, or 0.
+	Ante Amount
+
+	// BigBlindAnte is the ante posted by the big blind for every player, or 0.
+	BigBlindAnte Amount
+
+	// Straddle is the blind posted by the player left of the big blind, who
+	// acts last before the flop, or 0.
+	Straddle Amount
+
+	// BringIn is the forced bet of the lowest up card in stud games, or 0.
+	BringIn Amount
+
+	// SmallBet and BigBet are the bet sizes of the early and the late betting
+	// rounds in limit games, or 0.
+	SmallBet, BigBet Amount
+
+	// Currency is the currency of the stakes. The zero value is US dollars.
+	Currency Currency
+}
+
+// currency returns the currency of the stakes.
+func (s Stakes) currency() Currency {
+	if s.Currency.Code == "" {
+		return USD
+	}
+	return s.Currency
 }
 
 // String returns a string representation of the stakes in the form
-// '$0.01/$0.02 USD'.
+// '$0.01/$0.02 USD', '€0.01/€0.02 EUR' or '10/20 chips', followed by the
+// straddle and the other forced bets, e.g. '$0.25/$0.50/$1 USD - Ante $0.05'.
 func (s Stakes) String() string {
-	return fmt.Sprintf("%v/%v USD", s.SmallBlind, s.BigBlind)
+
+	c := s.currency()
+	str := s.Format(s.SmallBlind, Native) + "/" + s.Format(s.BigBlind, Native)
+	if s.Straddle > 0 {
+		str += "/" + s.Format(s.Straddle, Native)
+	}
+	if c.Suffix {
+		str += " " + c.Symbol
+	} else {
+		str += " " + c.Code
+	}
+
+	if s.Ante > 0 {
+		str += " - Ante " + s.Format(s.Ante, Native)
+	}
+	if s.BigBlindAnte > 0 {
+		str += " - BB Ante " + s.Format(s.BigBlindAnte, Native)
+	}
+	if s.BringIn > 0 {
+		str += " - Bring-in " + s.Format(s.BringIn, Native)
+	}
+	if s.SmallBet > 0 || s.BigBet > 0 {
+		str += " - Bets " + s.Format(s.SmallBet, Native) + "/" + s.Format(s.BigBet, Native)
+	}
+	return str
+}
+
+// limitRegexp matches stakes in the form 'NL10', whose big blind is the
+// number of cents.
+var limitRegexp = regexp.MustCompile(`(?i)^(?:NL|PL)\s*(\d+)$`)
+
+// smallBlinds are the small blinds of the 'NL' stakes whose small blind is not
+// half the big blind, by big blind, as dealt online and in casinos.
+var smallBlinds = map[int]int{
+	5:    2,    // $0.02/$0.05
+	25:   10,   // $0.10/$0.25
+	75:   25,   // $0.25/$0.75
+	300:  100,  // $1/$3
+	500:  200,  // $2/$5
+	2500: 1000, // $10/$25
 }
 
 // ParseStakes parses a string to a stakes object. The string must be in a format
-// similar to '$0.01/$0.02 USD'.
+// similar to '$0.01/$0.02 USD', '$0.25/$0.50/$1' with a straddle, or 'NL10',
+// optionally followed by forced bets as written by String, e.g.
+// '€0.02/€0.05 - Ante €0.01'. Amounts without a currency are in US dollars.
+// Stakes finer than hundredths, such as '$0.005/$0.01', are not supported; see
+// Amount.
 func ParseStakes(stakes string) (Stakes, error) {
 
-	var s Stakes
-	var err error
-
-	strs := strings.Split(stakes, "/")
-	s.SmallBlind, err = ParseAmount(strs[0])
+	parts := strings.Split(stakes, " - ")
+	s, err := parseBlinds(strings.TrimSpace(parts[0]))
 	if err != nil {
 		return Stakes{}, err
 	}
-	s.BigBlind, err = ParseAmount(strs[1])
-	if err != nil {
-		return Stakes{}, err
+	c := s.currency().WithPrecision(2)
+
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		var label, value string
+		for _, l := range []string{"BB Ante", "Ante", "Bring-in", "Bets"} {
+			if len(part) > len(l) && strings.EqualFold(part[:len(l)+1], l+" ") {
+				label, value = l, part[len(l)+1:]
+				break
+			}
+		}
+
+		amounts := strings.Split(value, "/")
+		if label == "" || len(amounts) != 1 && label != "Bets" ||
+			len(amounts) != 2 && label == "Bets" {
+			return Stakes{}, fmt.Errorf("failed to parse stakes: %v: unknown %q", stakes, part)
+		}
+		values := make([]Amount, len(amounts))
+		for i := 0; i < len(amounts); i++ {
+			m, err := ParseMoney(amounts[i], c)
+			if err != nil {
+				return Stakes{}, err
+			}
+			values[i] = m.Amount()
+		}
+
+		switch label {
+		case "BB Ante":
+			s.BigBlindAnte = values[0]
+		case "Ante":
+			s.Ante = values[0]
+		case "Bring-in":
+			s.BringIn = values[0]
+		case "Bets":
+			s.SmallBet, s.BigBet = values[0], values[1]
+		}
+	}
+	return s, nil
+}
+
+// parseBlinds parses the blinds and straddle of stakes, e.g. '$0.01/$0.02 USD'
+// or 'NL10'. The small blind of 'NL' stakes is half the big blind, except for
+// the stakes in smallBlinds.
+func parseBlinds(str string) (Stakes, error) {
+
+	if m := limitRegexp.FindStringSubmatch(str); m != nil {
+		bb, err := strconv.Atoi(m[1])
+		if err != nil || bb == 0 {
+			return Stakes{}, fmt.Errorf("failed to parse stakes: %v", str)
+		}
+		sb, ok := smallBlinds[bb]
+		if !ok {
+			if bb%2 != 0 {
+				return Stakes{}, fmt.Errorf("failed to parse stakes: %v", str)
+			}
+			sb = bb / 2
+		}
+		return Stakes{SmallBlind: Amount(sb), BigBlind: Amount(bb)}, nil
+	}
+
+	strs := strings.Split(str, "/")
+	if len(strs) != 2 && len(strs) != 3 {
+		return Stakes{}, fmt.Errorf("failed to parse stakes: %v", str)
+	}
+
+	c := USD
+	for _, str := range strs {
+		_, _, found, err := splitCurrency(str)
+		if err != nil {
+			return Stakes{}, err
+		}
+		if found.Code != "" {
+			c = found
+		}
+	}
+
+	blinds := make([]Amount, len(strs))
+	for i := 0; i < len(strs); i++ {
+		m, err := ParseMoney(strs[i], c)
+		if err != nil {
+			if _, err := ParseMoney(strs[i], c.WithPrecision(c.Precision+4)); err == nil {
+				return Stakes{}, fmt.Errorf("failed to parse stakes: %v: stakes finer "+
+					"than hundredths are not supported", str)
+			}
+			return Stakes{}, err
+		}
+		blinds[i] = m.Amount()
+	}
+
+	s := Stakes{SmallBlind: blinds[0], BigBlind: blinds[1]}
+	if len(blinds) == 3 {
+		s.Straddle = blinds[2]
+	}
+	if c != USD {
+		s.Currency = c
 	}
 	return s, nil
 }
@@ -118,6 +281,8 @@ func ParseGame(gameStr string) (Game, error) {
 		return TexasHoldEmNoLimit, nil
 	case "No Limit Hold'em":
 		return TexasHoldEmNoLimit, nil
+	case "Hold'em No Limit":
+		return TexasHoldEmNoLimit, nil
 	default:
 		return Unknown, fmt.Errorf("warning: Failed to parse game")
 	}
@@ -0,0 +1,68 @@
+package poker
+
+import (
+	"strings"
+	"testing"
+)
+
+// ParseStakes() ///////////////////////////////////////////////////////////////
+
+func TestParseStakes(t *testing.T) {
+
+	type testPair struct {
+		input  string
+		output Stakes
+		str    string
+	}
+	tests := []testPair{
+		{"$0.25/$0.50/$1", Stakes{SmallBlind: 25, BigBlind: 50, Straddle: 100},
+			"$0.25/$0.50/$1 USD"},
+		{"NL10", Stakes{SmallBlind: 5, BigBlind: 10}, "$0.05/$0.10 USD"},
+		{"nl25", Stakes{SmallBlind: 10, BigBlind: 25}, "$0.10/$0.25 USD"},
+		{"NL5", Stakes{SmallBlind: 2, BigBlind: 5}, "$0.02/$0.05 USD"},
+		{"PL200", Stakes{SmallBlind: 100, BigBlind: 200}, "$1/$2 USD"},
+		{"NL2", Stakes{SmallBlind: 1, BigBlind: 2}, "$0.01/$0.02 USD"},
+		{"NL50", Stakes{SmallBlind: 25, BigBlind: 50}, "$0.25/$0.50 USD"},
+		{"NL75", Stakes{SmallBlind: 25, BigBlind: 75}, "$0.25/$0.75 USD"},
+		{"NL300", Stakes{SmallBlind: 100, BigBlind: 300}, "$1/$3 USD"},
+		{"NL500", Stakes{SmallBlind: 200, BigBlind: 500}, "$2/$5 USD"},
+		{"NL2500", Stakes{SmallBlind: 1000, BigBlind: 2500}, "$10/$25 USD"},
+		{"NL5000", Stakes{SmallBlind: 2500, BigBlind: 5000}, "$25/$50 USD"},
+		{"€0.02/€0.05 - Ante €0.01", Stakes{SmallBlind: 2, BigBlind: 5, Ante: 1, Currency: EUR},
+			"€0.02/€0.05 EUR - Ante €0.01"},
+		{"$1/$2 USD - BB Ante $2", Stakes{SmallBlind: 100, BigBlind: 200, BigBlindAnte: 200},
+			"$1/$2 USD - BB Ante $2"},
+		{"$0.10/$0.20 USD - Bets $0.20/$0.40 - bring-in $0.05", Stakes{SmallBlind: 10,
+			BigBlind: 20, SmallBet: 20, BigBet: 40, BringIn: 5},
+			"$0.10/$0.20 USD - Bring-in $0.05 - Bets $0.20/$0.40"},
+		{"50/100 chips - Ante 10", Stakes{SmallBlind: 5000, BigBlind: 10000, Ante: 1000,
+			Currency: Chips}, "50/100 chips - Ante 10"},
+	}
+
+	for _, test := range tests {
+		s, err := ParseStakes(test.input)
+		if err != nil || s != test.output {
+			t.Errorf("For %q expected %+v, got %+v (%v)", test.input, test.output, s, err)
+			continue
+		}
+		if s.String() != test.str {
+			t.Errorf("For %q expected %v, got %v", test.input, test.str, s)
+		}
+		if again, err := ParseStakes(s.String()); err != nil || again != s {
+			t.Errorf("For %q expected the stakes to round trip, got %+v (%v)", test.input,
+				again, err)
+		}
+	}
+
+	if _, err := ParseStakes("$0.005/$0.01"); err == nil ||
+		!strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Expected sub-cent stakes not to be supported, got %v", err)
+	}
+
+	for _, str := range []string{"", "$0.01", "NL", "NL0", "NL15", "$1/$2/$4/$8",
+		"$1/$2 - Ante", "$1/$2 - Straddle $4", "$1/$2 - Ante €1", "$1/$2 - Bets $2"} {
+		if s, err := ParseStakes(str); err == nil {
+			t.Errorf("For %q expected error, got %+v", str, s)
+		}
+	}
+}
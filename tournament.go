@@ -0,0 +1,248 @@
+package poker
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tournament describes the tournament a hand is played in. Chip amounts in
+// tournament hands count one chip as 100, i.e. as if chips were dollars.
+type Tournament struct {
+
+	// ID is the tournament number.
+	ID int
+
+	// BuyIn is the part of the buy-in which goes to the prize pool.
+	BuyIn Amount
+
+	// Bounty is the part of the buy-in which goes to bounties, in knockout
+	// tournaments.
+	Bounty Amount
+
+	// Fee is the part of the buy-in which goes to the house.
+	Fee Amount
+
+	// Level is the blind level, starting at 1.
+	Level int
+
+	// Payouts are the prizes, if known.
+	Payouts Payouts
+
+	// Bounties are the bounties on the players' heads at the start of the
+	// hand, in knockout tournaments, if known.
+	Bounties map[PlayerPosition]Amount
+
+	// Progressive is whether half of a bounty won is added to the winner's
+	// own bounty, as in progressive knockout tournaments.
+	Progressive bool
+
+	// Currency is the currency of the buy-in. The zero value is US dollars.
+	Currency Currency
+}
+
+// currency returns the currency of the buy-in.
+func (t *Tournament) currency() Currency {
+	if t.Currency.Code == "" {
+		return USD
+	}
+	return t.Currency
+}
+
+// IsFreeroll returns whether the tournament has no buy-in.
+func (t *Tournament) IsFreeroll() bool {
+	return t.BuyIn == 0 && t.Bounty == 0 && t.Fee == 0
+}
+
+// BuyInString returns the buy-in in the form '$0.98+$0.02 USD' or
+// '€0.98+€0.02 EUR', or '$4.40+$4.50+$1.10 USD' with a bounty, or 'Freeroll'.
+func (t *Tournament) BuyInString() string {
+
+	if t.IsFreeroll() {
+		return "Freeroll"
+	}
+	s := Stakes{Currency: t.currency()}
+	str := s.Format(t.BuyIn, Native) + "+"
+	if t.Bounty > 0 {
+		str += s.Format(t.Bounty, Native) + "+"
+	}
+	str += s.Format(t.Fee, Native)
+	if s.Currency.Suffix {
+		return str + " " + s.Currency.Symbol
+	}
+	return str + " " + s.Currency.Code
+}
+
+// parseBuyInCurrency returns the currency of a buy-in as written by
+// BuyInString. US dollars are the zero value.
+func parseBuyInCurrency(str string) (Currency, error) {
+	parts := strings.Split(str, "+")
+	_, _, c, err := splitCurrency(parts[len(parts)-1])
+	if err != nil || c.Code == USD.Code {
+		return Currency{}, err
+	}
+	return c, nil
+}
+
+// ParseBuyIn parses a buy-in as written by BuyInString.
+func ParseBuyIn(str string) (buyIn, bounty, fee Amount, err error) {
+	str = strings.TrimSpace(str)
+	if strings.EqualFold(str, "Freeroll") {
+		return 0, 0, 0, nil
+	}
+
+	parts := strings.Split(str, "+")
+	amounts := make([]Amount, len(parts))
+	for i := 0; i < len(parts); i++ {
+		if amounts[i], err = ParseAmount(parts[i]); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	switch len(amounts) {
+	case 1:
+		return amounts[0], 0, 0, nil
+	case 2:
+		return amounts[0], 0, amounts[1], nil
+	case 3:
+		return amounts[0], amounts[1], amounts[2], nil
+	default:
+		return 0, 0, 0, fmt.Errorf("failed to parse buy-in: %v", str)
+	}
+}
+
+// Level is a level of a blind schedule.
+type Level struct {
+
+	// Stakes are the blinds and ante of the level.
+	Stakes Stakes
+
+	// Duration is the length of the level in time, or 0.
+	Duration time.Duration
+
+	// Hands is the length of the level in hands, or 0.
+	Hands int
+}
+
+// Schedule is a blind schedule. A level ends when its duration has passed or
+// its hands have been played. The last level never ends.
+type Schedule []Level
+
+// Level returns the index of the level after some time, and after some hands
+// have been played.
+func (s Schedule) Level(elapsed time.Duration, hands int) int {
+	var end time.Duration
+	var endHands int
+	for i := 0; i < len(s)-1; i++ {
+		end += s[i].Duration
+		endHands += s[i].Hands
+		timeLeft := s[i].Duration == 0 || elapsed < end
+		handsLeft := s[i].Hands == 0 || hands < endHands
+		if timeLeft && handsLeft {
+			return i
+		}
+	}
+	return len(s) - 1
+}
+
+// Stakes returns the stakes after some time, and after some hands have been
+// played.
+func (s Schedule) Stakes(elapsed time.Duration, hands int) Stakes {
+	if len(s) == 0 {
+		return Stakes{}
+	}
+	return s[s.Level(elapsed, hands)].Stakes
+}
+
+// Payouts are the prizes of a tournament by finishing place, i.e. Payouts[0]
+// is the prize for first place.
+type Payouts []Amount
+
+// Prize returns the prize for a place, starting at 1.
+func (p Payouts) Prize(place int) Amount {
+	if place < 1 || place > len(p) {
+		return 0
+	}
+	return p[place-1]
+}
+
+// Total returns the prize pool.
+func (p Payouts) Total() Amount {
+	var total Amount
+	for _, prize := range p {
+		total += prize
+	}
+	return total
+}
+
+// PayoutStructure is the share of the prize pool of each paid place, from
+// first place down.
+type PayoutStructure []float64
+
+// Common payout structures
+var (
+	WinnerTakesAll = PayoutStructure{1}
+	SitAndGo6Max   = PayoutStructure{0.65, 0.35}
+	SitAndGo9Max   = PayoutStructure{0.5, 0.3, 0.2}
+)
+
+// Payouts divides a prize pool, rounding each prize to the nearest cent.
+// Rounding leftovers go to first place.
+func (s PayoutStructure) Payouts(pool Amount) Payouts {
+	p := make(Payouts, len(s))
+	var paid Amount
+	for i := 0; i < len(s); i++ {
+		p[i] = Amount(math.Round(float64(pool) * s[i]))
+		paid += p[i]
+	}
+	if len(p) > 0 {
+		p[0] += pool - paid
+	}
+	return p
+}
+
+// chips formats a tournament amount as a number of chips, e.g. '1500'.
+func chips(a Amount) string {
+	return strconv.FormatFloat(float64(a)/100, 'f', -1, 64)
+}
+
+// romanNumerals are the roman numerals used for blind levels.
+var romanNumerals = []struct {
+	value  int
+	symbol string
+}{
+	{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"}, {100, "C"}, {90, "XC"},
+	{50, "L"}, {40, "XL"}, {10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+}
+
+// roman returns a number in roman numerals, e.g. 'XIV'.
+func roman(n int) string {
+	var b strings.Builder
+	for _, r := range romanNumerals {
+		for n >= r.value {
+			b.WriteString(r.symbol)
+			n -= r.value
+		}
+	}
+	return b.String()
+}
+
+// parseRoman parses a number in roman numerals, or in decimal digits.
+func parseRoman(str string) (int, error) {
+	if n, err := strconv.Atoi(str); err == nil {
+		return n, nil
+	}
+	n := 0
+	rest := strings.ToUpper(str)
+	for _, r := range romanNumerals {
+		for strings.HasPrefix(rest, r.symbol) {
+			n += r.value
+			rest = rest[len(r.symbol):]
+		}
+	}
+	if rest != "" || n == 0 {
+		return 0, fmt.Errorf("failed to parse level: %v", str)
+	}
+	return n, nil
+}
@@ -0,0 +1,160 @@
+package poker
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testTournamentHistory = `PokerStars Hand #2002: Tournament #3003, $4.40+$4.50+$1.10 USD Hold'em No Limit - Level IV (50/100) - 2016/03/04 20:15:42 UTC
+Table '3003 1' 9-max Seat #1 is the button
+Seat 1: dave (1500 in chips)
+Seat 2: erin (2250 in chips)
+Seat 3: frank (1275 in chips)
+dave: posts the ante 10
+erin: posts the ante 10
+frank: posts the ante 10
+erin: posts small blind 50
+frank: posts big blind 100
+*** HOLE CARDS ***
+Dealt to erin [Ah Kd]
+dave: folds
+erin: raises 200 to 300
+frank: folds
+Uncalled bet (200) returned to erin
+erin collected 230 from pot
+*** SUMMARY ***
+Total pot 230 | Rake 0
+Seat 1: dave (button) folded before Flop (didn't bet)
+Seat 2: erin (small blind) collected (230)
+Seat 3: frank (big blind) folded before Flop
+`
+
+// ParseHand() and String() ////////////////////////////////////////////////////
+
+func TestTournamentHand(t *testing.T) {
+
+	h, err := ParseHand(testTournamentHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Tournament{ID: 3003, BuyIn: 440, Bounty: 450, Fee: 110, Level: 4}
+	if !reflect.DeepEqual(h.Tournament, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, h.Tournament)
+	}
+	stakes := Stakes{SmallBlind: 5000, BigBlind: 10000, Ante: 1000}
+	if h.Table.Stakes != stakes || h.Players[1].Stack != 150000 {
+		t.Errorf("Unexpected stakes %v and stack %v", h.Table.Stakes, h.Players[1].Stack)
+	}
+	if v := h.Validate(); len(v) != 0 {
+		t.Errorf("Unexpected violations %v", v)
+	}
+	if h.String() != testTournamentHistory {
+		t.Errorf("Expected\n%v\ngot\n%v", testTournamentHistory, h.String())
+	}
+
+	// Freerolls have no buy-in.
+	h.Tournament = &Tournament{ID: 1, Level: 12}
+	h2, err := ParseHand(h.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(h2.Tournament, h.Tournament) {
+		t.Errorf("Expected %+v, got %+v", h.Tournament, h2.Tournament)
+	}
+
+	// Buy-ins are in the tournament's currency.
+	h.Tournament = &Tournament{ID: 1, BuyIn: 440, Bounty: 450, Fee: 110, Level: 4,
+		Currency: EUR}
+	if s := h.Tournament.BuyInString(); s != "€4.40+€4.50+€1.10 EUR" {
+		t.Errorf("Expected €4.40+€4.50+€1.10 EUR, got %v", s)
+	}
+	h2, err = ParseHand(h.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(h2.Tournament, h.Tournament) {
+		t.Errorf("Expected %+v, got %+v", h.Tournament, h2.Tournament)
+	}
+}
+
+// Schedule.Level() ////////////////////////////////////////////////////////////
+
+func TestScheduleLevel(t *testing.T) {
+
+	byTime := Schedule{
+		{Stakes: Stakes{SmallBlind: 1000, BigBlind: 2000}, Duration: 10 * time.Minute},
+		{Stakes: Stakes{SmallBlind: 1500, BigBlind: 3000}, Duration: 10 * time.Minute},
+		{Stakes: Stakes{SmallBlind: 2500, BigBlind: 5000, Ante: 500}},
+	}
+	byHands := Schedule{
+		{Stakes: Stakes{SmallBlind: 1000, BigBlind: 2000}, Hands: 10},
+		{Stakes: Stakes{SmallBlind: 1500, BigBlind: 3000}, Hands: 10, Duration: time.Minute},
+		{Stakes: Stakes{SmallBlind: 2500, BigBlind: 5000, Ante: 500}},
+	}
+
+	type testPair struct {
+		schedule Schedule
+		elapsed  time.Duration
+		hands    int
+		level    int
+	}
+	tests := []testPair{
+		{byTime, 0, 100, 0},
+		{byTime, 9 * time.Minute, 0, 0},
+		{byTime, 10 * time.Minute, 0, 1},
+		{byTime, 25 * time.Minute, 0, 2},
+		{byTime, 24 * time.Hour, 0, 2},
+		{byHands, time.Hour, 9, 0},
+		{byHands, 0, 10, 1},
+		{byHands, 0, 19, 1},
+		{byHands, 0, 20, 2},
+		// The level ends after a minute, before its hands are played.
+		{byHands, time.Minute, 11, 2},
+	}
+
+	for i := 0; i < len(tests); i++ {
+		test := tests[i]
+		if level := test.schedule.Level(test.elapsed, test.hands); level != test.level {
+			t.Errorf("For test %v expected level %v, got %v", i, test.level, level)
+		}
+	}
+
+	if s := byTime.Stakes(time.Hour, 0); s.Ante != 500 {
+		t.Errorf("Unexpected stakes %v", s)
+	}
+}
+
+// PayoutStructure.Payouts() ///////////////////////////////////////////////////
+
+func TestPayouts(t *testing.T) {
+
+	type testPair struct {
+		structure PayoutStructure
+		pool      Amount
+		payouts   Payouts
+	}
+	tests := []testPair{
+		{WinnerTakesAll, 1000, Payouts{1000}},
+		{SitAndGo6Max, 1000, Payouts{650, 350}},
+		{SitAndGo9Max, 1001, Payouts{501, 300, 200}},
+		// 100 * 0.29 is slightly less than 29.
+		{PayoutStructure{0.5, 0.29, 0.21}, 100, Payouts{50, 29, 21}},
+	}
+
+	for _, test := range tests {
+		payouts := test.structure.Payouts(test.pool)
+		if !reflect.DeepEqual(payouts, test.payouts) {
+			t.Errorf("For %v expected %v, got %v", test.structure, test.payouts, payouts)
+		}
+		if payouts.Total() != test.pool {
+			t.Errorf("For %v expected total %v, got %v", test.structure, test.pool, payouts.Total())
+		}
+	}
+
+	p := Payouts{650, 350}
+	if p.Prize(1) != 650 || p.Prize(2) != 350 || p.Prize(3) != 0 || p.Prize(0) != 0 {
+		t.Errorf("Unexpected prizes %v", p)
+	}
+}
@@ -0,0 +1,272 @@
+package poker
+
+import (
+	"fmt"
+
+	"github.com/whomever000/poker-common/card"
+)
+
+// ViolationKind is the kind of a hand consistency violation.
+type ViolationKind int
+
+// List of violation kinds
+const (
+	// ChipsNotConserved means that stacks, bets, pots, rake and winnings do
+	// not balance.
+	ChipsNotConserved ViolationKind = iota
+
+	// DuplicateCard means that a card appears more than once.
+	DuplicateCard
+
+	// InvalidCard means that a card is not a valid card.
+	InvalidCard
+
+	// BoardCardCount means that the number of board cards does not match the
+	// betting round.
+	BoardCardCount
+
+	// IllegalAction means that an action is out of order or not allowed.
+	IllegalAction
+
+	// ActionAfterFold means that a player acts after folding.
+	ActionAfterFold
+
+	// InvalidPosition means that a position is outside the table or refers to
+	// an empty seat.
+	InvalidPosition
+
+	// InvalidShowDown means that a player shows cards without being in the
+	// hand at showdown.
+	InvalidShowDown
+)
+
+// String returns the name of the violation kind.
+func (k ViolationKind) String() string {
+	switch k {
+	case ChipsNotConserved:
+		return "chips not conserved"
+	case DuplicateCard:
+		return "duplicate card"
+	case InvalidCard:
+		return "invalid card"
+	case BoardCardCount:
+		return "board card count"
+	case IllegalAction:
+		return "illegal action"
+	case ActionAfterFold:
+		return "action after fold"
+	case InvalidPosition:
+		return "invalid position"
+	case InvalidShowDown:
+		return "invalid showdown"
+	default:
+		return "unknown violation"
+	}
+}
+
+// Violation is an inconsistency found in a hand.
+type Violation struct {
+
+	// Kind is the kind of violation.
+	Kind ViolationKind
+
+	// Round is the index of the betting round, or -1 if the violation is not
+	// tied to a betting round.
+	Round int
+
+	// Action is the index of the action within the betting round, or -1 if
+	// the violation is not tied to an action.
+	Action int
+
+	// Message describes the violation.
+	Message string
+}
+
+// String returns a string representation of the violation.
+func (v Violation) String() string {
+	switch {
+	case v.Action >= 0:
+		return fmt.Sprintf("%v (round %v, action %v): %v", v.Kind, v.Round,
+			v.Action+1, v.Message)
+	case v.Round >= 0:
+		return fmt.Sprintf("%v (round %v): %v", v.Kind, v.Round, v.Message)
+	default:
+		return fmt.Sprintf("%v: %v", v.Kind, v.Message)
+	}
+}
+
+// boardCards is the number of board cards in each betting round.
+var boardCards = []int{0, 3, 4, 5}
+
+// Validate checks the hand for consistency, and returns all violations found.
+// A consistent hand returns no violations.
+func (h *Hand) Validate() []Violation {
+
+	var violations []Violation
+	add := func(kind ViolationKind, round, action int, format string,
+		args ...interface{}) {
+		violations = append(violations, Violation{kind, round, action,
+			fmt.Sprintf(format, args...)})
+	}
+
+	// Positions
+	checkPosition := func(name string, pos PlayerPosition, round, action int) {
+		if pos < 1 || int(pos) > h.Table.Size {
+			add(InvalidPosition, round, action, "%v is seat %v, table has %v seats",
+				name, pos, h.Table.Size)
+		} else if pos.Player(h) == nil {
+			add(InvalidPosition, round, action, "%v is empty seat %v", name, pos)
+		}
+	}
+
+	for _, pos := range h.Occupied() {
+		if pos < 1 || int(pos) > h.Table.Size {
+			add(InvalidPosition, -1, -1, "%v sits in seat %v, table has %v seats",
+				pos.Player(h).Name, pos, h.Table.Size)
+		}
+	}
+
+	// The button may be dead, i.e. on an empty seat.
+	if h.Button < 1 || int(h.Button) > h.Table.Size {
+		add(InvalidPosition, -1, -1, "button is seat %v, table has %v seats", h.Button,
+			h.Table.Size)
+	}
+	if h.SmallBlind != 0 {
+		checkPosition("small blind", h.SmallBlind, -1, -1)
+	}
+	if h.BigBlind != 0 {
+		checkPosition("big blind", h.BigBlind, -1, -1)
+	}
+	if h.ThisPlayer != nil {
+		checkPosition("this player", h.ThisPlayer.Position, -1, -1)
+	}
+	for r := 0; r < len(h.Rounds); r++ {
+		for a, pa := range h.Rounds[r].Actions {
+			checkPosition("player", pa.Position, r, a)
+		}
+	}
+	if h.Result != nil {
+		for _, sd := range h.Result.ShowDowns {
+			checkPosition("showdown", sd.Position, -1, -1)
+		}
+	}
+
+	// Cards
+	seen := make(map[card.Card]bool)
+	checkCards := func(name string, cards []card.Card, round int) {
+		for _, c := range cards {
+			if c == nil || c.Card() == card.CardInvalid || c.String() == "Invalid" {
+				add(InvalidCard, round, -1, "%v has invalid card %v", name, c)
+				continue
+			}
+			if seen[c.Card()] {
+				add(DuplicateCard, round, -1, "%v has duplicate card %v", name, c)
+			}
+			seen[c.Card()] = true
+		}
+	}
+
+	var board []card.Card
+	for r := 0; r < len(h.Rounds); r++ {
+		cards := h.Rounds[r].Cards
+		if r < len(boardCards) && len(cards) != boardCards[r] {
+			add(BoardCardCount, r, -1, "expected %v board cards, got %v",
+				boardCards[r], len(cards))
+		}
+		if r >= len(boardCards) {
+			add(BoardCardCount, r, -1, "too many betting rounds")
+		}
+
+		// Each round repeats the board of the previous round.
+		for i := 0; i < len(board) && i < len(cards); i++ {
+			if cards[i].Card() != board[i].Card() {
+				add(BoardCardCount, r, -1, "board card %v changed from %v to %v",
+					i+1, board[i], cards[i])
+			}
+		}
+		if len(cards) > len(board) {
+			checkCards("board", cards[len(board):], r)
+			board = cards
+		}
+	}
+	if h.ThisPlayer != nil {
+		checkCards("hole cards", h.ThisPlayer.Cards, -1)
+	}
+	if h.Result != nil {
+		for _, sd := range h.Result.ShowDowns {
+			if h.ThisPlayer != nil && sd.Position == h.ThisPlayer.Position {
+				continue
+			}
+			checkCards(fmt.Sprintf("seat %v", sd.Position), sd.Cards, -1)
+		}
+		for _, m := range h.Result.Mucked {
+			checkCards(fmt.Sprintf("seat %v", m.Position), m.Cards, -1)
+		}
+	}
+
+	// Actions
+	s := NewTableState(h)
+	for r := 0; r < len(h.Rounds); r++ {
+		if r > 0 {
+			if s.ToAct != 0 {
+				add(IllegalAction, r-1, -1, "betting round ended before seat %v acted",
+					s.ToAct)
+			}
+			s.NextRound()
+		}
+		for a, pa := range h.Rounds[r].Actions {
+			if _, ok := s.Stacks[pa.Position]; ok && !s.IsInHand(pa.Position) {
+				add(ActionAfterFold, r, a, "seat %v acts after folding", pa.Position)
+				continue
+			}
+			if err := s.Apply(pa); err != nil {
+				add(IllegalAction, r, a, "%v", err)
+			}
+		}
+		_, uncalled := s.Uncalled()
+		if h.Rounds[r].Pot != 0 && h.Rounds[r].Pot != s.Pot-uncalled {
+			add(ChipsNotConserved, r, -1, "pot is %v, actions add up to %v",
+				h.Rounds[r].Pot, s.Pot-uncalled)
+		}
+	}
+
+	if h.Result == nil {
+		return violations
+	}
+
+	for _, sd := range h.Result.ShowDowns {
+		if _, ok := s.Stacks[sd.Position]; ok && !s.IsInHand(sd.Position) {
+			add(InvalidShowDown, -1, -1, "seat %v shows after folding", sd.Position)
+		}
+	}
+
+	// Chips
+	_, uncalled := s.Uncalled()
+	total := h.Result.Rake + h.Result.Jackpot
+	for i, pot := range h.Result.Pots {
+		total += pot.Amount
+
+		var won Amount
+		for _, w := range pot.Winners {
+			won += w.Amount
+			if !pot.IsEligible(w.Position) && len(pot.Eligible) > 0 {
+				add(ChipsNotConserved, -1, -1, "seat %v wins %v without being eligible",
+					w.Position, PotName(i))
+			}
+			if !s.IsInHand(w.Position) {
+				add(ChipsNotConserved, -1, -1, "seat %v wins %v after folding",
+					w.Position, PotName(i))
+			}
+		}
+		if won != pot.Amount {
+			add(ChipsNotConserved, -1, -1, "%v is %v, winners collected %v",
+				PotName(i), pot.Amount, won)
+		}
+	}
+	if total != s.Pot-uncalled {
+		add(ChipsNotConserved, -1, -1, "pots and rake add up to %v, players put in %v",
+			total, s.Pot-uncalled)
+	}
+
+	return violations
+}
@@ -0,0 +1,50 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/whomever000/poker-common/card"
+)
+
+// Validate() //////////////////////////////////////////////////////////////////
+
+type testPairValidate struct {
+	corrupt func(h *Hand)
+	kind    ViolationKind
+}
+
+var testsValidate = []testPairValidate{
+	{func(h *Hand) { h.Result.ShowDowns[0].Cards[0] = card.CardAh }, DuplicateCard},
+	{func(h *Hand) { h.Rounds[1].Cards = h.Rounds[1].Cards[:2] }, BoardCardCount},
+	{func(h *Hand) { h.Rounds[0].Actions[2].Position = 2 }, ActionAfterFold},
+	{func(h *Hand) { h.Rounds[1].Actions[0].Position = 1 }, IllegalAction},
+	{func(h *Hand) { h.Button = 4 }, InvalidPosition},
+	{func(h *Hand) { h.Result.Pots[0].Winners[0].Amount = 200 }, ChipsNotConserved},
+	{func(h *Hand) { h.Result.Rake = 10 }, ChipsNotConserved},
+	{func(h *Hand) { h.Result.ShowDowns[0].Position = 2 }, InvalidShowDown},
+}
+
+func TestValidate(t *testing.T) {
+
+	if v := testHand().Validate(); len(v) != 0 {
+		t.Errorf("Expected no violations, got %v", v)
+	}
+
+	tests := testsValidate
+
+	for i := 0; i < len(tests); i++ {
+		h := testHand()
+		tests[i].corrupt(h)
+
+		violations := h.Validate()
+		found := false
+		for _, v := range violations {
+			if v.Kind == tests[i].kind {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("For test %v expected %v, got %v", i, tests[i].kind, violations)
+		}
+	}
+}
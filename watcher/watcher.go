@@ -0,0 +1,361 @@
+// Package watcher streams hands from hand history directories as they are
+// written by poker clients.
+package watcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	poker "github.com/whomever000/poker-common"
+	"github.com/whomever000/poker-common/importer"
+)
+
+// Event is emitted for each completed hand.
+type Event struct {
+
+	// File is the path of the hand history file.
+	File string
+
+	// Hand is the parsed hand, or nil if parsing failed.
+	Hand *poker.Hand
+
+	// Failure describes why parsing failed, or is nil on success.
+	Failure *importer.Failure
+}
+
+// Handler is called for each completed hand.
+type Handler func(e Event)
+
+// fileState is the position reached in a single file.
+type fileState struct {
+
+	// Name is the last known name of the file.
+	Name string
+
+	// Offset is the byte offset following the last completed hand.
+	Offset int64
+
+	// Line is the number of lines preceding Offset.
+	Line int
+
+	// Head is the checksum of the first HeadSize bytes of the file, which
+	// tells a file replaced by another with the same inode, or truncated and
+	// written again, from the file read so far.
+	Head     uint32
+	HeadSize int
+}
+
+// headSize is the size of the start of a file which identifies it.
+const headSize = 1024
+
+// Watcher tails all hand history files in a directory.
+type Watcher struct {
+
+	// Dir is the watched directory.
+	Dir string
+
+	// StateFile is the file in which the position reached in each file is
+	// saved, so that a restarted watcher continues where it stopped. An empty
+	// StateFile disables saving.
+	StateFile string
+
+	// Match selects the files to watch by name. A nil Match watches all files.
+	Match func(name string) bool
+
+	importer *importer.Importer
+	files    map[uint64]*fileState
+	mutex    sync.Mutex
+	closed   chan struct{}
+	notifier *notifier
+
+	// done is closed when a watcher started by Start stops, with the error
+	// it stopped with in err.
+	done chan struct{}
+	err  error
+}
+
+// New creates a new watcher of the directory dir, using the given parser.
+func New(dir string, parser importer.Parser) *Watcher {
+	return &Watcher{
+		Dir:      dir,
+		importer: importer.New(parser),
+		files:    make(map[uint64]*fileState),
+		closed:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Run reads all hands written since the last run, then calls handler for each
+// newly completed hand until the watcher is closed.
+//
+// A hand is considered complete once it is followed by a blank line, which is
+// how clients separate hands. Partially written hands are held back until
+// they are complete. Run stops with an error if the state cannot be saved, as
+// a restarted watcher would read hands again.
+func (w *Watcher) Run(handler Handler) error {
+
+	if err := w.load(); err != nil {
+		return err
+	}
+
+	n, err := newNotifier(w.Dir)
+	if err != nil {
+		return err
+	}
+	w.mutex.Lock()
+	select {
+	case <-w.closed:
+		w.mutex.Unlock()
+		n.close()
+		return nil
+	default:
+	}
+	w.notifier = n
+	w.mutex.Unlock()
+	defer n.close()
+
+	// Catch up on everything written while not running.
+	entries, err := os.ReadDir(w.Dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			if err := w.process(e.Name(), handler); err != nil {
+				return err
+			}
+		}
+	}
+	if err := w.forget(); err != nil {
+		return err
+	}
+
+	for {
+		name, err := n.next()
+		if err != nil {
+			select {
+			case <-w.closed:
+				return nil
+			default:
+				return err
+			}
+		}
+		if err := w.process(name, handler); err != nil {
+			return err
+		}
+	}
+}
+
+// Start runs the watcher in the background and delivers events on the returned
+// channel, which is closed when the watcher stops. Wait returns the error it
+// stopped with. Events not received before the watcher is closed are dropped.
+func (w *Watcher) Start() <-chan Event {
+	ch := make(chan Event)
+	go func() {
+		w.err = w.Run(func(e Event) {
+			select {
+			case ch <- e:
+			case <-w.closed:
+			}
+		})
+		close(ch)
+		close(w.done)
+	}()
+	return ch
+}
+
+// Wait waits until a watcher started by Start stops, and returns the error it
+// stopped with, or nil if it was closed.
+func (w *Watcher) Wait() error {
+	<-w.done
+	return w.err
+}
+
+// Close stops the watcher.
+func (w *Watcher) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	select {
+	case <-w.closed:
+		return nil
+	default:
+	}
+	close(w.closed)
+
+	if w.notifier != nil {
+		return w.notifier.close()
+	}
+	return nil
+}
+
+// process reads the completed hands which were appended to a file. The
+// returned error is that of saving the state.
+func (w *Watcher) process(name string, handler Handler) error {
+
+	if w.Match != nil && !w.Match(name) {
+		return nil
+	}
+	path := filepath.Join(w.Dir, name)
+	if w.StateFile != "" && (filepath.Clean(path) == filepath.Clean(w.StateFile) ||
+		filepath.Clean(path) == filepath.Clean(w.StateFile+".tmp")) {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		// The file was deleted or moved away.
+		return w.forget()
+	} else if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+
+	st := w.state(name, inode(info))
+
+	// The file was truncated, or replaced by another with the same inode.
+	if sum, err := head(f, st.HeadSize); info.Size() < st.Offset || err != nil ||
+		sum != st.Head {
+		*st = fileState{Name: name}
+	}
+
+	if _, err := f.Seek(st.Offset, io.SeekStart); err != nil {
+		return nil
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil
+	}
+
+	end := completed(data)
+	if end == 0 {
+		return nil
+	}
+
+	chunks, _ := importer.Split(bytes.NewReader(data[:end]))
+	for i := 0; i < len(chunks); i++ {
+		chunks[i].Line += st.Line
+		hand, failure := w.importer.ParseChunk(path, chunks[i])
+		handler(Event{path, hand, failure})
+	}
+
+	st.Offset += int64(end)
+	st.Line += bytes.Count(data[:end], []byte("\n"))
+	if st.HeadSize < headSize && int64(st.HeadSize) < st.Offset {
+		st.HeadSize = headSize
+		if st.Offset < headSize {
+			st.HeadSize = int(st.Offset)
+		}
+		if st.Head, err = head(f, st.HeadSize); err != nil {
+			*st = fileState{Name: name}
+		}
+	}
+	return w.save()
+}
+
+// forget forgets the files which are no longer in the directory, and saves
+// the state.
+func (w *Watcher) forget() error {
+
+	entries, err := os.ReadDir(w.Dir)
+	if err != nil {
+		return err
+	}
+	present := make(map[uint64]bool)
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && !e.IsDir() {
+			present[inode(info)] = true
+		}
+	}
+	for ino := range w.files {
+		if !present[ino] {
+			delete(w.files, ino)
+		}
+	}
+	return w.save()
+}
+
+// head returns the checksum of the first size bytes of a file.
+func head(f *os.File, size int) (uint32, error) {
+	data := make([]byte, size)
+	if _, err := f.ReadAt(data, 0); err != nil && !(err == io.EOF && size == 0) {
+		return 0, err
+	}
+	return crc32.ChecksumIEEE(data), nil
+}
+
+// state returns the state of a file. Files are identified by inode, so that
+// renamed files are followed and replaced files are read from the start.
+func (w *Watcher) state(name string, ino uint64) *fileState {
+
+	st, ok := w.files[ino]
+	if !ok {
+		st = &fileState{}
+		w.files[ino] = st
+	}
+	st.Name = name
+	return st
+}
+
+// completed returns the length of the data which contains completed hands,
+// i.e. the position following the last blank line.
+func completed(data []byte) int {
+	end := 0
+	for i := 0; i+1 < len(data); i++ {
+		if data[i] != '\n' {
+			continue
+		}
+		j := i + 1
+		if j < len(data) && data[j] == '\r' {
+			j++
+		}
+		if j < len(data) && data[j] == '\n' {
+			end = j + 1
+		}
+	}
+	return end
+}
+
+// load reads the saved state.
+func (w *Watcher) load() error {
+	if w.StateFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(w.StateFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &w.files)
+}
+
+// save writes the state, replacing the previous state atomically.
+func (w *Watcher) save() error {
+	if w.StateFile == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(w.files)
+	if err != nil {
+		return err
+	}
+
+	tmp := w.StateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, w.StateFile)
+}
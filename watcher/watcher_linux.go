@@ -0,0 +1,81 @@
+package watcher
+
+import (
+	"bytes"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// notifier reports changed files in a directory using inotify.
+type notifier struct {
+	file    *os.File
+	buf     []byte
+	pending []string
+}
+
+// newNotifier creates a new notifier watching the directory dir.
+func newNotifier(dir string) (*notifier, error) {
+
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+
+	mask := uint32(syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_CREATE |
+		syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM | syscall.IN_DELETE)
+
+	_, err = syscall.InotifyAddWatch(fd, dir, mask)
+	if err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+
+	// The non-blocking descriptor is handled by the runtime poller, which
+	// lets close interrupt a pending read.
+	return &notifier{
+		file: os.NewFile(uintptr(fd), "inotify"),
+		buf:  make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1)),
+	}, nil
+}
+
+// next blocks until a file changes and returns its name.
+func (n *notifier) next() (string, error) {
+
+	for len(n.pending) == 0 {
+		size, err := n.file.Read(n.buf)
+		if err != nil {
+			return "", err
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= size; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&n.buf[offset]))
+			start := offset + syscall.SizeofInotifyEvent
+			end := start + int(event.Len)
+			offset = end
+
+			if event.Len == 0 {
+				continue
+			}
+			name := string(bytes.TrimRight(n.buf[start:end], "\x00"))
+			n.pending = append(n.pending, name)
+		}
+	}
+
+	name := n.pending[0]
+	n.pending = n.pending[1:]
+	return name, nil
+}
+
+// close stops the notifier.
+func (n *notifier) close() error {
+	return n.file.Close()
+}
+
+// inode returns the inode number of a file.
+func inode(info os.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Ino)
+	}
+	return 0
+}
@@ -0,0 +1,185 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	poker "github.com/whomever000/poker-common"
+)
+
+func testParser(text string) (*poker.Hand, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(strings.Split(text, "\n")[0], "Hand #"))
+	if err != nil {
+		return nil, err
+	}
+	return &poker.Hand{HandID: id}, nil
+}
+
+func appendFile(t *testing.T, path, text string) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(text)
+	f.Close()
+}
+
+func expectHand(t *testing.T, events <-chan Event, id int) {
+	select {
+	case e := <-events:
+		if e.Hand == nil || e.Hand.HandID != id {
+			t.Fatalf("expected hand %v, got %+v", id, e)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for hand %v", id)
+	}
+}
+
+func expectNothing(t *testing.T, events <-chan Event) {
+	select {
+	case e := <-events:
+		t.Fatalf("unexpected event %+v", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatcher(t *testing.T) {
+
+	dir := t.TempDir()
+	state := filepath.Join(dir, "state.json")
+	path := filepath.Join(dir, "table.txt")
+
+	appendFile(t, path, "Hand #1\nline\n\n\n")
+
+	w := New(dir, testParser)
+	w.StateFile = state
+	events := w.Start()
+
+	expectHand(t, events, 1)
+
+	// Partial writes are held back until the hand is complete.
+	appendFile(t, path, "Hand #2\nli")
+	expectNothing(t, events)
+	appendFile(t, path, "ne\n\n\n")
+	expectHand(t, events, 2)
+
+	// Rotated files are not read again.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "Hand #3\n\n")
+	expectHand(t, events, 3)
+	expectNothing(t, events)
+
+	w.Close()
+	for range events {
+	}
+
+	// A restarted watcher continues where it stopped.
+	appendFile(t, path, "Hand #4\n\n")
+
+	w = New(dir, testParser)
+	w.StateFile = state
+	events = w.Start()
+
+	expectHand(t, events, 4)
+	expectNothing(t, events)
+
+	w.Close()
+	for range events {
+	}
+	if err := w.Wait(); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestWatcherError(t *testing.T) {
+
+	// The events are closed when the watcher fails to start.
+	w := New(filepath.Join(t.TempDir(), "missing"), testParser)
+	for e := range w.Start() {
+		t.Errorf("Unexpected event %+v", e)
+	}
+	if err := w.Wait(); err == nil {
+		t.Errorf("Expected error")
+	}
+
+	// The watcher stops when the state cannot be saved.
+	dir := t.TempDir()
+	appendFile(t, filepath.Join(dir, "table.txt"), "Hand #1\n\n")
+	w = New(dir, testParser)
+	w.StateFile = filepath.Join(dir, "missing", "state.json")
+	events := w.Start()
+	expectHand(t, events, 1)
+	for range events {
+	}
+	if err := w.Wait(); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestWatcherReplaced(t *testing.T) {
+
+	dir := t.TempDir()
+	state := filepath.Join(dir, "state.json")
+	path := filepath.Join(dir, "table.txt")
+
+	appendFile(t, path, "Hand #1\n\n")
+	w := New(dir, testParser)
+	w.StateFile = state
+	events := w.Start()
+	expectHand(t, events, 1)
+	w.Close()
+	for range events {
+	}
+
+	// The file is truncated and written again past the offset reached, so
+	// it is read from the start.
+	if err := os.WriteFile(path, []byte("Hand #2\nline\n\nHand #3\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w = New(dir, testParser)
+	w.StateFile = state
+	events = w.Start()
+	expectHand(t, events, 2)
+	expectHand(t, events, 3)
+
+	// A deleted file is forgotten.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	expectNothing(t, events)
+	w.Close()
+	for range events {
+	}
+	if err := w.Wait(); err != nil || len(w.files) != 0 {
+		t.Errorf("Expected no files, got %v (%v)", w.files, err)
+	}
+}
+
+func TestWatcherClose(t *testing.T) {
+
+	dir := t.TempDir()
+	appendFile(t, filepath.Join(dir, "table.txt"), "Hand #1\n\nHand #2\n\n")
+
+	// The watcher stops although the events are not received.
+	w := New(dir, testParser)
+	w.Start()
+	time.Sleep(100 * time.Millisecond)
+	w.Close()
+
+	done := make(chan error)
+	go func() { done <- w.Wait() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the watcher to stop")
+	}
+}
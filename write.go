@@ -0,0 +1,330 @@
+package poker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/whomever000/poker-common/card"
+)
+
+// roundNames are the names of the betting rounds in hand histories.
+var roundNames = []string{"Pre-Flop", "Flop", "Turn", "River"}
+
+// starsGame returns the name of a game as written by PokerStars.
+func starsGame(g Game) string {
+	if g == nil {
+		return Unknown.String()
+	}
+	switch g.Game() {
+	case TexasHoldEmNoLimit:
+		return "Hold'em No Limit"
+	default:
+		return g.String()
+	}
+}
+
+// seated returns whether a seat has a player.
+func (h *Hand) seated(pos PlayerPosition) bool {
+	return pos.Player(h) != nil
+}
+
+// dealtIn returns whether the player in a seat is dealt in.
+func (h *Hand) dealtIn(pos PlayerPosition) bool {
+	p := pos.Player(h)
+	return p != nil && p.IsDealtIn()
+}
+
+// board returns the board cards dealt by the last betting round.
+func (h *Hand) board() []card.Card {
+	for r := len(h.Rounds) - 1; r >= 0; r-- {
+		if len(h.Rounds[r].Cards) > 0 {
+			return h.Rounds[r].Cards
+		}
+	}
+	return nil
+}
+
+// describe returns a description of the best hand of a player, or an empty
+// string if there are not enough cards.
+func (h *Hand) describe(cards []card.Card) string {
+	all := append(append([]card.Card(nil), cards...), h.board()...)
+	if len(cards) == 0 || len(all) < 5 {
+		return ""
+	}
+	return card.Evaluate(all...).Description()
+}
+
+// write writes the hand history in PokerStars format, with amounts in a unit.
+func (h *Hand) write(b *strings.Builder, u Unit) {
+
+	name := func(pos PlayerPosition) string {
+		if p := pos.Player(h); p != nil {
+			return p.Name
+		}
+		return fmt.Sprintf("Seat %v", pos)
+	}
+	amount := func(a Amount) string {
+		return h.Format(a, u)
+	}
+
+	// Header
+	if t := h.Tournament; t != nil {
+		fmt.Fprintf(b, "%v Hand #%v: Tournament #%v, %v %v - Level %v (%v/%v) - %v\n",
+			h.Client, h.HandID, t.ID, t.BuyInString(), starsGame(h.Table.Game),
+			roman(t.Level), chips(h.Table.Stakes.SmallBlind),
+			chips(h.Table.Stakes.BigBlind), h.Date)
+	} else {
+		fmt.Fprintf(b, "%v Hand #%v: %v (%v) - %v\n", h.Client, h.HandID,
+			starsGame(h.Table.Game), h.Table.Stakes, h.Date)
+	}
+	fmt.Fprintf(b, "Table '%v' %v-max Seat #%v is the button\n",
+		h.Table.Name, h.Table.Size, h.Button)
+
+	// Player names and stacks
+	for _, pos := range h.Occupied() {
+		p := pos.Player(h)
+		fmt.Fprintf(b, "Seat %v: %v (%v in chips)", pos, p.Name, amount(p.Stack))
+		if !p.IsDealtIn() {
+			b.WriteString(" is sitting out")
+		}
+		b.WriteString("\n")
+	}
+
+	// Antes and blinds
+	s := NewTableState(h)
+	allIn := func(pos PlayerPosition) string {
+		if s.IsAllIn(pos) {
+			return " and is all-in"
+		}
+		return ""
+	}
+	if ante := h.Table.Stakes.Ante; ante > 0 {
+		for _, pos := range h.Seats() {
+			stack := pos.Player(h).Stack
+			fmt.Fprintf(b, "%v: posts the ante %v", name(pos), amount(minAmount(ante, stack)))
+			if stack <= ante {
+				b.WriteString(" and is all-in")
+			}
+			b.WriteString("\n")
+		}
+	}
+	if ante := h.Table.Stakes.BigBlindAnte; ante > 0 && h.seated(h.BigBlind) {
+		stack := h.BigBlind.Player(h).Stack - minAmount(h.Table.Stakes.Ante,
+			h.BigBlind.Player(h).Stack)
+		fmt.Fprintf(b, "%v: posts the ante %v", name(h.BigBlind),
+			amount(minAmount(ante, stack)))
+		if stack <= ante {
+			b.WriteString(" and is all-in")
+		}
+		b.WriteString("\n")
+	}
+	if h.SmallBlind != 0 && h.seated(h.SmallBlind) {
+		fmt.Fprintf(b, "%v: posts small blind %v%v\n", name(h.SmallBlind),
+			amount(s.Contributions[h.SmallBlind]), allIn(h.SmallBlind))
+	}
+	if h.BigBlind != 0 && h.seated(h.BigBlind) {
+		fmt.Fprintf(b, "%v: posts big blind %v%v\n", name(h.BigBlind),
+			amount(s.Contributions[h.BigBlind]), allIn(h.BigBlind))
+	}
+	for _, pos := range h.Seats() {
+		p := pos.Player(h)
+		if p.Status == DeadBlind && pos != h.SmallBlind && pos != h.BigBlind {
+			fmt.Fprintf(b, "%v: posts small & big blinds %v%v\n", name(pos),
+				amount(s.Invested[pos]-minAmount(h.Table.Stakes.Ante, p.Stack)), allIn(pos))
+		}
+	}
+	if straddler := h.Straddler(); straddler != 0 {
+		fmt.Fprintf(b, "%v: posts straddle %v%v\n", name(straddler),
+			amount(s.Contributions[straddler]), allIn(straddler))
+	}
+
+	// Betting rounds
+	folded := make(map[PlayerPosition]string)
+	var prev []card.Card
+	for r := 0; r < len(h.Rounds); r++ {
+		cards := h.Rounds[r].Cards
+
+		switch {
+		case r == 0:
+			b.WriteString("*** HOLE CARDS ***\n")
+			if h.ThisPlayer != nil {
+				fmt.Fprintf(b, "Dealt to %v [%v]\n", name(h.ThisPlayer.Position),
+					card.FormatCards(h.ThisPlayer.Cards))
+			}
+		case r < len(roundNames):
+			s.NextRound()
+			title := strings.ToUpper(roundNames[r])
+			if len(prev) > 0 && len(cards) > len(prev) {
+				fmt.Fprintf(b, "*** %v *** [%v] [%v]\n", title, card.FormatCards(prev),
+					card.FormatCards(cards[len(prev):]))
+			} else {
+				fmt.Fprintf(b, "*** %v *** [%v]\n", title, card.FormatCards(cards))
+			}
+		default:
+			s.NextRound()
+		}
+		prev = cards
+
+		for _, pa := range h.Rounds[r].Actions {
+			pos := pa.Position
+			before := s.CurrentBet
+			stack := s.Stacks[pos]
+			s.Apply(pa)
+			put := stack - s.Stacks[pos]
+
+			var action string
+			switch pa.Action.(type) {
+			case *foldAction:
+				action = "folds"
+				folded[pos] = roundNames[minInt(r, len(roundNames)-1)]
+				ante := minAmount(h.Table.Stakes.Ante, pos.Player(h).Stack)
+				if r == 0 && s.Invested[pos] == ante {
+					folded[pos] += " (didn't bet)"
+				}
+			case *checkAction:
+				action = "checks"
+			case *callAction:
+				action = fmt.Sprintf("calls %v", amount(put))
+			case *betAction:
+				action = fmt.Sprintf("bets %v", amount(put))
+			case *raiseAction:
+				action = fmt.Sprintf("raises %v to %v",
+					amount(s.Contributions[pos]-before), amount(s.Contributions[pos]))
+			default:
+				action = fmt.Sprint(pa.Action)
+			}
+			if put > 0 {
+				action += allIn(pos)
+			}
+			fmt.Fprintf(b, "%v: %v\n", name(pos), action)
+		}
+
+		if pos, uncalled := s.Uncalled(); uncalled > 0 {
+			fmt.Fprintf(b, "Uncalled bet (%v) returned to %v\n", amount(uncalled), name(pos))
+		}
+	}
+
+	if h.Result == nil {
+		return
+	}
+
+	// Showdown
+	if len(h.Result.ShowDowns) > 0 || len(h.Result.Mucked) > 0 {
+		b.WriteString("*** SHOW DOWN ***\n")
+	}
+	for _, sd := range h.Result.ShowDowns {
+		fmt.Fprintf(b, "%v: shows [%v]", name(sd.Position), card.FormatCards(sd.Cards))
+		if desc := h.describe(sd.Cards); desc != "" {
+			fmt.Fprintf(b, " (%v)", desc)
+		}
+		b.WriteString("\n")
+	}
+	for _, m := range h.Result.Mucked {
+		fmt.Fprintf(b, "%v: mucks hand\n", name(m.Position))
+	}
+
+	// Winnings, side pots first.
+	for i := len(h.Result.Pots) - 1; i >= 0; i-- {
+		pot := "pot"
+		if len(h.Result.Pots) > 1 {
+			pot = PotName(i)
+		}
+		for _, w := range h.Result.Pots[i].Winners {
+			fmt.Fprintf(b, "%v collected %v from %v\n", name(w.Position), amount(w.Amount), pot)
+		}
+	}
+
+	// Summary
+	b.WriteString("*** SUMMARY ***\n")
+
+	total := h.Result.Rake + h.Result.Jackpot
+	for _, pot := range h.Result.Pots {
+		total += pot.Amount
+	}
+	fmt.Fprintf(b, "Total pot %v", amount(total))
+	if len(h.Result.Pots) > 1 {
+		for i, pot := range h.Result.Pots {
+			fmt.Fprintf(b, " %v%v %v.", strings.ToUpper(PotName(i)[:1]), PotName(i)[1:],
+				amount(pot.Amount))
+		}
+	}
+	fmt.Fprintf(b, " | Rake %v", amount(h.Result.Rake))
+	if h.Result.Jackpot > 0 {
+		fmt.Fprintf(b, " | Jackpot %v", amount(h.Result.Jackpot))
+	}
+	b.WriteString("\n")
+
+	if board := h.board(); len(board) > 0 {
+		fmt.Fprintf(b, "Board [%v]\n", card.FormatCards(board))
+	}
+
+	winnings := h.Result.Winnings()
+	for _, pos := range h.Seats() {
+		fmt.Fprintf(b, "Seat %v: %v", pos, name(pos))
+		if pos == h.Button {
+			b.WriteString(" (button)")
+		}
+		if pos == h.SmallBlind {
+			b.WriteString(" (small blind)")
+		}
+		if pos == h.BigBlind {
+			b.WriteString(" (big blind)")
+		}
+
+		shown := h.Result.showDown(pos)
+		mucked := h.Result.mucked(pos)
+		switch {
+		case shown != nil:
+			fmt.Fprintf(b, " showed [%v] and ", card.FormatCards(shown.Cards))
+			if winnings[pos] > 0 {
+				fmt.Fprintf(b, "won (%v)", amount(winnings[pos]))
+			} else {
+				b.WriteString("lost")
+			}
+			if desc := h.describe(shown.Cards); desc != "" {
+				fmt.Fprintf(b, " with %v", desc)
+			}
+		case mucked != nil && len(mucked.Cards) > 0:
+			fmt.Fprintf(b, " mucked [%v]", card.FormatCards(mucked.Cards))
+		case mucked != nil:
+			b.WriteString(" mucked")
+		case folded[pos] != "":
+			if strings.HasPrefix(folded[pos], "Pre-Flop") {
+				fmt.Fprintf(b, " folded before Flop%v", folded[pos][len("Pre-Flop"):])
+			} else {
+				fmt.Fprintf(b, " folded on the %v", folded[pos])
+			}
+		case winnings[pos] > 0:
+			fmt.Fprintf(b, " collected (%v)", amount(winnings[pos]))
+		}
+		b.WriteString("\n")
+	}
+}
+
+// showDown returns the cards shown by a player, or nil.
+func (r *Result) showDown(pos PlayerPosition) *PlayerCards {
+	for i := 0; i < len(r.ShowDowns); i++ {
+		if r.ShowDowns[i].Position == pos {
+			return &r.ShowDowns[i]
+		}
+	}
+	return nil
+}
+
+// mucked returns the hand mucked by a player, or nil.
+func (r *Result) mucked(pos PlayerPosition) *PlayerCards {
+	for i := 0; i < len(r.Mucked); i++ {
+		if r.Mucked[i].Position == pos {
+			return &r.Mucked[i]
+		}
+	}
+	return nil
+}
+
+// minInt returns the smaller of two integers.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
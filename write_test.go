@@ -0,0 +1,246 @@
+package poker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/whomever000/poker-common/card"
+)
+
+const testHistory = `PokerStars Hand #1001: Hold'em No Limit ($0.05/$0.10 USD) - 2016/03/04 20:15:42 ET
+Table 'Alcyone' 6-max Seat #4 is the button
+Seat 1: dave ($3 in chips)
+Seat 2: erin ($10 in chips)
+Seat 4: frank ($6.50 in chips)
+Seat 6: grace ($10 in chips)
+grace: posts small blind $0.05
+dave: posts big blind $0.10
+*** HOLE CARDS ***
+Dealt to erin [Qh Qd]
+erin: raises $0.20 to $0.30
+frank: calls $0.30
+grace: folds
+dave: calls $0.20
+*** FLOP *** [2c 7d Kh]
+dave: bets $2.70 and is all-in
+erin: raises $4 to $6.70
+frank: calls $6.20 and is all-in
+Uncalled bet ($0.50) returned to erin
+*** TURN *** [2c 7d Kh] [3s]
+*** RIVER *** [2c 7d Kh 3s] [9c]
+*** SHOW DOWN ***
+erin: shows [Qh Qd] (a pair of Queens)
+frank: shows [Kd Js] (a pair of Kings)
+dave: mucks hand
+frank collected $6.80 from side pot-1
+frank collected $8.70 from main pot
+*** SUMMARY ***
+Total pot $16.05 Main pot $8.70. Side pot-1 $6.80. | Rake $0.55
+Board [2c 7d Kh 3s 9c]
+Seat 1: dave (big blind) mucked [Ac 4c]
+Seat 2: erin showed [Qh Qd] and lost with a pair of Queens
+Seat 4: frank (button) showed [Kd Js] and won ($15.50) with a pair of Kings
+Seat 6: grace (small blind) folded before Flop
+`
+
+const testStraddleHistory = `PokerStars Hand #1002: Hold'em No Limit ($0.05/$0.10/$0.20 USD) - 2016/03/04 20:15:42 UTC
+Table 'Alcyone' 6-max Seat #1 is the button
+Seat 1: dave ($10 in chips)
+Seat 2: erin ($10 in chips)
+Seat 3: frank ($10 in chips)
+Seat 4: grace ($10 in chips)
+erin: posts small blind $0.05
+frank: posts big blind $0.10
+grace: posts straddle $0.20
+*** HOLE CARDS ***
+dave: raises $0.20 to $0.40
+erin: folds
+frank: folds
+grace: calls $0.20
+*** FLOP *** [2c 7d Kh]
+grace: checks
+dave: bets $0.50
+grace: folds
+Uncalled bet ($0.50) returned to dave
+dave collected $0.95 from pot
+*** SUMMARY ***
+Total pot $0.95 | Rake $0
+Board [2c 7d Kh]
+Seat 1: dave (button) collected ($0.95)
+Seat 2: erin (small blind) folded before Flop
+Seat 3: frank (big blind) folded before Flop
+Seat 4: grace folded on the Flop
+`
+
+const testSittingOutHistory = `PokerStars Hand #1003: Hold'em No Limit ($0.05/$0.10 USD) - 2016/03/04 20:18:03 UTC
+Table 'Alcyone' 6-max Seat #1 is the button
+Seat 1: dave ($10 in chips)
+Seat 2: erin ($10 in chips)
+Seat 3: frank ($10 in chips) is sitting out
+Seat 4: grace ($10 in chips)
+Seat 5: heidi ($10 in chips)
+erin: posts small blind $0.05
+grace: posts big blind $0.10
+heidi: posts small & big blinds $0.15
+*** HOLE CARDS ***
+heidi: raises $0.20 to $0.30
+dave: folds
+erin: folds
+grace: folds
+Uncalled bet ($0.20) returned to heidi
+heidi collected $0.30 from pot
+*** SUMMARY ***
+Total pot $0.30 | Rake $0
+Seat 1: dave (button) folded before Flop (didn't bet)
+Seat 2: erin (small blind) folded before Flop
+Seat 4: grace (big blind) folded before Flop
+Seat 5: heidi collected ($0.30)
+`
+
+// ParseHand() and String() ////////////////////////////////////////////////////
+
+func TestParseHand(t *testing.T) {
+
+	h, err := ParseHand(testHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h.HandID != 1001 || h.Table.Size != 6 || h.Button != 4 ||
+		h.SmallBlind != 6 || h.BigBlind != 1 || len(h.Rounds) != 4 {
+		t.Errorf("Unexpected hand %+v", h)
+	}
+	if h.ThisPlayer == nil || h.ThisPlayer.Position != 2 {
+		t.Errorf("Unexpected hole cards %v", h.ThisPlayer)
+	}
+	if len(h.Result.Pots) != 2 || h.Result.Rake != 55 ||
+		!reflect.DeepEqual(h.Result.Pots[1].Eligible, []PlayerPosition{2, 4}) {
+		t.Errorf("Unexpected result %+v", h.Result)
+	}
+	if len(h.Result.Mucked) != 1 || len(h.Result.Mucked[0].Cards) != 2 {
+		t.Errorf("Unexpected mucked hands %v", h.Result.Mucked)
+	}
+	if v := h.Validate(); len(v) != 0 {
+		t.Errorf("Unexpected violations %v", v)
+	}
+}
+
+func TestHandRoundTrip(t *testing.T) {
+
+	h := testHand()
+	h.Date = Date(time.Date(2016, 3, 4, 20, 15, 42, 0, time.UTC))
+
+	// Antes are posted by every player.
+	ante := testHand()
+	ante.Date = h.Date
+	ante.Table.Stakes.Ante = 1
+	ante.Result.Pots[0].Amount += 3
+	ante.Result.Pots[0].Winners[0].Amount += 3
+
+	// The big blind posts an ante for the table.
+	bbAnte := testHand()
+	bbAnte.Date = h.Date
+	bbAnte.Table.Stakes.BigBlindAnte = 3
+	bbAnte.Result.Pots[0].Amount += 3
+	bbAnte.Result.Pots[0].Winners[0].Amount += 3
+
+	// Histories mark the big blind ante by the big blind posting alone.
+	parsed, err := ParseHand(strings.Replace(bbAnte.String(), " - BB Ante $0.03", "", 1))
+	if err != nil || parsed.Table.Stakes.BigBlindAnte != 3 || parsed.Table.Stakes.Ante != 0 {
+		t.Errorf("Expected a big blind ante, got %+v (%v)", parsed.Table.Stakes, err)
+	}
+
+	// Amounts are in the currency of the stakes.
+	euro := strings.Replace(testHistory, "$", "€", -1)
+	euro = strings.Replace(euro, " USD)", " EUR)", 1)
+
+	histories := []string{testHistory, h.String(), ante.String(), bbAnte.String(), euro,
+		testStraddleHistory, testSittingOutHistory}
+
+	for i := 0; i < len(histories); i++ {
+		h1, err := ParseHand(histories[i])
+		if err != nil {
+			t.Fatalf("For history %v: %v", i, err)
+		}
+		written := h1.String()
+		h2, err := ParseHand(written)
+		if err != nil {
+			t.Fatalf("For history %v: %v\n%v", i, err, written)
+		}
+		if !reflect.DeepEqual(h1, h2) {
+			t.Errorf("For history %v expected %+v, got %+v", i, h1, h2)
+		}
+		if h2.String() != written {
+			t.Errorf("For history %v expected\n%v\ngot\n%v", i, written, h2.String())
+		}
+
+	}
+
+	// The written hand matches the original.
+	for _, history := range []string{testHistory, euro, testStraddleHistory,
+		testSittingOutHistory} {
+		written, _ := ParseHand(history)
+		if written.String() != history {
+			t.Errorf("Expected\n%v\ngot\n%v", history, written.String())
+		}
+		if v := written.Validate(); len(v) != 0 {
+			t.Errorf("Unexpected violations %v", v)
+		}
+	}
+}
+
+func TestHandStringIncomplete(t *testing.T) {
+
+	// A short flop and empty seats must not panic.
+	h := testHand()
+	h.Table.Size = 6
+	h.Rounds[1].Cards = []card.Card{card.CardAh}
+	h.Rounds = h.Rounds[:2]
+	h.Result = nil
+
+	str := h.String()
+	if strings.Contains(str, "Seat 4") {
+		t.Errorf("Unexpected empty seat in\n%v", str)
+	}
+}
+
+// ParseDate() /////////////////////////////////////////////////////////////////
+
+func TestParseDate(t *testing.T) {
+
+	type testPair struct {
+		input  string
+		output time.Time
+	}
+	tests := []testPair{
+		{"2016/03/04 20:15:42 ET", time.Date(2016, 3, 5, 1, 15, 42, 0, time.UTC)},
+		{"2016/07/04 20:15:42 ET", time.Date(2016, 7, 5, 0, 15, 42, 0, time.UTC)},
+		{"2016/03/04 20:15:42 EST", time.Date(2016, 3, 5, 1, 15, 42, 0, time.UTC)},
+		{"2016/07/04 20:15:42 CEST", time.Date(2016, 7, 4, 18, 15, 42, 0, time.UTC)},
+		{"2016/03/04 20:15:42 UTC", time.Date(2016, 3, 4, 20, 15, 42, 0, time.UTC)},
+		{"2016/03/04 20:15:42", time.Date(2016, 3, 4, 20, 15, 42, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		d, err := ParseDate(test.input)
+		if err != nil {
+			t.Errorf("For %v unexpected error %v", test.input, err)
+		} else if !time.Time(d).Equal(test.output) {
+			t.Errorf("For %v expected %v, got %v", test.input, test.output,
+				time.Time(d).UTC())
+		}
+	}
+
+	for _, invalid := range []string{"2016/03/04 20:15:42 XYZ", "2016/03/04", "20:15:42 ET"} {
+		if _, err := ParseDate(invalid); err == nil {
+			t.Errorf("Expected error for %v", invalid)
+		}
+	}
+
+	d, _ := ParseDate("2016/03/04 20:15:42 ET")
+	if d.String() != "2016/03/04 20:15:42 ET" {
+		t.Errorf("Expected 2016/03/04 20:15:42 ET, got %v", d)
+	}
+}